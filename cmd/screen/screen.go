@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	// power schedule time zones are available in images without tzdata
+	_ "time/tzdata"
 
 	"github.com/unikiosk/unikiosk/pkg/config"
 	"github.com/unikiosk/unikiosk/pkg/service"
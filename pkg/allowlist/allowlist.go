@@ -0,0 +1,83 @@
+package allowlist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// List is allowlist of url patterns. Pattern is url in which * matches any characters,
+// e.g. https://*.synpse.net/* . Host and path are matched separately, so * in host does
+// not match path. Url fragment is ignored when matching
+type List struct {
+	patterns []string
+}
+
+// New validates patterns and returns list of them
+func New(patterns []string) (*List, error) {
+	for _, p := range patterns {
+		if err := Validate(p); err != nil {
+			return nil, err
+		}
+	}
+	return &List{patterns: patterns}, nil
+}
+
+// Validate returns error when pattern is not url pattern
+func Validate(pattern string) error {
+	if !strings.Contains(pattern, "://") {
+		return fmt.Errorf("invalid url pattern %q, scheme is required, e.g. https://synpse.net/*", pattern)
+	}
+	return nil
+}
+
+// Allows reports if url matches any of patterns
+func (l *List) Allows(url string) bool {
+	if i := strings.Index(url, "#"); i >= 0 {
+		url = url[:i]
+	}
+	scheme, host, path := split(url)
+	for _, p := range l.patterns {
+		pScheme, pHost, pPath := split(p)
+		if match(pScheme, scheme) && match(pHost, host) && match(pPath, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// split splits url into lower case scheme and host and path with query, which is / when empty
+func split(url string) (string, string, string) {
+	scheme, rest := "", url
+	if i := strings.Index(url, "://"); i >= 0 {
+		scheme, rest = url[:i], url[i+len("://"):]
+	}
+	host, path := rest, "/"
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		host, path = rest[:i], rest[i:]
+	}
+	if strings.HasPrefix(path, "?") {
+		path = "/" + path
+	}
+	return strings.ToLower(scheme), strings.ToLower(host), path
+}
+
+// match reports if s matches pattern in which * matches any characters
+func match(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
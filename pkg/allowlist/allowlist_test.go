@@ -0,0 +1,49 @@
+package allowlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	_, err := New([]string{"synpse.net"})
+	require.Error(err)
+
+	l, err := New([]string{"https://synpse.net", "https://synpse.net/docs/*", "https://*.unikiosk.io/*"})
+	require.NoError(err)
+
+	for _, url := range []string{
+		"https://synpse.net",
+		"https://synpse.net#pricing",
+		"https://Synpse.net/",
+		"https://synpse.net/docs/",
+		"https://synpse.net/docs/install?os=linux",
+		"https://app.unikiosk.io/",
+	} {
+		require.True(l.Allows(url), url)
+	}
+	for _, url := range []string{
+		"https://synpse.net/blog",
+		"http://synpse.net",
+		"https://unikiosk.io/",
+		"https://evil.com/?https://app.unikiosk.io/",
+		"https://google.com",
+	} {
+		require.False(l.Allows(url), url)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.True(match("a*b*c", "abc"))
+	require.True(match("a*b*c", "axxbyyc"))
+	require.False(match("a*b*c", "axxbyy"))
+	require.False(match("a*bc*c", "abc"))
+	require.True(match("*", ""))
+}
@@ -14,6 +14,17 @@ const (
 	ScreenActionPowerOff
 	ScreenActionPowerOn
 	ScreenActionScreenShot
+	ScreenActionEval
+	ScreenActionGetCookies
+	ScreenActionSetCookies
+	ScreenActionDeleteCookies
+	ScreenActionClearData
+	ScreenActionGetOutputs
+	ScreenActionSetOutput
+	ScreenActionGetBrightness
+	ScreenActionSetBrightness
+	ScreenActionGetPowerSchedule
+	ScreenActionSetPowerSchedule
 
 	ScreenActionUnknown
 )
@@ -32,6 +43,28 @@ func StringToAction(a string) (ScreenAction, error) {
 		return ScreenActionPowerOn, nil
 	case "screenshot":
 		return ScreenActionScreenShot, nil
+	case "eval":
+		return ScreenActionEval, nil
+	case "getcookies":
+		return ScreenActionGetCookies, nil
+	case "setcookies":
+		return ScreenActionSetCookies, nil
+	case "deletecookies":
+		return ScreenActionDeleteCookies, nil
+	case "cleardata":
+		return ScreenActionClearData, nil
+	case "getoutputs":
+		return ScreenActionGetOutputs, nil
+	case "setoutput":
+		return ScreenActionSetOutput, nil
+	case "getbrightness":
+		return ScreenActionGetBrightness, nil
+	case "setbrightness":
+		return ScreenActionSetBrightness, nil
+	case "getpowerschedule":
+		return ScreenActionGetPowerSchedule, nil
+	case "setpowerschedule":
+		return ScreenActionSetPowerSchedule, nil
 	default:
 		return ScreenActionUnknown, fmt.Errorf("unknown action")
 	}
@@ -51,7 +84,44 @@ func (s ScreenAction) String() string {
 		return "poweron"
 	case ScreenActionScreenShot:
 		return "screenshot"
+	case ScreenActionEval:
+		return "eval"
+	case ScreenActionGetCookies:
+		return "getcookies"
+	case ScreenActionSetCookies:
+		return "setcookies"
+	case ScreenActionDeleteCookies:
+		return "deletecookies"
+	case ScreenActionClearData:
+		return "cleardata"
+	case ScreenActionGetOutputs:
+		return "getoutputs"
+	case ScreenActionSetOutput:
+		return "setoutput"
+	case ScreenActionGetBrightness:
+		return "getbrightness"
+	case ScreenActionSetBrightness:
+		return "setbrightness"
+	case ScreenActionGetPowerSchedule:
+		return "getpowerschedule"
+	case ScreenActionSetPowerSchedule:
+		return "setpowerschedule"
 	default:
 		return "unknown"
 	}
 }
+
+// Mutating is true for actions which control kiosk. Actions which only read kiosk state are not audited
+func (s ScreenAction) Mutating() bool {
+	switch s {
+	case ScreenActionScreenShot,
+		ScreenActionGetCookies,
+		ScreenActionGetOutputs,
+		ScreenActionGetBrightness,
+		ScreenActionGetPowerSchedule,
+		ScreenActionUnknown:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,15 @@
+package api
+
+// ErrorResponse is the payload returned by the api when request fails
+type ErrorResponse struct {
+	// Code is HTTP status code of the response
+	Code int `json:"code"`
+	// Message is human readable error description
+	Message string `json:"message"`
+	// RequestID identifies request in server logs
+	RequestID string `json:"requestId,omitempty"`
+}
+
+func (e ErrorResponse) Error() string {
+	return e.Message
+}
@@ -1,6 +1,59 @@
 package api
 
+import "time"
+
+// EventType defines type of event travelling via eventer
+type EventType string
+
+var (
+	// EventTypeRequest is request to kiosk. Kiosk responds to it via callback
+	EventTypeRequest EventType = ""
+	// EventTypeContentChanged is emitted when kiosk content changes
+	EventTypeContentChanged EventType = "content_changed"
+	// EventTypePowerChanged is emitted when screen power state changes
+	EventTypePowerChanged EventType = "power_changed"
+	// EventTypeScreenshotTaken is emitted when screenshot is taken
+	EventTypeScreenshotTaken EventType = "screenshot_taken"
+	// EventTypeBrowserRestarted is emitted when browser is restarted
+	EventTypeBrowserRestarted EventType = "browser_restarted"
+	// EventTypeBrowserException is emitted when page throws uncaught exception, if enabled
+	EventTypeBrowserException EventType = "browser_exception"
+	// EventTypeLoadFailed is emitted when content fails to load and fallback is shown
+	EventTypeLoadFailed EventType = "load_failed"
+	// EventTypeLoadRecovered is emitted when failed content loads on retry
+	EventTypeLoadRecovered EventType = "load_recovered"
+	// EventTypeNavigationBlocked is emitted when page navigation outside of allowlist is blocked
+	EventTypeNavigationBlocked EventType = "navigation_blocked"
+	// EventTypeIdleTimeout is emitted when kiosk returns to content after page was idle
+	EventTypeIdleTimeout EventType = "idle_timeout"
+)
+
 type Event struct {
+	// Type is empty for requests and set for notifications
+	Type     EventType
 	Request  KioskRequest
 	Response KioskResponse
+	// Caller is set by api which received the request
+	Caller Caller
+	// Console is exception of browser_exception event
+	Console *ConsoleMessage
+}
+
+// Caller describes origin of the request
+type Caller struct {
+	// Identity is name of api token used, empty when api is not protected
+	Identity  string
+	Address   string
+	RequestID string
+	// Scheduled is set for requests sent by power schedule, api callers can't set it
+	Scheduled bool `json:"-"`
+}
+
+// StreamEvent is event representation streamed to remote clients
+type StreamEvent struct {
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	State State     `json:"state"`
+	// Message is set for browser_exception events
+	Message *ConsoleMessage `json:"message,omitempty"`
 }
@@ -1,41 +1,275 @@
 package api
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
 const StaticFilePrefix = "data:text/html"
 const ContentTypeApplicationJSON = "application/json"
 
+// ErrorPagePath is path of web server page shown when content fails to load
+const ErrorPagePath = "/unikiosk/error"
+
 // KioskRequest represents request to interact with kiosk
 type KioskRequest struct {
 	Content string
 	Title   string
 	SizeW   int
 	SizeH   int
-	Action  ScreenAction
+	// Position moves window when set
+	Position *Position `json:",omitempty"`
+	// Fullscreen switches window into or out of fullscreen when set. Size or position requested
+	// without it switch window out of fullscreen
+	Fullscreen *bool `json:",omitempty"`
+	Action     ScreenAction
+	// ScreenshotOptions configures ScreenActionScreenShot. Defaults are used when nil
+	ScreenshotOptions *ScreenshotOptions `json:",omitempty"`
+	// EvalOptions is expression evaluated by ScreenActionEval
+	EvalOptions *EvalOptions `json:",omitempty"`
+	// Allowlist is url patterns page can navigate to. Allowlist belongs to content, so new content
+	// without allowlist allows all navigations. Non-nil allowlist replaces allowlist of current content
+	Allowlist []string `json:",omitempty"`
+	// CookieOptions selects cookies of ScreenActionGetCookies and ScreenActionDeleteCookies. All cookies are selected when nil
+	CookieOptions *CookieOptions `json:",omitempty"`
+	// Cookies are set by ScreenActionSetCookies
+	Cookies []Cookie `json:",omitempty"`
+	// ClearOptions selects browser data removed by ScreenActionClearData
+	ClearOptions *ClearOptions `json:",omitempty"`
+	// Output is mode and rotation set by ScreenActionSetOutput
+	Output *OutputConfig `json:",omitempty"`
+	// Brightness is backlight level and schedule set by ScreenActionSetBrightness
+	Brightness *BrightnessConfig `json:",omitempty"`
+	// PowerSchedule replaces power schedule with ScreenActionSetPowerSchedule, nil removes it
+	PowerSchedule *PowerSchedule `json:",omitempty"`
+	// Display is id of display request is handled by. Screenshots select display by ScreenshotOptions
+	Display int `json:",omitempty"`
+}
+
+// TargetDisplay returns id of display which handles request
+func (r KioskRequest) TargetDisplay() int {
+	if r.Action == ScreenActionScreenShot {
+		// stitched screenshot is captured by the first display
+		if r.ScreenshotOptions == nil || r.ScreenshotOptions.Stitch {
+			return 0
+		}
+		return r.ScreenshotOptions.Display
+	}
+	return r.Display
+}
+
+var (
+	ScreenshotFormatPNG  = "png"
+	ScreenshotFormatJPEG = "jpeg"
+)
+
+// ScreenshotOptions configures how screenshot is captured and encoded
+type ScreenshotOptions struct {
+	// Format is one of: png, jpeg. Defaults to png
+	Format string
+	// Quality is jpeg quality 1-100
+	Quality int
+	// Width scales image down to given width keeping aspect ratio. Zero keeps original size
+	Width int
+	// Display is id of display to capture
+	Display int
+	// Stitch captures all displays into one image, Display is ignored then
+	Stitch bool `json:",omitempty"`
+}
+
+// ContentType returns mime type of encoded screenshot
+func (o ScreenshotOptions) ContentType() string {
+	if o.Format == ScreenshotFormatJPEG {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+var (
+	// DefaultEvalTimeout is used when evaluation timeout is not set
+	DefaultEvalTimeout = 2 * time.Second
+	// MaxEvalTimeout keeps evaluation shorter than eventer callback timeout
+	MaxEvalTimeout = 4 * time.Second
+)
+
+// EvalOptions configures javascript evaluation in the current page
+type EvalOptions struct {
+	Expression string
+	// Timeout defaults to DefaultEvalTimeout, limited by MaxEvalTimeout
+	Timeout time.Duration
+}
+
+// CookieOptions selects cookies by domain and name, empty fields match all cookies
+type CookieOptions struct {
+	// Domain matches cookies of domain and its subdomains
+	Domain string
+	Name   string
+}
+
+// ClearOptions selects browser data to remove
+type ClearOptions struct {
+	Cookies bool
+	Cache   bool
+	// Storage removes local storage, indexed db, service workers and other storage of Origins,
+	// content and the current page
+	Storage bool
+	Origins []string
+}
+
+// Position is window position on the screen
+type Position struct {
+	X int
+	Y int
+}
+
+// WindowGeometry is actual browser window geometry, read back from the browser after window was changed
+type WindowGeometry struct {
+	X          int  `json:"x"`
+	Y          int  `json:"y"`
+	Width      int  `json:"width"`
+	Height     int  `json:"height"`
+	Fullscreen bool `json:"fullscreen"`
+}
+
+// LoadResult is outcome of content load
+type LoadResult struct {
+	URL  string
+	Time time.Time
+	// Status is HTTP status of the document, zero when it is not known
+	Status   int
+	Duration time.Duration
+	// Error is network error, HTTP error status or load timeout. Empty when content loaded
+	Error string `json:",omitempty"`
+	// Fallback is content shown while URL fails to load
+	Fallback string `json:",omitempty"`
+	// Retries is number of failed attempts to load URL again
+	Retries int `json:",omitempty"`
+}
+
+// Failed reports if content failed to load
+func (r *LoadResult) Failed() bool {
+	return r != nil && r.Error != ""
 }
 
 // KioskResponse represents response payload for the api
 type KioskResponse struct {
+	// Display is id of display state belongs to
+	Display    int
 	Content    string
 	Title      string
 	SizeW      int
 	SizeH      int
-	PowerState PowerState
-	KioskMode  KioskMode
+	PosX       int
+	PosY       int
+	Fullscreen bool
+	// Geometry is actual window geometry. Nil until window is changed
+	Geometry *WindowGeometry `json:",omitempty"`
+	// PowerState is actual screen power, unknown when it can't be read. DesiredPowerState is requested one
+	PowerState        PowerState
+	DesiredPowerState PowerState
+	KioskMode         KioskMode
+	// Restarts is number of browser restarts, LastRestart and RestartReason describe the last one
+	Restarts      int
+	LastRestart   time.Time
+	RestartReason string
+	// LastLoad is outcome of the last content load
+	LastLoad *LoadResult `json:",omitempty"`
+	// Allowlist is url patterns page can navigate to, empty allows all
+	Allowlist []string `json:",omitempty"`
+	// BlockedNavigations is number of blocked navigations, LastBlocked and LastBlockedTime describe the last one
+	BlockedNavigations int
+	LastBlocked        string
+	LastBlockedTime    time.Time
+	// OutputConfigs are modes and rotations requested for outputs of the display
+	OutputConfigs []OutputConfig `json:",omitempty"`
+	// BrightnessSchedule is brightness curve over the day, empty when brightness is set by hand only
+	BrightnessSchedule []BrightnessPoint `json:",omitempty"`
 	// optional fields
-	Screenshot []byte
+	Screenshot    []byte               `json:",omitempty"`
+	Eval          *EvalResult          `json:",omitempty"`
+	Cookies       []Cookie             `json:",omitempty"`
+	Outputs       []Output             `json:",omitempty"`
+	Brightness    *Brightness          `json:",omitempty"`
+	PowerSchedule *PowerScheduleStatus `json:",omitempty"`
 }
 
 // KioskState respresent current Kiosk state and is used for eventing and storage
 type KioskState struct {
+	// Display is id of display state belongs to, each display has its own state
+	Display int
 	Content string
 	// ContentHash is hash of content before it was modified
 	ContentHash string
 	Title       string
 	SizeW       int
 	SizeH       int
-	PowerState  PowerState
-	KioskMode   KioskMode
+	PosX        int
+	PosY        int
+	// Windowed is true when window has requested size and position. Browser starts in fullscreen otherwise
+	Windowed bool
+	// Geometry is actual window geometry read back after window was changed
+	Geometry *WindowGeometry `json:",omitempty"`
+	// PowerState is actual screen power, unknown when it can't be read. DesiredPowerState is requested one
+	PowerState        PowerState
+	DesiredPowerState PowerState
+	KioskMode         KioskMode
+	// Restarts is number of browser restarts, LastRestart and RestartReason describe the last one
+	Restarts      int
+	LastRestart   time.Time
+	RestartReason string
+	// LastLoad is outcome of the last content load, LastGoodContent is last content which loaded
+	LastLoad        *LoadResult `json:",omitempty"`
+	LastGoodContent string      `json:",omitempty"`
+	// Allowlist is url patterns page can navigate to, empty allows all
+	Allowlist []string `json:",omitempty"`
+	// BlockedNavigations is number of blocked navigations, LastBlocked and LastBlockedTime describe the last one
+	BlockedNavigations int
+	LastBlocked        string
+	LastBlockedTime    time.Time
+	// OutputConfigs are modes and rotations requested for outputs, applied again before browser starts.
+	// Display has config of its own output with MultiDisplay, the first display of all outputs otherwise
+	OutputConfigs []OutputConfig `json:",omitempty"`
+	// Brightness is backlight level set by hand, nil until it is set. It overrides BrightnessSchedule
+	// until BrightnessOverrideUntil, which is the next point of schedule
+	Brightness              *int              `json:",omitempty"`
+	BrightnessSchedule      []BrightnessPoint `json:",omitempty"`
+	BrightnessOverrideUntil time.Time
+	// PowerSchedule turns screen on and off. Power requested by hand sets PowerOverride, which lasts
+	// until PowerOverrideUntil, the next transition of schedule, or until schedule changes when it is zero
+	PowerSchedule      *PowerSchedule `json:",omitempty"`
+	PowerOverride      bool
+	PowerOverrideUntil time.Time
+}
+
+// StateToResponse converts kiosk state into api response
+func StateToResponse(state *KioskState) KioskResponse {
+	return KioskResponse{
+		Display:       state.Display,
+		Content:       state.Content,
+		Title:         state.Title,
+		SizeW:         state.SizeW,
+		SizeH:         state.SizeH,
+		PosX:          state.PosX,
+		PosY:          state.PosY,
+		Fullscreen:    !state.Windowed,
+		Geometry:      state.Geometry,
+		PowerState:    state.PowerState,
+		KioskMode:     state.KioskMode,
+		Restarts:      state.Restarts,
+		LastRestart:   state.LastRestart,
+		RestartReason: state.RestartReason,
+		LastLoad:      state.LastLoad,
+		Allowlist:     state.Allowlist,
 
-	Screenshot []byte
+		BlockedNavigations: state.BlockedNavigations,
+		LastBlocked:        state.LastBlocked,
+		LastBlockedTime:    state.LastBlockedTime,
+		DesiredPowerState:  state.DesiredPowerState,
+		OutputConfigs:      state.OutputConfigs,
+		BrightnessSchedule: state.BrightnessSchedule,
+	}
 }
 
 // Kiosk mode defines mode of operations
@@ -48,6 +282,26 @@ var (
 	KioskModeProxy KioskMode = "proxy"
 )
 
+// UnmarshalJSON reads state persisted by older versions too. Requested power was stored in PowerState
+// before DesiredPowerState was added, so it is requested power when DesiredPowerState is missing
+func (s *KioskState) UnmarshalJSON(data []byte) error {
+	type kioskState KioskState
+	err := json.Unmarshal(data, (*kioskState)(s))
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	if _, ok := fields["DesiredPowerState"]; !ok {
+		s.DesiredPowerState = s.PowerState
+	}
+	return nil
+}
+
 // PowerState defines screen power state
 type PowerState int
 
@@ -56,3 +310,25 @@ const (
 	PowerStateOff
 	PowerStateUnknown
 )
+
+func StringToPowerState(p string) (PowerState, error) {
+	switch strings.ToLower(p) {
+	case "on":
+		return PowerStateOn, nil
+	case "off":
+		return PowerStateOff, nil
+	default:
+		return PowerStateUnknown, fmt.Errorf("unknown power state")
+	}
+}
+
+func (p PowerState) String() string {
+	switch p {
+	case PowerStateOn:
+		return "on"
+	case PowerStateOff:
+		return "off"
+	default:
+		return "unknown"
+	}
+}
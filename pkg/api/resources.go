@@ -0,0 +1,384 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Resources below are used by versioned (/api/v1) api. Unlike KioskRequest and KioskResponse
+// they use lower case json fields and string enums.
+
+// Content represents content displayed by the kiosk
+type Content struct {
+	Content string `json:"content"`
+	Title   string `json:"title,omitempty"`
+	// Allowlist is url patterns page can navigate to, empty allows all
+	Allowlist []string `json:"allowlist,omitempty"`
+}
+
+// Power represents screen power state. State is actual power, one of: on, off, unknown. Desired is
+// requested power, ignored in requests
+type Power struct {
+	State   string `json:"state"`
+	Desired string `json:"desired,omitempty"`
+}
+
+// ResponseToPower converts legacy api response into power resource
+func ResponseToPower(r KioskResponse) Power {
+	return Power{
+		State:   r.PowerState.String(),
+		Desired: r.DesiredPowerState.String(),
+	}
+}
+
+// Window represents kiosk window size, position and fullscreen state
+type Window struct {
+	Width      int  `json:"width"`
+	Height     int  `json:"height"`
+	X          int  `json:"x"`
+	Y          int  `json:"y"`
+	Fullscreen bool `json:"fullscreen"`
+	// Actual is window geometry read back from the browser. Ignored in requests
+	Actual *WindowGeometry `json:"actual,omitempty"`
+}
+
+// ResponseToWindow converts legacy api response into window resource
+func ResponseToWindow(r KioskResponse) Window {
+	return Window{
+		Width:      r.SizeW,
+		Height:     r.SizeH,
+		X:          r.PosX,
+		Y:          r.PosY,
+		Fullscreen: r.Fullscreen,
+		Actual:     r.Geometry,
+	}
+}
+
+// Eval is javascript expression to evaluate in the current page. Timeout is duration, e.g. 2s
+type Eval struct {
+	Expression string `json:"expression"`
+	Timeout    string `json:"timeout,omitempty"`
+}
+
+// EvalResult is json value of evaluated expression or exception it has thrown
+type EvalResult struct {
+	Result    json.RawMessage `json:"result,omitempty"`
+	Exception string          `json:"exception,omitempty"`
+}
+
+// Cookie is browser cookie. Cookie without expiry time is removed when browser exits
+type Cookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"`
+	// Path defaults to /
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	// SameSite is one of: Strict, Lax, None. Browser default is used when empty
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+// ClearData selects browser data to remove. Storage is removed for origins, content and the current page
+type ClearData struct {
+	Cookies bool     `json:"cookies,omitempty"`
+	Storage bool     `json:"storage,omitempty"`
+	Cache   bool     `json:"cache,omitempty"`
+	Origins []string `json:"origins,omitempty"`
+}
+
+var (
+	ConsoleLevelDebug   = "debug"
+	ConsoleLevelInfo    = "info"
+	ConsoleLevelWarning = "warning"
+	ConsoleLevelError   = "error"
+
+	// ConsoleSourceConsole marks messages logged via console api
+	ConsoleSourceConsole = "console"
+	// ConsoleSourceException marks uncaught exceptions
+	ConsoleSourceException = "exception"
+)
+
+// ConsoleMessage is message logged to browser console or uncaught exception thrown in the page
+type ConsoleMessage struct {
+	Time time.Time `json:"time"`
+	// Display is id of display page of which logged the message
+	Display int `json:"display"`
+	// Level is one of: debug, info, warning, error
+	Level string `json:"level"`
+	// Source is one of: console, exception
+	Source string `json:"source"`
+	Text   string `json:"text"`
+	URL    string `json:"url,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// State represents full kiosk state
+type State struct {
+	Display int       `json:"display"`
+	Content string    `json:"content"`
+	Title   string    `json:"title,omitempty"`
+	Window  Window    `json:"window"`
+	Power   string    `json:"power"`
+	Mode    KioskMode `json:"mode,omitempty"`
+	Browser Browser   `json:"browser"`
+	// Load is outcome of the last content load
+	Load       *Load      `json:"load,omitempty"`
+	Navigation Navigation `json:"navigation"`
+}
+
+// Browser represents browser restart history
+type Browser struct {
+	Restarts      int        `json:"restarts"`
+	LastRestart   *time.Time `json:"lastRestart,omitempty"`
+	RestartReason string     `json:"restartReason,omitempty"`
+}
+
+// ResponseToState converts legacy api response into versioned state resource
+func ResponseToState(r KioskResponse) State {
+	return State{
+		Display: r.Display,
+		Content: r.Content,
+		Title:   r.Title,
+		Window:  ResponseToWindow(r),
+		Power:   r.PowerState.String(),
+		Mode:    r.KioskMode,
+		Browser: ResponseToBrowser(r),
+		Load:    ResponseToLoad(r),
+
+		Navigation: ResponseToNavigation(r),
+	}
+}
+
+// ResponseToBrowser converts legacy api response into browser resource
+func ResponseToBrowser(r KioskResponse) Browser {
+	b := Browser{
+		Restarts:      r.Restarts,
+		RestartReason: r.RestartReason,
+	}
+	if !r.LastRestart.IsZero() {
+		b.LastRestart = &r.LastRestart
+	}
+	return b
+}
+
+// Navigation represents navigation allowlist of content and navigations blocked by it
+type Navigation struct {
+	Allowlist       []string   `json:"allowlist,omitempty"`
+	Blocked         int        `json:"blocked"`
+	LastBlocked     string     `json:"lastBlocked,omitempty"`
+	LastBlockedTime *time.Time `json:"lastBlockedTime,omitempty"`
+}
+
+// ResponseToNavigation converts legacy api response into navigation resource
+func ResponseToNavigation(r KioskResponse) Navigation {
+	n := Navigation{
+		Allowlist:   r.Allowlist,
+		Blocked:     r.BlockedNavigations,
+		LastBlocked: r.LastBlocked,
+	}
+	if !r.LastBlockedTime.IsZero() {
+		n.LastBlockedTime = &r.LastBlockedTime
+	}
+	return n
+}
+
+// Load represents outcome of the last content load
+type Load struct {
+	URL  string    `json:"url"`
+	Time time.Time `json:"time"`
+	// Status is HTTP status of the document, omitted when it is not known
+	Status     int   `json:"status,omitempty"`
+	DurationMs int64 `json:"durationMs"`
+	// Error is set when content failed to load, Fallback is content shown instead while it is retried
+	Error    string `json:"error,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
+	Retries  int    `json:"retries,omitempty"`
+}
+
+// ResponseToLoad converts legacy api response into load resource. Nil is returned when content was not loaded yet
+func ResponseToLoad(r KioskResponse) *Load {
+	l := r.LastLoad
+	if l == nil {
+		return nil
+	}
+	return &Load{
+		URL:        l.URL,
+		Time:       l.Time,
+		Status:     l.Status,
+		DurationMs: l.Duration.Milliseconds(),
+		Error:      l.Error,
+		Fallback:   l.Fallback,
+		Retries:    l.Retries,
+	}
+}
+
+// Display is output kiosk shows content on. Position and size are in screen coordinates,
+// disabled output is positioned right of enabled ones
+type Display struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name,omitempty"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+var (
+	RotationNormal   = "normal"
+	RotationLeft     = "left"
+	RotationInverted = "inverted"
+	RotationRight    = "right"
+)
+
+// ValidRotation reports if rotation is known. Empty rotation keeps current one
+func ValidRotation(rotation string) bool {
+	switch rotation {
+	case "", RotationNormal, RotationLeft, RotationInverted, RotationRight:
+		return true
+	default:
+		return false
+	}
+}
+
+// Output is video output of the screen and modes it supports. Rotation and rate are set for enabled outputs
+type Output struct {
+	Name     string       `json:"name"`
+	Enabled  bool         `json:"enabled"`
+	X        int          `json:"x"`
+	Y        int          `json:"y"`
+	Width    int          `json:"width"`
+	Height   int          `json:"height"`
+	Rotation string       `json:"rotation,omitempty"`
+	Rate     float64      `json:"rate,omitempty"`
+	Modes    []OutputMode `json:"modes"`
+}
+
+// OutputMode is resolution and refresh rate in Hz output supports
+type OutputMode struct {
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Rate      float64 `json:"rate"`
+	Preferred bool    `json:"preferred,omitempty"`
+}
+
+// OutputConfig is resolution, refresh rate and rotation requested for output. Zero fields keep current value,
+// zero rate selects preferred or the fastest mode of resolution
+type OutputConfig struct {
+	Name     string  `json:"name,omitempty"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Rate     float64 `json:"rate,omitempty"`
+	Rotation string  `json:"rotation,omitempty"`
+}
+
+// Brightness is backlight brightness of display in percent. Schedule is brightness curve over the day,
+// level set by hand overrides it until OverrideUntil
+type Brightness struct {
+	Level         int               `json:"level"`
+	Device        string            `json:"device,omitempty"`
+	Schedule      []BrightnessPoint `json:"schedule,omitempty"`
+	OverrideUntil *time.Time        `json:"overrideUntil,omitempty"`
+}
+
+// BrightnessConfig is requested brightness. Nil level keeps brightness, non-nil schedule replaces
+// schedule and empty one removes it
+type BrightnessConfig struct {
+	Level    *int              `json:"level,omitempty"`
+	Schedule []BrightnessPoint `json:"schedule"`
+}
+
+// BrightnessPoint is brightness level at time of day in format 15:04. Brightness between points
+// changes linearly
+type BrightnessPoint struct {
+	Time  string `json:"time"`
+	Level int    `json:"level"`
+}
+
+// Minute returns minute of the day of point time
+func (p BrightnessPoint) Minute() (int, error) {
+	t, err := time.Parse("15:04", p.Time)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected format 15:04", p.Time)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// PowerSchedule is weekly schedule of screen power. Screen is on during windows and off outside of them
+type PowerSchedule struct {
+	// Timezone is IANA time zone of schedule, e.g. Europe/Vilnius. Local time zone is used when empty
+	Timezone string        `json:"timezone,omitempty"`
+	Windows  []PowerWindow `json:"windows"`
+	// Exceptions replace windows of their dates, e.g. holidays
+	Exceptions []PowerException `json:"exceptions,omitempty"`
+}
+
+// PowerWindow is time of day screen is on at weekdays. Days are one of: mon, tue, wed, thu, fri, sat, sun.
+// On and Off are in format 15:04, window with Off before On ends the next day
+type PowerWindow struct {
+	Days []string `json:"days"`
+	On   string   `json:"on"`
+	Off  string   `json:"off"`
+}
+
+// PowerException is window of date in format 2006-01-02. Screen is off the whole day when On and Off
+// are empty, several exceptions of the same date are windows of that date
+type PowerException struct {
+	Date string `json:"date"`
+	On   string `json:"on,omitempty"`
+	Off  string `json:"off,omitempty"`
+}
+
+// PowerScheduleStatus is power schedule with power it requests now and its next transition
+type PowerScheduleStatus struct {
+	Schedule *PowerSchedule `json:"schedule,omitempty"`
+	// Scheduled is power requested by schedule now, one of: on, off
+	Scheduled string `json:"scheduled,omitempty"`
+	// Next is time of next scheduled transition, NextPower is power requested then
+	Next      *time.Time `json:"next,omitempty"`
+	NextPower string     `json:"nextPower,omitempty"`
+	// Override is set while power requested by hand overrides schedule. It lasts until OverrideUntil or
+	// until schedule changes when there is no transition
+	Override      bool       `json:"override"`
+	OverrideUntil *time.Time `json:"overrideUntil,omitempty"`
+}
+
+// Token represents management api token. Token secret is returned only on creation
+type Token struct {
+	ID      string    `json:"id,omitempty"`
+	Name    string    `json:"name"`
+	Role    string    `json:"role"`
+	Created time.Time `json:"created,omitempty"`
+	Token   string    `json:"token,omitempty"`
+}
+
+// AuditEntry is record of single control action
+type AuditEntry struct {
+	Time            time.Time `json:"time"`
+	Identity        string    `json:"identity,omitempty"`
+	Address         string    `json:"address,omitempty"`
+	RequestID       string    `json:"requestId,omitempty"`
+	Display         int       `json:"display"`
+	Action          string    `json:"action"`
+	PreviousContent string    `json:"previousContent,omitempty"`
+	Content         string    `json:"content,omitempty"`
+	// Token is id of api token created or deleted
+	Token string `json:"token,omitempty"`
+	// Result is success, error or denied
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+var (
+	AuditResultSuccess = "success"
+	AuditResultError   = "error"
+	// AuditResultDenied is result of request rejected by authentication or authorization
+	AuditResultDenied = "denied"
+
+	// AuditActionCreateToken and AuditActionDeleteToken are actions of api token management
+	AuditActionCreateToken = "createtoken"
+	AuditActionDeleteToken = "deletetoken"
+)
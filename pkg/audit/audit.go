@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/config"
+)
+
+var _ Log = &FileLog{}
+
+var (
+	// auditDir is directory in state directory where audit log is stored
+	auditDir = "audit"
+	// dayLayout is layout of daily audit file names
+	dayLayout = "2006-01-02"
+	fileExt   = ".jsonl"
+	// maxEntrySize limits size of single line, content can be large data url
+	maxEntrySize = 4 * 1024 * 1024
+)
+
+// Log records control actions
+type Log interface {
+	Record(entry api.AuditEntry) error
+	// Query returns entries recorded in [from, to) range, newest first. Zero time means no bound
+	Query(from, to time.Time, limit int) ([]api.AuditEntry, error)
+}
+
+// FileLog stores audit entries as json lines, one file per day. Files older than retention are removed
+type FileLog struct {
+	log       *zap.Logger
+	dir       string
+	retention time.Duration
+
+	mu sync.Mutex
+	// pruned is day when old files were removed last time
+	pruned string
+}
+
+func New(log *zap.Logger, config *config.Config) (*FileLog, error) {
+	l := &FileLog{
+		log:       log,
+		dir:       filepath.Join(config.StateDir, auditDir),
+		retention: config.AuditRetention,
+	}
+
+	err := os.MkdirAll(l.dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(time.Now().UTC())
+
+	return l, nil
+}
+
+func (l *FileLog) Record(entry api.AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(time.Now().UTC())
+
+	f, err := os.OpenFile(l.path(entry.Time), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func (l *FileLog) Query(from, to time.Time, limit int) ([]api.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	days, err := l.days()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []api.AuditEntry{}
+	// newest files first, so we can stop once limit is reached
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i]
+		if !from.IsZero() && day.Before(truncateDay(from)) {
+			break
+		}
+		if !to.IsZero() && !day.Before(to) {
+			continue
+		}
+
+		entries, err := l.read(day)
+		if err != nil {
+			return nil, err
+		}
+		for j := len(entries) - 1; j >= 0; j-- {
+			e := entries[j]
+			if !from.IsZero() && e.Time.Before(from) {
+				continue
+			}
+			if !to.IsZero() && !e.Time.Before(to) {
+				continue
+			}
+			result = append(result, e)
+			if limit > 0 && len(result) >= limit {
+				return result, nil
+			}
+		}
+	}
+	return result, nil
+}
+
+func (l *FileLog) read(day time.Time) ([]api.AuditEntry, error) {
+	f, err := os.Open(l.path(day))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []api.AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		var e api.AuditEntry
+		err := json.Unmarshal(scanner.Bytes(), &e)
+		if err != nil {
+			// partially written line after crash, skip it
+			l.log.Warn("skipping invalid audit entry", zap.String("file", f.Name()), zap.Error(err))
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// days returns days which have audit file, oldest first
+func (l *FileLog) days() ([]time.Time, error) {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	var days []time.Time
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileExt) {
+			continue
+		}
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(f.Name(), fileExt))
+		if err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// prune removes files older than retention. Runs at most once a day. Must be called with lock held
+func (l *FileLog) prune(now time.Time) {
+	today := now.Format(dayLayout)
+	if l.retention <= 0 || l.pruned == today {
+		return
+	}
+	l.pruned = today
+
+	days, err := l.days()
+	if err != nil {
+		l.log.Warn("failed to prune audit log", zap.Error(err))
+		return
+	}
+	cutoff := truncateDay(now.Add(-l.retention))
+	for _, day := range days {
+		if !day.Before(cutoff) {
+			break
+		}
+		err := os.Remove(l.path(day))
+		if err != nil {
+			l.log.Warn("failed to remove audit log", zap.Time("day", day), zap.Error(err))
+		}
+	}
+}
+
+func (l *FileLog) path(t time.Time) string {
+	return filepath.Join(l.dir, t.UTC().Format(dayLayout)+fileExt)
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
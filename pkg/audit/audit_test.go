@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/util/logger"
+)
+
+func TestFileLog(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c := &config.Config{
+		StateDir:       t.TempDir(),
+		AuditRetention: 48 * time.Hour,
+	}
+	l, err := New(logger.GetLoggerInstance("", zap.DebugLevel), c)
+	require.NoError(err)
+
+	now := time.Now().UTC()
+	for _, age := range []time.Duration{10 * 24 * time.Hour, 24 * time.Hour, time.Minute} {
+		// write old entries directly, Record prunes files only once a day
+		e := api.AuditEntry{Time: now.Add(-age), Action: "update", Result: api.AuditResultSuccess}
+		require.NoError(l.Record(e))
+	}
+
+	entries, err := l.Query(time.Time{}, time.Time{}, 0)
+	require.NoError(err)
+	require.Len(entries, 3)
+	require.True(entries[0].Time.After(entries[1].Time))
+
+	entries, err = l.Query(now.Add(-48*time.Hour), now.Add(-time.Hour), 0)
+	require.NoError(err)
+	require.Len(entries, 1)
+
+	// partially written line is skipped
+	f, err := os.OpenFile(l.path(now), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(err)
+	_, err = f.WriteString(`{"time":`)
+	require.NoError(err)
+	require.NoError(f.Close())
+	entries, err = l.Query(now.Add(-time.Hour), time.Time{}, 0)
+	require.NoError(err)
+	require.Len(entries, 1)
+
+	// files older than retention are removed on next start
+	l, err = New(logger.GetLoggerInstance("", zap.DebugLevel), c)
+	require.NoError(err)
+	entries, err = l.Query(time.Time{}, time.Time{}, 0)
+	require.NoError(err)
+	require.Len(entries, 2)
+
+	files, err := os.ReadDir(filepath.Join(c.StateDir, auditDir))
+	require.NoError(err)
+	require.Len(files, 2)
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	l := NewLimiter(time.Minute)
+	now := time.Now()
+
+	require.True(l.Allow("192.0.2.1", now))
+	require.False(l.Allow("192.0.2.1", now.Add(30*time.Second)))
+	require.True(l.Allow("192.0.2.2", now.Add(30*time.Second)))
+	require.True(l.Allow("192.0.2.1", now.Add(time.Minute)))
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// DeniedInterval is how often denied requests of single caller are recorded
+	DeniedInterval = time.Minute
+	// maxLimiterKeys bounds number of callers limiter remembers
+	maxLimiterKeys = 10000
+)
+
+// Limiter allows single entry per key in interval. It bounds entries anyone can cause,
+// like requests denied to unauthenticated callers, so they can't grow audit log without limit
+type Limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{
+		interval: interval,
+		last:     map[string]time.Time{},
+	}
+}
+
+// Allow returns true when entry of key was not allowed during interval before now
+func (l *Limiter) Allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.last[key]; ok && now.Sub(last) < l.interval {
+		return false
+	}
+	if len(l.last) >= maxLimiterKeys {
+		for k, last := range l.last {
+			if now.Sub(last) >= l.interval {
+				delete(l.last, k)
+			}
+		}
+		// too many callers within interval, their entries are dropped until some expire
+		if len(l.last) >= maxLimiterKeys {
+			return false
+		}
+	}
+	l.last[key] = now
+	return true
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Role defines what api caller is allowed to do
+type Role string
+
+var (
+	// RoleNone is used for resources which do not require authentication
+	RoleNone Role = ""
+	// RoleViewer can read kiosk state
+	RoleViewer Role = "viewer"
+	// RoleOperator can read and change kiosk state
+	RoleOperator Role = "operator"
+	// RoleAdmin can do everything, including token management
+	RoleAdmin Role = "admin"
+)
+
+func StringToRole(r string) (Role, error) {
+	switch strings.ToLower(r) {
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("unknown role %q", r)
+	}
+}
+
+func (r Role) level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows returns true if role has at least required permissions
+func (r Role) Allows(required Role) bool {
+	return r.level() >= required.level()
+}
+
+// Identity is authenticated api caller
+type Identity struct {
+	// Name identifies token used by the caller
+	Name string
+	Role Role
+}
+
+type identityKey struct{}
+
+// WithIdentity stores caller identity in the context
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns caller identity stored in the context, nil if caller is anonymous
+func IdentityFromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/config"
+)
+
+var (
+	// ErrUnauthenticated is returned when token is missing or invalid
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrTokenNotFound is returned when token does not exist
+	ErrTokenNotFound = errors.New("token not found")
+
+	// tokensFile is file in state directory where api tokens are stored
+	tokensFile = "tokens.json"
+)
+
+// Token is api token stored in state directory. Only token hash is persisted
+type Token struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Role    Role      `json:"role"`
+	Created time.Time `json:"created"`
+	Hash    string    `json:"hash,omitempty"`
+}
+
+// Authenticator validates api tokens. Tokens are either provided via configuration or
+// created via api and stored hashed in state directory
+type Authenticator struct {
+	log  *zap.Logger
+	path string
+
+	// enabled is set once tokens are configured or token file exists. It never changes afterwards, so
+	// deleting the last stored token does not open the api
+	enabled bool
+
+	mu sync.RWMutex
+	// static tokens from configuration, keyed by hash
+	static map[string]Identity
+	// stored tokens, keyed by id
+	stored map[string]Token
+}
+
+func New(log *zap.Logger, config *config.Config) (*Authenticator, error) {
+	a := &Authenticator{
+		log:    log,
+		path:   filepath.Join(config.StateDir, tokensFile),
+		static: map[string]Identity{},
+		stored: map[string]Token{},
+	}
+
+	for token, r := range config.APITokens {
+		role, err := StringToRole(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API_TOKENS: %w", err)
+		}
+		hash := hashToken(token)
+		a.static[hash] = Identity{
+			Name: "static-" + hash[:8],
+			Role: role,
+		}
+	}
+
+	exists, err := a.load()
+	if err != nil {
+		return nil, err
+	}
+	a.enabled = len(a.static) > 0 || exists
+
+	if !a.enabled {
+		log.Warn("no api tokens configured, management api is not protected. Configure API_TOKENS to protect it")
+	}
+
+	return a, nil
+}
+
+// Enabled returns true if api must be protected. Api is protected when API_TOKENS are configured or
+// tokens were ever stored, regardless of how many of them are left
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate returns identity of the token owner
+func (a *Authenticator) Authenticate(token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	hash := hashToken(token)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if id, ok := a.static[hash]; ok {
+		return &id, nil
+	}
+	for _, t := range a.stored {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+			return &Identity{Name: t.Name, Role: t.Role}, nil
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+// CreateToken creates and persists new token. Token secret is returned only once
+func (a *Authenticator) CreateToken(name string, role Role) (*Token, string, error) {
+	if role == RoleNone {
+		return nil, "", fmt.Errorf("role is required")
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+
+	t := Token{
+		ID:      id,
+		Name:    name,
+		Role:    role,
+		Created: time.Now().UTC(),
+		Hash:    hashToken(secret),
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stored[t.ID] = t
+	err = a.persist()
+	if err != nil {
+		delete(a.stored, t.ID)
+		return nil, "", err
+	}
+
+	t.Hash = ""
+	return &t, secret, nil
+}
+
+// ListTokens returns stored tokens without hashes
+func (a *Authenticator) ListTokens() []Token {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make([]Token, 0, len(a.stored))
+	for _, t := range a.stored {
+		t.Hash = ""
+		result = append(result, t)
+	}
+	return result
+}
+
+// DeleteToken deletes stored token
+func (a *Authenticator) DeleteToken(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	t, ok := a.stored[id]
+	if !ok {
+		return ErrTokenNotFound
+	}
+
+	delete(a.stored, id)
+	err := a.persist()
+	if err != nil {
+		a.stored[id] = t
+		return err
+	}
+	return nil
+}
+
+// load reads stored tokens and reports if token file exists
+func (a *Authenticator) load() (bool, error) {
+	data, err := os.ReadFile(a.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read tokens: %w", err)
+	}
+
+	var tokens []Token
+	err = json.Unmarshal(data, &tokens)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse tokens %s: %w", a.path, err)
+	}
+	for _, t := range tokens {
+		a.stored[t.ID] = t
+	}
+	return true, nil
+}
+
+// persist writes stored tokens to disk. Must be called with lock held
+func (a *Authenticator) persist() error {
+	tokens := make([]Token, 0, len(a.stored))
+	for _, t := range a.stored {
+		tokens = append(tokens, t)
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(a.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	// write and rename so partially written file never replaces valid one
+	tmp := a.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write tokens: %w", err)
+	}
+	return os.Rename(tmp, a.path)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
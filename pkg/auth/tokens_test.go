@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/util/logger"
+)
+
+func TestAuthenticator(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	log := logger.GetLoggerInstance("", zap.DebugLevel)
+	c := &config.Config{
+		StateDir:  t.TempDir(),
+		APITokens: map[string]string{"static-secret": "viewer"},
+	}
+
+	a, err := New(log, c)
+	require.NoError(err)
+	require.True(a.Enabled())
+
+	id, err := a.Authenticate("static-secret")
+	require.NoError(err)
+	require.Equal(RoleViewer, id.Role)
+
+	_, err = a.Authenticate("")
+	require.ErrorIs(err, ErrUnauthenticated)
+
+	token, secret, err := a.CreateToken("dashboard", RoleOperator)
+	require.NoError(err)
+	require.Empty(token.Hash)
+
+	// only hash must be persisted
+	data, err := os.ReadFile(filepath.Join(c.StateDir, tokensFile))
+	require.NoError(err)
+	require.NotContains(string(data), secret)
+
+	// tokens survive restart
+	a, err = New(log, c)
+	require.NoError(err)
+	id, err = a.Authenticate(secret)
+	require.NoError(err)
+	require.Equal("dashboard", id.Name)
+	require.Equal(RoleOperator, id.Role)
+	require.Len(a.ListTokens(), 1)
+
+	require.NoError(a.DeleteToken(token.ID))
+	require.ErrorIs(a.DeleteToken(token.ID), ErrTokenNotFound)
+	_, err = a.Authenticate(secret)
+	require.ErrorIs(err, ErrUnauthenticated)
+
+	// api stays protected after the last stored token is deleted
+	a, err = New(log, &config.Config{StateDir: c.StateDir})
+	require.NoError(err)
+	require.True(a.Enabled())
+
+	a, err = New(log, &config.Config{StateDir: t.TempDir()})
+	require.NoError(err)
+	require.False(a.Enabled())
+}
+
+func TestRole_Allows(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.True(RoleAdmin.Allows(RoleOperator))
+	require.True(RoleOperator.Allows(RoleViewer))
+	require.True(RoleViewer.Allows(RoleNone))
+	require.False(RoleViewer.Allows(RoleOperator))
+	require.False(RoleOperator.Allows(RoleAdmin))
+	require.False(RoleNone.Allows(RoleViewer))
+}
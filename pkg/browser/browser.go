@@ -0,0 +1,145 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/config"
+)
+
+var (
+	DriverFirefox  = "firefox"
+	DriverChromium = "chromium"
+
+	// ErrNotSupported is returned when driver can't perform operation
+	ErrNotSupported = errors.New("not supported by browser driver")
+	// ErrNotRunning is returned when browser is not started
+	ErrNotRunning = errors.New("browser is not running")
+)
+
+// Exception is error thrown by evaluated expression
+type Exception struct {
+	Message string
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("evaluation failed: %s", e.Message)
+}
+
+// LoadResult describes page load
+type LoadResult struct {
+	// Status is HTTP status of the loaded document. Zero when it is not known, e.g. for local files
+	Status int
+	// Duration is time from navigation start until page load event
+	Duration time.Duration
+}
+
+// WindowState is state of browser window
+type WindowState string
+
+var (
+	WindowStateNormal     WindowState = "normal"
+	WindowStateMaximized  WindowState = "maximized"
+	WindowStateMinimized  WindowState = "minimized"
+	WindowStateFullscreen WindowState = "fullscreen"
+)
+
+// Bounds is position and size of browser window
+type Bounds struct {
+	Left   int         `json:"left"`
+	Top    int         `json:"top"`
+	Width  int         `json:"width"`
+	Height int         `json:"height"`
+	State  WindowState `json:"state"`
+}
+
+// Driver controls browser process which displays kiosk content
+type Driver interface {
+	// Start launches browser showing url. It returns once browser can be controlled
+	Start(ctx context.Context, url string) error
+	// Wait blocks until browser started by Start exits
+	Wait() error
+	// Stop terminates browser
+	Stop() error
+
+	// Load navigates browser to url and waits for page load event. Network errors and
+	// pages not loaded until ctx is done are returned as error, HTTP error statuses are not
+	Load(ctx context.Context, url string) (LoadResult, error)
+	// Eval evaluates javascript expression in the page and returns its value as json. Promises are awaited.
+	// Exception thrown by expression is returned as *Exception
+	Eval(ctx context.Context, expression string) (json.RawMessage, error)
+	// Screenshot captures page as png
+	Screenshot(ctx context.Context) ([]byte, error)
+	// Console returns messages logged to page console and uncaught page exceptions of all browser runs.
+	// Messages are dropped when they are not read
+	Console() <-chan api.ConsoleMessage
+	// SetNavigationPolicy makes browser block page navigations to urls allow rejects, also after restarts.
+	// Nil allow allows all navigations. allow is called while browser events are handled and must not block
+	SetNavigationPolicy(ctx context.Context, allow func(url string) bool) error
+	// BlockedNavigations returns urls of blocked page navigations. Browsers which can't pause navigations
+	// report them once page navigated, so page has to be taken back
+	BlockedNavigations() <-chan string
+	// Cookies returns all browser cookies
+	Cookies(ctx context.Context) ([]api.Cookie, error)
+	// SetCookies creates or replaces cookies
+	SetCookies(ctx context.Context, cookies []api.Cookie) error
+	// DeleteCookies removes cookies with the same name, domain and path
+	DeleteCookies(ctx context.Context, cookies []api.Cookie) error
+	// ClearData removes browser data selected by opts. Storage of the current page is removed together with storage of opts.Origins
+	ClearData(ctx context.Context, opts api.ClearOptions) error
+
+	// Bounds returns browser window position and size
+	Bounds(ctx context.Context) (Bounds, error)
+	// SetBounds moves and resizes browser window. Size and position are ignored for states other than normal
+	SetBounds(ctx context.Context, bounds Bounds) error
+}
+
+// New creates driver selected by config for display. Browsers of different displays use separate profiles
+func New(log *zap.Logger, config *config.Config, display int) (Driver, error) {
+	switch config.BrowserDriver {
+	case DriverFirefox:
+		return newFirefox(log, config, display), nil
+	case DriverChromium:
+		return newChromium(log, config, display), nil
+	default:
+		return nil, fmt.Errorf("unknown browser driver %q", config.BrowserDriver)
+	}
+}
+
+// profileDir returns persistent profile directory of driver on display, empty when temporary profile is used
+func profileDir(config *config.Config, driver string, display int) string {
+	dir := config.BrowserProfileDir
+	if dir == "" && config.BrowserPersistProfile {
+		dir = filepath.Join(config.StateDir, "profile", driver)
+	}
+	if dir == "" || display == 0 {
+		return dir
+	}
+	return dir + "-" + strconv.Itoa(display)
+}
+
+// normalizeURL turns path to local page into file url
+func normalizeURL(url string) string {
+	if url == "" {
+		return "data:text/html,<html>Hello from Unikiosk!</html>"
+	}
+
+	parts := strings.Split(url, "/")
+	last := parts[len(parts)-1]
+	if strings.Contains(last, ".html") || strings.Contains(last, ".htm") || strings.Contains(last, ".php") {
+		if _, err := os.Stat(url); err == nil {
+			return "file://" + url
+		}
+	}
+	return url
+}
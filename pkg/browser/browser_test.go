@@ -0,0 +1,241 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/browser/cdp"
+	"github.com/unikiosk/unikiosk/pkg/config"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	d, err := New(zap.NewNop(), &config.Config{BrowserDriver: DriverFirefox}, 0)
+	require.NoError(err)
+	require.IsType(&firefox{}, d)
+
+	d, err = New(zap.NewNop(), &config.Config{BrowserDriver: DriverChromium}, 0)
+	require.NoError(err)
+	require.IsType(&chromium{}, d)
+
+	_, err = New(zap.NewNop(), &config.Config{BrowserDriver: "lynx"}, 0)
+	require.Error(err)
+}
+
+func TestNormalizeURL(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	page := filepath.Join(t.TempDir(), "index.html")
+	require.NoError(os.WriteFile(page, []byte("<html></html>"), 0644))
+
+	require.Equal("file://"+page, normalizeURL(page))
+	require.Equal("https://synpse.net/index.html", normalizeURL("https://synpse.net/index.html"))
+	require.Equal("https://synpse.net", normalizeURL("https://synpse.net"))
+	require.True(strings.HasPrefix(normalizeURL(""), "data:text/html"))
+}
+
+func TestProxyAddr(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	host, port, err := proxyAddr(":8080")
+	require.NoError(err)
+	require.Equal("localhost", host)
+	require.Equal(8080, port)
+
+	host, port, err = proxyAddr("10.0.0.1:3128")
+	require.NoError(err)
+	require.Equal("10.0.0.1", host)
+	require.Equal(3128, port)
+
+	_, _, err = proxyAddr("8080")
+	require.Error(err)
+}
+
+func TestDriverArgs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c := &config.Config{
+		ProxyHTTPServerAddr:  ":8000",
+		ProxyHTTPSServerAddr: ":8001",
+	}
+
+	profile := t.TempDir()
+	f := newFirefox(zap.NewNop(), c, 0)
+	args, err := f.args(context.Background(), profile, "http://localhost:8081")
+	require.NoError(err)
+	require.Contains(args, "--new-window=http://localhost:8081")
+	userJS, err := os.ReadFile(filepath.Join(profile, "user.js"))
+	require.NoError(err)
+	require.Contains(string(userJS), `user_pref("network.proxy.http_port", 8000);`)
+	require.Contains(string(userJS), `user_pref("network.proxy.ssl_port", 8001);`)
+	require.Contains(string(userJS), `user_pref("security.enterprise_roots.enabled", true);`)
+
+	ch := newChromium(zap.NewNop(), c, 0)
+	args, err = ch.args(context.Background(), profile, "http://localhost:8081")
+	require.NoError(err)
+	require.Contains(args, "--proxy-server=http=localhost:8000;https=localhost:8001")
+	require.Contains(args, "--user-data-dir="+profile)
+	require.Equal("http://localhost:8081", args[len(args)-1])
+
+	_, err = locate(filepath.Join(profile, "missing"))
+	require.Error(err)
+}
+
+func TestConsoleMessage(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	m, ok := consoleMessage(cdp.Event{
+		Method: "Runtime.consoleAPICalled",
+		Params: json.RawMessage(`{
+			"type": "warning",
+			"args": [{"type": "string", "value": "slow response"}, {"type": "number", "value": 1200}, {"type": "function", "description": "function f() {}"}],
+			"timestamp": 1700000000000,
+			"stackTrace": {"callFrames": [{"url": "https://synpse.net/app.js", "lineNumber": 9, "columnNumber": 4}]}
+		}`),
+	})
+	require.True(ok)
+	require.Equal(api.ConsoleLevelWarning, m.Level)
+	require.Equal(api.ConsoleSourceConsole, m.Source)
+	require.Equal("slow response 1200 function f() {}", m.Text)
+	require.Equal("https://synpse.net/app.js", m.URL)
+	require.Equal(10, m.Line)
+	require.Equal(time.Unix(1700000000, 0), m.Time)
+
+	m, ok = consoleMessage(cdp.Event{
+		Method: "Runtime.exceptionThrown",
+		Params: json.RawMessage(`{
+			"timestamp": 1700000000000,
+			"exceptionDetails": {"text": "Uncaught", "url": "https://synpse.net", "lineNumber": 0, "columnNumber": 0,
+				"exception": {"type": "object", "description": "TypeError: x is undefined"}}
+		}`),
+	})
+	require.True(ok)
+	require.Equal(api.ConsoleLevelError, m.Level)
+	require.Equal(api.ConsoleSourceException, m.Source)
+	require.Equal("TypeError: x is undefined", m.Text)
+	require.Equal(1, m.Line)
+
+	_, ok = consoleMessage(cdp.Event{Method: "Page.frameNavigated"})
+	require.False(ok)
+}
+
+func TestLoadWatch(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	w := newLoadWatch()
+	require.True(w.handle(cdp.Event{
+		Method: "Network.responseReceived",
+		Params: json.RawMessage(`{"loaderId": "L1", "type": "Document", "response": {"status": 503}}`),
+	}))
+	// frame and subresource responses do not change page status
+	w.handle(cdp.Event{
+		Method: "Network.responseReceived",
+		Params: json.RawMessage(`{"loaderId": "L1", "type": "Document", "response": {"status": 200}}`),
+	})
+	w.handle(cdp.Event{
+		Method: "Network.responseReceived",
+		Params: json.RawMessage(`{"loaderId": "L2", "type": "Script", "response": {"status": 404}}`),
+	})
+	require.Equal(503, w.status("L1"))
+	require.Equal(0, w.status("L2"))
+
+	select {
+	case <-w.loaded:
+		require.Fail("page is not loaded yet")
+	default:
+	}
+	require.True(w.handle(cdp.Event{Method: "Page.loadEventFired"}))
+	require.True(w.handle(cdp.Event{Method: "Page.loadEventFired"}))
+	<-w.loaded
+
+	require.False(w.handle(cdp.Event{Method: "Runtime.consoleAPICalled"}))
+}
+
+func TestHandleNavigation(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	d := newDevtools(zap.NewNop(), "test", "", nil)
+	d.allow = func(url string) bool {
+		return strings.HasPrefix(url, "https://synpse.net")
+	}
+	navigated := func(parent, url string) cdp.Event {
+		params, _ := json.Marshal(map[string]interface{}{"frame": map[string]string{"id": "F", "parentId": parent, "url": url}})
+		return cdp.Event{Method: "Page.frameNavigated", Params: params}
+	}
+
+	// browser without request interception reports page navigations after they happen
+	require.True(d.handleNavigation(nil, navigated("", "https://synpse.net/docs")))
+	require.True(d.handleNavigation(nil, navigated("P", "https://ads.com")))
+	require.True(d.handleNavigation(nil, navigated("", "https://google.com")))
+	require.Equal("https://google.com", <-d.BlockedNavigations())
+	require.Empty(d.blocked)
+	require.Equal("F", d.mainFrame)
+
+	// intercepted navigations are blocked before page navigates
+	d.intercept = true
+	d.handleNavigation(nil, navigated("", "https://google.com"))
+	require.Empty(d.blocked)
+
+	require.False(d.handleNavigation(nil, cdp.Event{Method: "Runtime.consoleAPICalled"}))
+}
+
+func TestCookie(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 500000000, time.UTC)
+	in := api.Cookie{Name: "session", Value: "secret", Domain: ".synpse.net", Expires: &expires, HTTPOnly: true, SameSite: "Lax"}
+
+	c := toCookie(in)
+	require.Equal("/", c.Path)
+	require.Equal(float64(expires.Unix())+0.5, c.Expires)
+
+	in.Path = "/"
+	require.Equal(in, fromCookie(c))
+
+	// session cookies have no expiry time
+	session := fromCookie(cookie{Name: "session", Domain: "synpse.net", Path: "/", Expires: -1})
+	require.Nil(session.Expires)
+	require.Zero(toCookie(session).Expires)
+}
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.Empty(profileDir(&config.Config{StateDir: "/data"}, DriverChromium, 0))
+	require.Equal("/data/profile/chromium", profileDir(&config.Config{StateDir: "/data", BrowserPersistProfile: true}, DriverChromium, 0))
+	require.Equal("/profile", profileDir(&config.Config{StateDir: "/data", BrowserPersistProfile: true, BrowserProfileDir: "/profile"}, DriverChromium, 0))
+	// browsers of other displays have own profiles
+	require.Equal("/data/profile/chromium-1", profileDir(&config.Config{StateDir: "/data", BrowserPersistProfile: true}, DriverChromium, 1))
+	require.Equal("/profile-2", profileDir(&config.Config{BrowserProfileDir: "/profile"}, DriverChromium, 2))
+
+	// locks of browser which did not exit are removed from persistent profile
+	dir := t.TempDir()
+	require.NoError(os.WriteFile(filepath.Join(dir, "SingletonLock"), nil, 0600))
+	require.NoError(os.WriteFile(filepath.Join(dir, "Cookies"), nil, 0600))
+	d := newDevtools(zap.NewNop(), DriverChromium, dir, nil)
+	profile, cleanup, err := d.profile()
+	require.NoError(err)
+	cleanup()
+	require.Equal(dir, profile)
+	require.NoFileExists(filepath.Join(dir, "SingletonLock"))
+	require.FileExists(filepath.Join(dir, "Cookies"))
+}
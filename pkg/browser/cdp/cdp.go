@@ -0,0 +1,226 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// DefaultTimeout limits calls made with context without deadline
+var DefaultTimeout = 30 * time.Second
+
+// Error is error returned by browser for a call
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("%s (%d): %s", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+type message struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Event is notification sent by browser for enabled protocol domains
+type Event struct {
+	Method string
+	Params json.RawMessage
+}
+
+// Target is debuggable target listed by browser
+type Target struct {
+	ID                   string `json:"id"`
+	Type                 string `json:"type"`
+	Title                string `json:"title"`
+	URL                  string `json:"url"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+// Conn is devtools protocol connection to single target
+type Conn struct {
+	ws *websocket.Conn
+	id int64
+
+	mu      sync.Mutex
+	pending map[int64]chan *message
+	handler func(Event)
+	done    chan struct{}
+	err     error
+}
+
+// Dial connects to target websocket debugger url
+func Dial(ctx context.Context, wsURL string) (*Conn, error) {
+	config, err := websocket.NewConfig(wsURL, "http://127.0.0.1")
+	if err != nil {
+		return nil, err
+	}
+	config.Dialer = &net.Dialer{Timeout: DefaultTimeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		config.Dialer.Deadline = deadline
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", wsURL, err)
+	}
+	// devtools messages, like screenshots, can be large
+	ws.MaxPayloadBytes = 64 * 1024 * 1024
+
+	c := &Conn{
+		ws:      ws,
+		pending: map[int64]chan *message{},
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// Call invokes method with params and decodes result into result, when it is not nil
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	m := message{
+		ID:     atomic.AddInt64(&c.id, 1),
+		Method: method,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		m.Params = data
+	}
+
+	reply := make(chan *message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.pending[m.ID] = reply
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, m.ID)
+		c.mu.Unlock()
+	}()
+
+	err := websocket.JSON.Send(c.ws, m)
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case r := <-reply:
+		if r.Error != nil {
+			return fmt.Errorf("%s failed: %w", method, r.Error)
+		}
+		if result == nil || len(r.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(r.Result, result)
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// OnEvent sets handler receiving events. Handler is called from connection read loop,
+// so it must not block nor make calls
+func (c *Conn) OnEvent(handler func(Event)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handler = handler
+}
+
+// Done is closed when connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Conn) Close() error {
+	return c.ws.Close()
+}
+
+func (c *Conn) readLoop() {
+	var err error
+	for {
+		m := &message{}
+		err = websocket.JSON.Receive(c.ws, m)
+		if err != nil {
+			break
+		}
+		if m.ID == 0 {
+			c.mu.Lock()
+			handler := c.handler
+			c.mu.Unlock()
+			if handler != nil && m.Method != "" {
+				handler(Event{Method: m.Method, Params: m.Params})
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		reply, ok := c.pending[m.ID]
+		c.mu.Unlock()
+		if ok {
+			reply <- m
+		}
+	}
+
+	c.mu.Lock()
+	c.err = fmt.Errorf("devtools connection closed: %w", err)
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// Targets lists targets of browser which devtools endpoint is on browserURL.
+// browserURL is websocket url browser prints on start, only its host is used
+func Targets(ctx context.Context, browserURL string) ([]Target, error) {
+	u, err := url.Parse(browserURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+u.Host+"/json/list", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list targets: %s", resp.Status)
+	}
+
+	var targets []Target
+	err = json.NewDecoder(resp.Body).Decode(&targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode targets: %w", err)
+	}
+	return targets, nil
+}
@@ -0,0 +1,88 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// newTestBrowser serves devtools endpoint with single page target answering evaluate calls
+func newTestBrowser(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/devtools/page/1"
+	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Target{
+			{ID: "1", Type: "page", URL: "about:blank", WebSocketDebuggerURL: wsURL},
+		})
+	})
+	mux.Handle("/devtools/page/1", websocket.Handler(func(ws *websocket.Conn) {
+		for {
+			m := message{}
+			if err := websocket.JSON.Receive(ws, &m); err != nil {
+				return
+			}
+			// events are interleaved with replies
+			websocket.JSON.Send(ws, message{Method: "Page.frameNavigated", Params: json.RawMessage(`{"frame":{"id":"1"}}`)})
+
+			reply := message{ID: m.ID}
+			switch m.Method {
+			case "Runtime.evaluate":
+				reply.Result = json.RawMessage(`{"result":{"type":"number","value":2}}`)
+			default:
+				reply.Error = &Error{Code: -32601, Message: "method not found"}
+			}
+			websocket.JSON.Send(ws, reply)
+		}
+	}))
+	return srv
+}
+
+func TestConn(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := newTestBrowser(t)
+	targets, err := Targets(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/devtools/browser/1")
+	require.NoError(err)
+	require.Len(targets, 1)
+	require.Equal("page", targets[0].Type)
+
+	conn, err := Dial(ctx, targets[0].WebSocketDebuggerURL)
+	require.NoError(err)
+	events := make(chan Event, 10)
+	conn.OnEvent(func(e Event) {
+		events <- e
+	})
+
+	var result struct {
+		Result struct {
+			Value int `json:"value"`
+		} `json:"result"`
+	}
+	err = conn.Call(ctx, "Runtime.evaluate", map[string]interface{}{"expression": "1+1"}, &result)
+	require.NoError(err)
+	require.Equal(2, result.Result.Value)
+	event := <-events
+	require.Equal("Page.frameNavigated", event.Method)
+	require.JSONEq(`{"frame":{"id":"1"}}`, string(event.Params))
+
+	err = conn.Call(ctx, "Unknown.method", nil, nil)
+	require.Error(err)
+	var cdpErr *Error
+	require.ErrorAs(err, &cdpErr)
+	require.Equal(-32601, cdpErr.Code)
+
+	require.NoError(conn.Close())
+	<-conn.Done()
+	require.Error(conn.Call(ctx, "Runtime.evaluate", nil, nil))
+}
@@ -0,0 +1,142 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/config"
+)
+
+var _ Driver = &chromium{}
+
+// chromium drives Chromium and Chrome over Chrome DevTools Protocol
+type chromium struct {
+	*devtools
+	config *config.Config
+}
+
+// window is reply of Browser.getWindowForTarget
+type window struct {
+	WindowID int `json:"windowId"`
+	Bounds   struct {
+		Left        int         `json:"left"`
+		Top         int         `json:"top"`
+		Width       int         `json:"width"`
+		Height      int         `json:"height"`
+		WindowState WindowState `json:"windowState"`
+	} `json:"bounds"`
+}
+
+func newChromium(log *zap.Logger, config *config.Config, display int) *chromium {
+	c := &chromium{
+		config: config,
+	}
+	c.devtools = newDevtools(log, DriverChromium, profileDir(config, DriverChromium, display), c)
+	return c
+}
+
+func (c *chromium) binary() (string, error) {
+	return locate(c.config.BrowserBinary, "chromium", "chromium-browser", "google-chrome", "google-chrome-stable")
+}
+
+func (c *chromium) args(ctx context.Context, profileDir, url string) ([]string, error) {
+	httpHost, httpPort, err := proxyAddr(c.config.ProxyHTTPServerAddr)
+	if err != nil {
+		return nil, err
+	}
+	httpsHost, httpsPort, err := proxyAddr(c.config.ProxyHTTPSServerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"--user-data-dir=" + profileDir,
+		"--remote-debugging-port=0",
+		fmt.Sprintf("--proxy-server=http=%s:%d;https=%s:%d", httpHost, httpPort, httpsHost, httpsPort),
+		"--kiosk",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--noerrdialogs",
+		"--disable-infobars",
+		"--disable-session-crashed-bubble",
+		"--disable-translate",
+	}
+	// chromium refuses to start as root with sandbox enabled, which is the case in container
+	if os.Geteuid() == 0 {
+		args = append(args, "--no-sandbox")
+	}
+	return append(args, url), nil
+}
+
+func (c *chromium) Bounds(ctx context.Context) (Bounds, error) {
+	w, err := c.window(ctx)
+	if err != nil {
+		return Bounds{}, err
+	}
+	return Bounds{
+		Left:   w.Bounds.Left,
+		Top:    w.Bounds.Top,
+		Width:  w.Bounds.Width,
+		Height: w.Bounds.Height,
+		State:  w.Bounds.WindowState,
+	}, nil
+}
+
+func (c *chromium) SetBounds(ctx context.Context, bounds Bounds) error {
+	w, err := c.window(ctx)
+	if err != nil {
+		return err
+	}
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+
+	if bounds.State != "" && bounds.State != WindowStateNormal {
+		return conn.Call(ctx, "Browser.setWindowBounds", map[string]interface{}{
+			"windowId": w.WindowID,
+			"bounds":   map[string]interface{}{"windowState": bounds.State},
+		}, nil)
+	}
+
+	// window must be restored before its geometry can be changed
+	if w.Bounds.WindowState != WindowStateNormal {
+		err := conn.Call(ctx, "Browser.setWindowBounds", map[string]interface{}{
+			"windowId": w.WindowID,
+			"bounds":   map[string]interface{}{"windowState": WindowStateNormal},
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	geometry := map[string]interface{}{
+		"left": bounds.Left,
+		"top":  bounds.Top,
+	}
+	if bounds.Width > 0 && bounds.Height > 0 {
+		geometry["width"] = bounds.Width
+		geometry["height"] = bounds.Height
+	}
+	return conn.Call(ctx, "Browser.setWindowBounds", map[string]interface{}{
+		"windowId": w.WindowID,
+		"bounds":   geometry,
+	}, nil)
+}
+
+func (c *chromium) window(ctx context.Context) (*window, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &window{}
+	err = conn.Call(ctx, "Browser.getWindowForTarget", nil, w)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
@@ -0,0 +1,151 @@
+package browser
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/browser/cdp"
+)
+
+// consoleBuffer is how many console messages wait to be read before new ones are dropped
+var consoleBuffer = 100
+
+type remoteObject struct {
+	Type        string          `json:"type"`
+	Value       json.RawMessage `json:"value"`
+	Description string          `json:"description"`
+}
+
+type stackTrace struct {
+	CallFrames []struct {
+		URL          string `json:"url"`
+		LineNumber   int    `json:"lineNumber"`
+		ColumnNumber int    `json:"columnNumber"`
+	} `json:"callFrames"`
+}
+
+type consoleAPICalled struct {
+	Type       string         `json:"type"`
+	Args       []remoteObject `json:"args"`
+	Timestamp  float64        `json:"timestamp"`
+	StackTrace *stackTrace    `json:"stackTrace"`
+}
+
+type exceptionThrown struct {
+	Timestamp        float64 `json:"timestamp"`
+	ExceptionDetails struct {
+		Text         string        `json:"text"`
+		URL          string        `json:"url"`
+		LineNumber   int           `json:"lineNumber"`
+		ColumnNumber int           `json:"columnNumber"`
+		Exception    *remoteObject `json:"exception"`
+	} `json:"exceptionDetails"`
+}
+
+// consoleMessage converts Runtime domain event into console message. False is returned for other events
+func consoleMessage(e cdp.Event) (api.ConsoleMessage, bool) {
+	switch e.Method {
+	case "Runtime.consoleAPICalled":
+		p := consoleAPICalled{}
+		if err := json.Unmarshal(e.Params, &p); err != nil {
+			return api.ConsoleMessage{}, false
+		}
+		texts := make([]string, 0, len(p.Args))
+		for _, arg := range p.Args {
+			texts = append(texts, arg.text())
+		}
+		m := api.ConsoleMessage{
+			Time:   timestamp(p.Timestamp),
+			Level:  consoleLevel(p.Type),
+			Source: api.ConsoleSourceConsole,
+			Text:   strings.Join(texts, " "),
+		}
+		if p.StackTrace != nil && len(p.StackTrace.CallFrames) > 0 {
+			frame := p.StackTrace.CallFrames[0]
+			// protocol positions are zero based
+			m.URL, m.Line, m.Column = frame.URL, frame.LineNumber+1, frame.ColumnNumber+1
+		}
+		return m, true
+	case "Runtime.exceptionThrown":
+		p := exceptionThrown{}
+		if err := json.Unmarshal(e.Params, &p); err != nil {
+			return api.ConsoleMessage{}, false
+		}
+		d := p.ExceptionDetails
+		text := d.Text
+		if d.Exception != nil && d.Exception.Description != "" {
+			text = d.Exception.Description
+		}
+		return api.ConsoleMessage{
+			Time:   timestamp(p.Timestamp),
+			Level:  api.ConsoleLevelError,
+			Source: api.ConsoleSourceException,
+			Text:   text,
+			URL:    d.URL,
+			Line:   d.LineNumber + 1,
+			Column: d.ColumnNumber + 1,
+		}, true
+	default:
+		return api.ConsoleMessage{}, false
+	}
+}
+
+// text returns string value as is and json of other values. Description is used for values
+// which are not serializable, like functions and DOM nodes
+func (o remoteObject) text() string {
+	if len(o.Value) == 0 {
+		if o.Description != "" {
+			return o.Description
+		}
+		return o.Type
+	}
+	var s string
+	if json.Unmarshal(o.Value, &s) == nil {
+		return s
+	}
+	return string(o.Value)
+}
+
+// consoleLevel maps console api call type into message level
+func consoleLevel(t string) string {
+	switch t {
+	case "debug", "trace":
+		return api.ConsoleLevelDebug
+	case "warning", "warn":
+		return api.ConsoleLevelWarning
+	case "error", "assert":
+		return api.ConsoleLevelError
+	default:
+		return api.ConsoleLevelInfo
+	}
+}
+
+// timestamp converts protocol timestamp in milliseconds since epoch
+func timestamp(ms float64) time.Time {
+	if ms <= 0 {
+		return time.Now()
+	}
+	return time.Unix(0, int64(ms*float64(time.Millisecond)))
+}
+
+// logConsole writes console message into log with matching level
+func logConsole(log *zap.Logger, m api.ConsoleMessage) {
+	fields := []zap.Field{zap.String("source", m.Source)}
+	if m.URL != "" {
+		fields = append(fields, zap.String("url", m.URL), zap.Int("line", m.Line), zap.Int("column", m.Column))
+	}
+	switch m.Level {
+	case api.ConsoleLevelDebug:
+		log.Debug(m.Text, fields...)
+	case api.ConsoleLevelWarning:
+		log.Warn(m.Text, fields...)
+	case api.ConsoleLevelError:
+		log.Error(m.Text, fields...)
+	default:
+		log.Info(m.Text, fields...)
+	}
+}
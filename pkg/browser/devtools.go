@@ -0,0 +1,518 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/browser/cdp"
+)
+
+var (
+	// startTimeout limits how long browser can take to open devtools endpoint and page
+	startTimeout = 30 * time.Second
+	// stopTimeout is how long browser has to exit after terminate signal before it is killed
+	stopTimeout = 5 * time.Second
+
+	devToolsListening = regexp.MustCompile(`DevTools listening on (ws://\S+)`)
+)
+
+// launcher prepares browser specific command line
+type launcher interface {
+	// binary returns path to browser executable
+	binary() (string, error)
+	// args returns command line arguments to start browser with profile in profileDir showing url
+	args(ctx context.Context, profileDir, url string) ([]string, error)
+}
+
+// devtools runs browser process and controls its page over devtools protocol.
+// It is shared by drivers of browsers implementing the protocol
+type devtools struct {
+	log      *zap.Logger
+	name     string
+	launcher launcher
+	// profileDir is persistent profile directory. Temporary profile is used for each start when empty
+	profileDir string
+	// console receives page console messages of all browser runs
+	console    chan api.ConsoleMessage
+	consoleLog *zap.Logger
+
+	loadMu sync.Mutex
+	// loading collects events of load in progress, nil when page is not being loaded
+	loading *loadWatch
+
+	navMu sync.Mutex
+	// allow decides which page navigations are allowed, nil allows all
+	allow func(url string) bool
+	// mainFrame is id of page frame, navigations of other frames are not checked
+	mainFrame string
+	// intercept is true when browser pauses navigations until they are checked
+	intercept bool
+	// blocked receives urls of blocked navigations
+	blocked chan string
+
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	conn *cdp.Conn
+	done chan struct{}
+	// err is exit error of last browser process
+	err      error
+	stopping bool
+	// lost is set when page was lost while process kept running, e.g. when page crashed
+	lost error
+}
+
+func newDevtools(log *zap.Logger, name, profileDir string, l launcher) *devtools {
+	return &devtools{
+		log:        log,
+		name:       name,
+		launcher:   l,
+		profileDir: profileDir,
+		console:    make(chan api.ConsoleMessage, consoleBuffer),
+		consoleLog: log.Named("console").With(zap.String("browser", name)),
+		blocked:    make(chan string, blockedBuffer),
+	}
+}
+
+func (d *devtools) Start(ctx context.Context, url string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cmd != nil {
+		return fmt.Errorf("%s is already running", d.name)
+	}
+
+	bin, err := d.launcher.binary()
+	if err != nil {
+		return err
+	}
+	profileDir, cleanup, err := d.profile()
+	if err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	args, err := d.launcher.args(ctx, profileDir, normalizeURL(url))
+	if err != nil {
+		cleanup()
+		return err
+	}
+
+	cmd := exec.Command(bin, args...)
+	cmd.Env = os.Environ()
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		cleanup()
+		return err
+	}
+	err = cmd.Start()
+	if err != nil {
+		cleanup()
+		return fmt.Errorf("failed to start %s: %w", d.name, err)
+	}
+	d.log.Info("browser started", zap.String("browser", d.name), zap.Int("pid", cmd.Process.Pid))
+
+	exited := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		close(exited)
+		cleanup()
+
+		d.mu.Lock()
+		if d.cmd == cmd {
+			if d.stopping {
+				err = nil
+			} else if d.lost != nil {
+				err = d.lost
+			}
+			d.err = err
+			d.cmd = nil
+			d.conn.Close()
+			d.conn = nil
+		}
+		d.mu.Unlock()
+		close(done)
+	}()
+
+	conn, err := d.connect(ctx, stderr, exited)
+	if err != nil {
+		// process is reaped by the goroutine above
+		cmd.Process.Kill()
+		return err
+	}
+
+	// console messages are reported once runtime domain is enabled
+	conn.OnEvent(func(e cdp.Event) {
+		d.handleEvent(cmd, conn, e)
+	})
+	err = conn.Call(ctx, "Runtime.enable", nil, nil)
+	if err != nil {
+		d.log.Warn("failed to enable console messages", zap.String("browser", d.name), zap.Error(err))
+	}
+	// load event and document status are reported once page and network domains are enabled
+	for _, domain := range []string{"Page", "Network"} {
+		err = conn.Call(ctx, domain+".enable", nil, nil)
+		if err != nil {
+			d.log.Warn("failed to enable page load events", zap.String("browser", d.name), zap.String("domain", domain), zap.Error(err))
+		}
+	}
+	err = d.applyNavigationPolicy(ctx, conn)
+	if err != nil {
+		d.log.Warn("failed to apply navigation policy", zap.String("browser", d.name), zap.Error(err))
+	}
+	// inspector reports crashed page, not every browser implements it
+	err = conn.Call(ctx, "Inspector.enable", nil, nil)
+	if err != nil {
+		d.log.Debug("failed to enable page crash events", zap.String("browser", d.name), zap.Error(err))
+	}
+	go func() {
+		select {
+		case <-conn.Done():
+			d.lose(cmd, "devtools connection closed")
+		case <-done:
+		}
+	}()
+
+	d.cmd = cmd
+	d.conn = conn
+	d.done = done
+	d.err = nil
+	d.stopping = false
+	d.lost = nil
+	return nil
+}
+
+// connect waits for browser to open devtools endpoint and connects to its page
+func (d *devtools) connect(ctx context.Context, stderr io.Reader, exited chan struct{}) (*cdp.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+
+	endpoint := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if m := devToolsListening.FindStringSubmatch(scanner.Text()); m != nil {
+				select {
+				case endpoint <- m[1]:
+				default:
+				}
+			}
+		}
+	}()
+
+	var browserURL string
+	select {
+	case browserURL = <-endpoint:
+	case <-exited:
+		return nil, fmt.Errorf("%s exited before devtools endpoint was opened", d.name)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s did not open devtools endpoint: %w", d.name, ctx.Err())
+	}
+
+	for {
+		targets, err := cdp.Targets(ctx, browserURL)
+		if err == nil {
+			for _, t := range targets {
+				if t.Type == "page" && t.WebSocketDebuggerURL != "" {
+					return cdp.Dial(ctx, t.WebSocketDebuggerURL)
+				}
+			}
+		}
+		select {
+		case <-exited:
+			return nil, fmt.Errorf("%s exited before page was opened", d.name)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s did not open page: %w", d.name, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (d *devtools) Wait() error {
+	d.mu.Lock()
+	done := d.done
+	d.mu.Unlock()
+	if done == nil {
+		return ErrNotRunning
+	}
+
+	<-done
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func (d *devtools) Stop() error {
+	d.mu.Lock()
+	cmd, done := d.cmd, d.done
+	if cmd == nil {
+		d.mu.Unlock()
+		return nil
+	}
+	d.stopping = true
+	d.mu.Unlock()
+
+	// let browser save profile before it is killed
+	err := cmd.Process.Signal(syscall.SIGTERM)
+	if errors.Is(err, os.ErrProcessDone) {
+		<-done
+		return nil
+	}
+	if err != nil {
+		return cmd.Process.Kill()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(stopTimeout):
+	}
+	err = cmd.Process.Kill()
+	<-done
+	return err
+}
+
+func (d *devtools) Load(ctx context.Context, url string) (LoadResult, error) {
+	conn, err := d.connection()
+	if err != nil {
+		return LoadResult{}, err
+	}
+
+	// events are collected from navigation start, document response can arrive before navigate returns
+	w := newLoadWatch()
+	d.loadMu.Lock()
+	d.loading = w
+	d.loadMu.Unlock()
+	defer func() {
+		d.loadMu.Lock()
+		d.loading = nil
+		d.loadMu.Unlock()
+	}()
+
+	started := time.Now()
+	var reply struct {
+		LoaderID  string `json:"loaderId"`
+		ErrorText string `json:"errorText"`
+	}
+	err = conn.Call(ctx, "Page.navigate", map[string]interface{}{"url": normalizeURL(url)}, &reply)
+	if err != nil {
+		return LoadResult{}, err
+	}
+	if reply.ErrorText != "" {
+		return LoadResult{}, fmt.Errorf("failed to navigate to %s: %s", url, reply.ErrorText)
+	}
+	// navigation within the same document does not load the page
+	if reply.LoaderID == "" {
+		return LoadResult{Duration: time.Since(started)}, nil
+	}
+
+	select {
+	case <-w.loaded:
+	case <-ctx.Done():
+		return LoadResult{Status: w.status(reply.LoaderID)}, fmt.Errorf("%s did not finish loading: %w", url, ctx.Err())
+	}
+	return LoadResult{Status: w.status(reply.LoaderID), Duration: time.Since(started)}, nil
+}
+
+func (d *devtools) Eval(ctx context.Context, expression string) (json.RawMessage, error) {
+	conn, err := d.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	var reply struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception *struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	err = conn.Call(ctx, "Runtime.evaluate", map[string]interface{}{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	if e := reply.ExceptionDetails; e != nil {
+		if e.Exception != nil && e.Exception.Description != "" {
+			return nil, &Exception{Message: e.Exception.Description}
+		}
+		return nil, &Exception{Message: e.Text}
+	}
+	// undefined has no value
+	if len(reply.Result.Value) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return reply.Result.Value, nil
+}
+
+func (d *devtools) Screenshot(ctx context.Context) ([]byte, error) {
+	conn, err := d.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	var reply struct {
+		Data string `json:"data"`
+	}
+	err = conn.Call(ctx, "Page.captureScreenshot", map[string]interface{}{"format": "png"}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(reply.Data)
+}
+
+func (d *devtools) Console() <-chan api.ConsoleMessage {
+	return d.console
+}
+
+func (d *devtools) handleEvent(cmd *exec.Cmd, conn *cdp.Conn, e cdp.Event) {
+	switch e.Method {
+	case "Inspector.targetCrashed":
+		go d.lose(cmd, "page crashed")
+		return
+	case "Inspector.detached":
+		go d.lose(cmd, "page detached")
+		return
+	}
+
+	d.loadMu.Lock()
+	w := d.loading
+	d.loadMu.Unlock()
+	if w != nil && w.handle(e) {
+		return
+	}
+	if d.handleNavigation(conn, e) {
+		return
+	}
+
+	m, ok := consoleMessage(e)
+	if !ok {
+		return
+	}
+	logConsole(d.consoleLog, m)
+
+	select {
+	case d.console <- m:
+	default:
+		d.log.Debug("console buffer is full, dropping message", zap.String("browser", d.name))
+	}
+}
+
+// lose kills browser process which lost its page, so it is restarted. Browser
+// can't be controlled without page connection
+func (d *devtools) lose(cmd *exec.Cmd, reason string) {
+	d.mu.Lock()
+	if d.cmd != cmd || d.stopping || d.lost != nil {
+		d.mu.Unlock()
+		return
+	}
+	d.lost = fmt.Errorf("%s: %s", d.name, reason)
+	d.mu.Unlock()
+
+	d.log.Error("browser page lost, terminating browser", zap.String("browser", d.name), zap.String("reason", reason))
+	err := cmd.Process.Kill()
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		d.log.Error("failed to kill browser", zap.String("browser", d.name), zap.Error(err))
+	}
+}
+
+// pid returns browser process id
+func (d *devtools) pid() (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cmd == nil {
+		return 0, ErrNotRunning
+	}
+	return d.cmd.Process.Pid, nil
+}
+
+func (d *devtools) connection() (*cdp.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return nil, ErrNotRunning
+	}
+	return d.conn, nil
+}
+
+// profileLocks are files browsers use to detect profile used by other process. Locks left by browser which
+// was killed, possibly in other container, make browser refuse the profile
+var profileLocks = []string{"SingletonLock", "SingletonSocket", "SingletonCookie", "lock", ".parentlock"}
+
+// profile returns profile directory and function which removes it when it is temporary
+func (d *devtools) profile() (string, func(), error) {
+	if d.profileDir != "" {
+		// only one browser runs at a time, so locks are stale
+		for _, name := range profileLocks {
+			err := os.Remove(filepath.Join(d.profileDir, name))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				d.log.Warn("failed to remove profile lock", zap.String("dir", d.profileDir), zap.String("lock", name), zap.Error(err))
+			}
+		}
+		return d.profileDir, func() {}, os.MkdirAll(d.profileDir, 0700)
+	}
+
+	dir, err := os.MkdirTemp("", "unikiosk-"+d.name)
+	if err != nil {
+		return "", nil, err
+	}
+	return dir, func() {
+		err := os.RemoveAll(dir)
+		if err != nil {
+			d.log.Warn("failed to remove profile", zap.String("dir", dir), zap.Error(err))
+		}
+	}, nil
+}
+
+// locate returns override when it is set, otherwise first of names found in PATH
+func locate(override string, names ...string) (string, error) {
+	if override != "" {
+		_, err := os.Stat(override)
+		if err != nil {
+			return "", fmt.Errorf("browser executable: %w", err)
+		}
+		return override, nil
+	}
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("none of %v found in PATH", names)
+}
+
+// proxyAddr returns host and port browser should use to reach proxy bound to addr
+func proxyAddr(addr string) (string, int, error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid proxy address %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid proxy port %q: %w", addr, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return host, port, nil
+}
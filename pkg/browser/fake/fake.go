@@ -0,0 +1,367 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+	"time"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/browser"
+)
+
+var _ browser.Driver = &Driver{}
+
+// Driver is in-memory browser driver used in tests. It records loaded urls and
+// can simulate browser crashes and slow loads
+type Driver struct {
+	mu      sync.Mutex
+	running bool
+	exited  chan error
+	url     string
+	starts  int
+	loads   []string
+	bounds  browser.Bounds
+	console chan api.ConsoleMessage
+
+	startErr  error
+	loadErr   error
+	boundsErr error
+	loadDelay time.Duration
+	eval      func(ctx context.Context, expression string) (json.RawMessage, error)
+	// statuses are HTTP statuses of urls, other urls load with 200
+	statuses map[string]int
+	allow    func(url string) bool
+	blocked  chan string
+	cookies  []api.Cookie
+	// cleared are options of ClearData calls
+	cleared []api.ClearOptions
+}
+
+func New() *Driver {
+	return &Driver{
+		bounds:   browser.Bounds{Width: 1920, Height: 1080, State: browser.WindowStateFullscreen},
+		console:  make(chan api.ConsoleMessage, 10),
+		statuses: map[string]int{},
+		blocked:  make(chan string, 10),
+	}
+}
+
+func (d *Driver) Start(ctx context.Context, url string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return fmt.Errorf("browser is already running")
+	}
+	if d.startErr != nil {
+		return d.startErr
+	}
+
+	d.running = true
+	d.exited = make(chan error, 1)
+	d.url = url
+	d.starts++
+	return nil
+}
+
+func (d *Driver) Wait() error {
+	d.mu.Lock()
+	exited := d.exited
+	d.mu.Unlock()
+	if exited == nil {
+		return browser.ErrNotRunning
+	}
+	return <-exited
+}
+
+func (d *Driver) Stop() error {
+	d.exit(nil)
+	return nil
+}
+
+// Crash makes browser exit with err, as if it crashed
+func (d *Driver) Crash(err error) {
+	d.exit(err)
+}
+
+func (d *Driver) exit(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return
+	}
+	d.running = false
+	d.exited <- err
+}
+
+func (d *Driver) Load(ctx context.Context, url string) (browser.LoadResult, error) {
+	d.mu.Lock()
+	delay := d.loadDelay
+	d.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return browser.LoadResult{}, ctx.Err()
+	case <-time.After(delay):
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return browser.LoadResult{}, browser.ErrNotRunning
+	}
+	if d.loadErr != nil {
+		return browser.LoadResult{}, d.loadErr
+	}
+	d.loads = append(d.loads, url)
+	d.url = url
+
+	status, ok := d.statuses[url]
+	if !ok {
+		status = 200
+	}
+	return browser.LoadResult{Status: status, Duration: delay}, nil
+}
+
+func (d *Driver) Eval(ctx context.Context, expression string) (json.RawMessage, error) {
+	d.mu.Lock()
+	running, eval := d.running, d.eval
+	d.mu.Unlock()
+	if !running {
+		return nil, browser.ErrNotRunning
+	}
+	if eval == nil {
+		return json.RawMessage("null"), nil
+	}
+	return eval(ctx, expression)
+}
+
+// Screenshot returns white png of window size
+func (d *Driver) Screenshot(ctx context.Context) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return nil, browser.ErrNotRunning
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, d.bounds.Width, d.bounds.Height))
+	for y := 0; y < d.bounds.Height; y++ {
+		for x := 0; x < d.bounds.Width; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, img)
+	return buf.Bytes(), err
+}
+
+func (d *Driver) Console() <-chan api.ConsoleMessage {
+	return d.console
+}
+
+// Log reports message as if page logged it to console
+func (d *Driver) Log(m api.ConsoleMessage) {
+	d.console <- m
+}
+
+func (d *Driver) SetNavigationPolicy(ctx context.Context, allow func(url string) bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.allow = allow
+	return nil
+}
+
+func (d *Driver) BlockedNavigations() <-chan string {
+	return d.blocked
+}
+
+// Navigate navigates page to url as if user followed a link. Navigation is blocked when policy rejects url
+func (d *Driver) Navigate(url string) {
+	d.mu.Lock()
+	allow := d.allow
+	d.mu.Unlock()
+
+	// policy is called without lock, as browsers call it from event loop
+	if allow != nil && !allow(url) {
+		d.blocked <- url
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.url = url
+}
+
+func (d *Driver) Cookies(ctx context.Context) ([]api.Cookie, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return nil, browser.ErrNotRunning
+	}
+	return append([]api.Cookie{}, d.cookies...), nil
+}
+
+func (d *Driver) SetCookies(ctx context.Context, cookies []api.Cookie) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return browser.ErrNotRunning
+	}
+	for _, c := range cookies {
+		if c.Path == "" {
+			c.Path = "/"
+		}
+		d.cookies = append(removeCookie(d.cookies, c), c)
+	}
+	return nil
+}
+
+func (d *Driver) DeleteCookies(ctx context.Context, cookies []api.Cookie) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return browser.ErrNotRunning
+	}
+	for _, c := range cookies {
+		if c.Path == "" {
+			c.Path = "/"
+		}
+		d.cookies = removeCookie(d.cookies, c)
+	}
+	return nil
+}
+
+// removeCookie removes cookie with the same name, domain and path
+func removeCookie(cookies []api.Cookie, c api.Cookie) []api.Cookie {
+	kept := []api.Cookie{}
+	for _, existing := range cookies {
+		if existing.Name != c.Name || existing.Domain != c.Domain || existing.Path != c.Path {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+func (d *Driver) ClearData(ctx context.Context, opts api.ClearOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return browser.ErrNotRunning
+	}
+	if opts.Cookies {
+		d.cookies = nil
+	}
+	d.cleared = append(d.cleared, opts)
+	return nil
+}
+
+func (d *Driver) Bounds(ctx context.Context) (browser.Bounds, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return browser.Bounds{}, browser.ErrNotRunning
+	}
+	return d.bounds, nil
+}
+
+func (d *Driver) SetBounds(ctx context.Context, bounds browser.Bounds) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return browser.ErrNotRunning
+	}
+	if d.boundsErr != nil {
+		return d.boundsErr
+	}
+	if bounds.State == "" {
+		bounds.State = browser.WindowStateNormal
+	}
+	if bounds.State != browser.WindowStateNormal {
+		d.bounds.State = bounds.State
+		return nil
+	}
+	d.bounds = bounds
+	return nil
+}
+
+// Running reports if browser is started and did not exit
+func (d *Driver) Running() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.running
+}
+
+// Starts returns how many times browser was started
+func (d *Driver) Starts() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.starts
+}
+
+// URL returns url browser shows
+func (d *Driver) URL() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.url
+}
+
+// Loads returns urls passed to Load, oldest first
+func (d *Driver) Loads() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.loads...)
+}
+
+// Cleared returns options of every ClearData call
+func (d *Driver) Cleared() []api.ClearOptions {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]api.ClearOptions{}, d.cleared...)
+}
+
+// SetStartError makes following starts fail with err
+func (d *Driver) SetStartError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.startErr = err
+}
+
+// SetLoadError makes following loads fail with err
+func (d *Driver) SetLoadError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadErr = err
+}
+
+// SetStatus makes following loads of url respond with HTTP status
+func (d *Driver) SetStatus(url string, status int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statuses[url] = status
+}
+
+// SetBoundsError makes following window changes fail with err
+func (d *Driver) SetBoundsError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.boundsErr = err
+}
+
+// SetLoadDelay makes following loads take delay
+func (d *Driver) SetLoadDelay(delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadDelay = delay
+}
+
+// SetEval sets function answering Eval calls
+func (d *Driver) SetEval(fn func(ctx context.Context, expression string) (json.RawMessage, error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.eval = fn
+}
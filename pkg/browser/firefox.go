@@ -0,0 +1,220 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/util/x11"
+)
+
+var _ Driver = &firefox{}
+
+// userJSTimeout limits download of base user.js
+var userJSTimeout = 30 * time.Second
+
+// firefox drives Firefox over its devtools protocol implementation
+type firefox struct {
+	*devtools
+	config *config.Config
+}
+
+func newFirefox(log *zap.Logger, config *config.Config, display int) *firefox {
+	f := &firefox{
+		config: config,
+	}
+	f.devtools = newDevtools(log, DriverFirefox, profileDir(config, DriverFirefox, display), f)
+	return f
+}
+
+func (f *firefox) binary() (string, error) {
+	return locate(f.config.BrowserBinary, "firefox", "firefox-esr")
+}
+
+func (f *firefox) args(ctx context.Context, profileDir, url string) ([]string, error) {
+	err := f.writeUserJS(ctx, filepath.Join(profileDir, "user.js"))
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		"--profile", profileDir,
+		"--remote-debugging-port=0",
+		"--no-remote",
+		"--kiosk",
+		"--new-window=" + url,
+	}, nil
+}
+
+// writeUserJS writes profile preferences. Preferences are appended to downloaded base user.js,
+// so they override it
+func (f *firefox) writeUserJS(ctx context.Context, path string) error {
+	prefs, err := f.preferences()
+	if err != nil {
+		return err
+	}
+
+	var base []byte
+	if f.config.FirefoxUserJSURL != "" {
+		base, err = download(ctx, f.config.FirefoxUserJSURL)
+		if err != nil {
+			// kiosk must start even without network, base preferences are not essential
+			f.log.Warn("failed to download user.js", zap.String("url", f.config.FirefoxUserJSURL), zap.Error(err))
+		}
+	}
+
+	data := string(base) + "\n" + strings.Join(prefs, "\n") + "\n"
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+func (f *firefox) preferences() ([]string, error) {
+	httpHost, httpPort, err := proxyAddr(f.config.ProxyHTTPServerAddr)
+	if err != nil {
+		return nil, err
+	}
+	httpsHost, httpsPort, err := proxyAddr(f.config.ProxyHTTPSServerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		// devtools protocol used to control the browser
+		userPref("devtools.chrome.enabled", true),
+		userPref("devtools.debugger.prompt-connection", false),
+		userPref("devtools.debugger.remote-enabled", true),
+		// enable both webdriver bidi and cdp, newer versions enable only bidi by default
+		userPref("remote.active-protocols", 3),
+		// all traffic goes via proxy
+		userPref("network.proxy.type", 1),
+		userPref("network.proxy.http", httpHost),
+		userPref("network.proxy.http_port", httpPort),
+		userPref("network.proxy.ssl", httpsHost),
+		userPref("network.proxy.ssl_port", httpsPort),
+		userPref("security.insecure_field_warning.contextual.enabled", false),
+		userPref("security.insecure_password.ui.enabled", false),
+		userPref("dom.security.https_only_mode", false),
+		// trust proxy certificate installed in OS
+		userPref("security.enterprise_roots.enabled", true),
+	}, nil
+}
+
+// Bounds reads window geometry from X server, Firefox does not implement browser domain of the protocol.
+// Geometry reported by the page is used when X server is not reachable
+func (f *firefox) Bounds(ctx context.Context) (Bounds, error) {
+	conn, w, err := f.window()
+	if err != nil {
+		f.log.Debug("failed to find browser window, using page geometry", zap.Error(err))
+		return f.pageBounds(ctx)
+	}
+	defer conn.Close()
+
+	g, err := conn.Geometry(w)
+	if err != nil {
+		return Bounds{}, err
+	}
+	b := Bounds{
+		Left:   g.X,
+		Top:    g.Y,
+		Width:  g.Width,
+		Height: g.Height,
+		State:  WindowStateNormal,
+	}
+	if g.Fullscreen {
+		b.State = WindowStateFullscreen
+	}
+	return b, nil
+}
+
+// SetBounds changes window via X server. Only normal and fullscreen states are supported
+func (f *firefox) SetBounds(ctx context.Context, bounds Bounds) error {
+	if bounds.State != "" && bounds.State != WindowStateNormal && bounds.State != WindowStateFullscreen {
+		return fmt.Errorf("window state %s: %w", bounds.State, ErrNotSupported)
+	}
+
+	conn, w, err := f.window()
+	if err != nil {
+		return fmt.Errorf("failed to find browser window: %w", err)
+	}
+	defer conn.Close()
+
+	if bounds.State == WindowStateFullscreen {
+		return conn.SetFullscreen(w, true)
+	}
+	err = conn.SetFullscreen(w, false)
+	if err != nil {
+		return err
+	}
+	return conn.Configure(w, bounds.Left, bounds.Top, bounds.Width, bounds.Height)
+}
+
+// window finds browser window on X server
+func (f *firefox) window() (*x11.Conn, x11.Window, error) {
+	pid, err := f.pid()
+	if err != nil {
+		return nil, 0, err
+	}
+	conn, err := x11.Connect()
+	if err != nil {
+		return nil, 0, err
+	}
+	w, err := conn.FindWindow(pid)
+	if err != nil {
+		conn.Close()
+		return nil, 0, err
+	}
+	return conn, w, nil
+}
+
+func (f *firefox) pageBounds(ctx context.Context) (Bounds, error) {
+	value, err := f.Eval(ctx, `({
+		left: window.screenX,
+		top: window.screenY,
+		width: window.outerWidth,
+		height: window.outerHeight,
+		state: (window.fullScreen || document.fullscreenElement) ? "fullscreen" : "normal"
+	})`)
+	if err != nil {
+		return Bounds{}, err
+	}
+
+	b := Bounds{}
+	err = json.Unmarshal(value, &b)
+	if err != nil {
+		return Bounds{}, fmt.Errorf("failed to decode window bounds: %w", err)
+	}
+	return b, nil
+}
+
+func userPref(name string, value interface{}) string {
+	n, _ := json.Marshal(name)
+	v, _ := json.Marshal(value)
+	return fmt.Sprintf("user_pref(%s, %s);", n, v)
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, userJSTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
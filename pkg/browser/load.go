@@ -0,0 +1,67 @@
+package browser
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/unikiosk/unikiosk/pkg/browser/cdp"
+)
+
+type responseReceived struct {
+	LoaderID string `json:"loaderId"`
+	Type     string `json:"type"`
+	Response struct {
+		Status int `json:"status"`
+	} `json:"response"`
+}
+
+// loadWatch collects events of page load in progress
+type loadWatch struct {
+	mu sync.Mutex
+	// statuses are HTTP statuses of documents by loader id
+	statuses map[string]int
+	loaded   chan struct{}
+}
+
+func newLoadWatch() *loadWatch {
+	return &loadWatch{
+		statuses: map[string]int{},
+		loaded:   make(chan struct{}),
+	}
+}
+
+// handle records Page and Network domain events. False is returned for other events
+func (w *loadWatch) handle(e cdp.Event) bool {
+	switch e.Method {
+	case "Page.loadEventFired":
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		select {
+		case <-w.loaded:
+		default:
+			close(w.loaded)
+		}
+		return true
+	case "Network.responseReceived":
+		p := responseReceived{}
+		if err := json.Unmarshal(e.Params, &p); err != nil || p.Type != "Document" {
+			return true
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// frames of the page are loaded by their own loaders, first document is the page itself
+		if _, ok := w.statuses[p.LoaderID]; !ok {
+			w.statuses[p.LoaderID] = p.Response.Status
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// status returns HTTP status of document loaded by loader, zero when it is not known
+func (w *loadWatch) status(loaderID string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.statuses[loaderID]
+}
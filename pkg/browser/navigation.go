@@ -0,0 +1,160 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/browser/cdp"
+)
+
+// blockedBuffer is how many blocked navigations wait to be read before new ones are dropped
+var blockedBuffer = 10
+
+type frameNavigated struct {
+	Frame struct {
+		ID       string `json:"id"`
+		ParentID string `json:"parentId"`
+		URL      string `json:"url"`
+	} `json:"frame"`
+}
+
+type requestPaused struct {
+	RequestID string `json:"requestId"`
+	FrameID   string `json:"frameId"`
+	Request   struct {
+		URL string `json:"url"`
+	} `json:"request"`
+}
+
+func (d *devtools) SetNavigationPolicy(ctx context.Context, allow func(url string) bool) error {
+	d.navMu.Lock()
+	d.allow = allow
+	d.navMu.Unlock()
+
+	conn, err := d.connection()
+	if err != nil {
+		// policy is applied once browser starts
+		return nil
+	}
+	return d.applyNavigationPolicy(ctx, conn)
+}
+
+func (d *devtools) BlockedNavigations() <-chan string {
+	return d.blocked
+}
+
+// applyNavigationPolicy makes browser pause page requests, so navigations can be checked before they start.
+// When browser can't intercept requests, navigations are checked once page navigated
+func (d *devtools) applyNavigationPolicy(ctx context.Context, conn *cdp.Conn) error {
+	d.navMu.Lock()
+	enabled := d.allow != nil
+	d.navMu.Unlock()
+
+	if !enabled {
+		d.setIntercept(false)
+		err := conn.Call(ctx, "Fetch.disable", nil, nil)
+		if err != nil {
+			d.log.Debug("failed to disable request interception", zap.String("browser", d.name), zap.Error(err))
+		}
+		return nil
+	}
+
+	// main frame is needed to tell page navigations from frame navigations
+	var tree struct {
+		FrameTree struct {
+			Frame struct {
+				ID string `json:"id"`
+			} `json:"frame"`
+		} `json:"frameTree"`
+	}
+	err := conn.Call(ctx, "Page.getFrameTree", nil, &tree)
+	if err != nil {
+		return err
+	}
+	d.navMu.Lock()
+	d.mainFrame = tree.FrameTree.Frame.ID
+	d.navMu.Unlock()
+
+	err = conn.Call(ctx, "Fetch.enable", map[string]interface{}{
+		"patterns": []map[string]interface{}{
+			{"urlPattern": "*", "resourceType": "Document", "requestStage": "Request"},
+		},
+	}, nil)
+	if err != nil {
+		d.log.Info("browser can't intercept requests, navigations are checked after page navigated", zap.String("browser", d.name), zap.Error(err))
+		d.setIntercept(false)
+		return nil
+	}
+	d.setIntercept(true)
+	return nil
+}
+
+func (d *devtools) setIntercept(intercept bool) {
+	d.navMu.Lock()
+	defer d.navMu.Unlock()
+	d.intercept = intercept
+}
+
+// handleNavigation checks page navigations against policy. False is returned for events not related to navigation
+func (d *devtools) handleNavigation(conn *cdp.Conn, e cdp.Event) bool {
+	switch e.Method {
+	case "Fetch.requestPaused":
+		p := requestPaused{}
+		if err := json.Unmarshal(e.Params, &p); err != nil {
+			return true
+		}
+		d.navMu.Lock()
+		allow, mainFrame := d.allow, d.mainFrame
+		d.navMu.Unlock()
+
+		// frames are part of allowed page
+		if allow == nil || p.FrameID != mainFrame || allow(p.Request.URL) {
+			go d.resolveRequest(conn, "Fetch.continueRequest", map[string]interface{}{"requestId": p.RequestID})
+			return true
+		}
+		go d.resolveRequest(conn, "Fetch.failRequest", map[string]interface{}{"requestId": p.RequestID, "errorReason": "BlockedByClient"})
+		d.block(p.Request.URL)
+		return true
+	case "Page.frameNavigated":
+		p := frameNavigated{}
+		if err := json.Unmarshal(e.Params, &p); err != nil {
+			return true
+		}
+		if p.Frame.ParentID != "" {
+			return true
+		}
+		d.navMu.Lock()
+		d.mainFrame = p.Frame.ID
+		allow, intercept := d.allow, d.intercept
+		d.navMu.Unlock()
+
+		// page already navigated, so it is reported to be taken back
+		if allow != nil && !intercept && !allow(p.Frame.URL) {
+			d.block(p.Frame.URL)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveRequest continues or fails paused request. Events are handled in connection read loop, so calls are made separately
+func (d *devtools) resolveRequest(conn *cdp.Conn, method string, params map[string]interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	err := conn.Call(ctx, method, params, nil)
+	if err != nil {
+		d.log.Warn("failed to resolve paused request", zap.String("browser", d.name), zap.String("method", method), zap.Error(err))
+	}
+}
+
+func (d *devtools) block(url string) {
+	d.log.Debug("navigation blocked", zap.String("browser", d.name), zap.String("url", url))
+	select {
+	case d.blocked <- url:
+	default:
+		d.log.Debug("blocked navigations buffer is full, dropping navigation", zap.String("browser", d.name))
+	}
+}
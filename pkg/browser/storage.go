@@ -0,0 +1,167 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// clearSession clears session storage of the page, which survives navigations, and returns page origin
+var clearSession = `(() => { try { sessionStorage.clear() } catch (e) {} return location.origin })()`
+
+// cookie is cookie of devtools protocol. Expires is unix time in seconds, session cookies have -1
+type cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires,omitempty"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+func toCookie(c api.Cookie) cookie {
+	out := cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		HTTPOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+	}
+	if out.Path == "" {
+		out.Path = "/"
+	}
+	if c.Expires != nil {
+		out.Expires = float64(c.Expires.UnixNano()) / float64(time.Second)
+	}
+	return out
+}
+
+func fromCookie(c cookie) api.Cookie {
+	out := api.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		HTTPOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+	}
+	if c.Expires > 0 {
+		sec, frac := math.Modf(c.Expires)
+		expires := time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC()
+		out.Expires = &expires
+	}
+	return out
+}
+
+func (d *devtools) Cookies(ctx context.Context) ([]api.Cookie, error) {
+	conn, err := d.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	var reply struct {
+		Cookies []cookie `json:"cookies"`
+	}
+	err = conn.Call(ctx, "Network.getAllCookies", nil, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", err)
+	}
+	cookies := make([]api.Cookie, 0, len(reply.Cookies))
+	for _, c := range reply.Cookies {
+		cookies = append(cookies, fromCookie(c))
+	}
+	return cookies, nil
+}
+
+func (d *devtools) SetCookies(ctx context.Context, cookies []api.Cookie) error {
+	conn, err := d.connection()
+	if err != nil {
+		return err
+	}
+
+	params := make([]cookie, 0, len(cookies))
+	for _, c := range cookies {
+		params = append(params, toCookie(c))
+	}
+	err = conn.Call(ctx, "Network.setCookies", map[string]interface{}{"cookies": params}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+	return nil
+}
+
+func (d *devtools) DeleteCookies(ctx context.Context, cookies []api.Cookie) error {
+	conn, err := d.connection()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range cookies {
+		c := toCookie(c)
+		err = conn.Call(ctx, "Network.deleteCookies", map[string]interface{}{
+			"name":   c.Name,
+			"domain": c.Domain,
+			"path":   c.Path,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to delete cookie %s of %s: %w", c.Name, c.Domain, err)
+		}
+	}
+	return nil
+}
+
+func (d *devtools) ClearData(ctx context.Context, opts api.ClearOptions) error {
+	conn, err := d.connection()
+	if err != nil {
+		return err
+	}
+
+	if opts.Cookies {
+		err = conn.Call(ctx, "Network.clearBrowserCookies", nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to clear cookies: %w", err)
+		}
+	}
+	if opts.Cache {
+		err = conn.Call(ctx, "Network.clearBrowserCache", nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	if !opts.Storage {
+		return nil
+	}
+
+	// storage can only be cleared by origin
+	var current string
+	value, err := d.Eval(ctx, clearSession)
+	if err != nil {
+		d.log.Warn("failed to clear page session storage", zap.String("browser", d.name), zap.Error(err))
+	} else {
+		_ = json.Unmarshal(value, &current)
+	}
+	cleared := map[string]bool{}
+	for _, origin := range append(opts.Origins, current) {
+		// pages without origin, e.g. local files, report "null"
+		if origin == "" || origin == "null" || cleared[origin] {
+			continue
+		}
+		cleared[origin] = true
+		err = conn.Call(ctx, "Storage.clearDataForOrigin", map[string]interface{}{"origin": origin, "storageTypes": "all"}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to clear storage of %s: %w", origin, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package brightness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/client"
+)
+
+type config struct {
+	unikioskServerUrl string
+	token             string
+	caCert            string
+	clientCert        string
+	clientKey         string
+	display           int
+	schedule          string
+	clearSchedule     bool
+}
+
+// New returns the cobra command for "brightness".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "brightness [level]",
+		Short: "Get or set backlight brightness",
+		Long:  "Get backlight brightness, set it in percent or set brightness schedule over the day",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Context(), c, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", client.DefaultServerURL, "Screen management API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().StringVar(&c.caCert, "ca-cert", "", "CA bundle to verify screen certificate when API uses TLS")
+	cmd.Flags().StringVar(&c.clientCert, "client-cert", "", "Client certificate when API requires it")
+	cmd.Flags().StringVar(&c.clientKey, "client-key", "", "Client certificate key")
+	cmd.Flags().IntVarP(&c.display, "display", "d", 0, "Id of display")
+	cmd.Flags().StringVar(&c.schedule, "schedule", "", "Brightness schedule [time=level,...]. Example: 07:00=100,22:00=20")
+	cmd.Flags().BoolVar(&c.clearSchedule, "clear-schedule", false, "Remove brightness schedule")
+
+	return cmd
+}
+
+func run(ctx context.Context, c config, args []string) error {
+	in := api.BrightnessConfig{}
+	if len(args) == 1 {
+		level, err := strconv.Atoi(strings.TrimSuffix(args[0], "%"))
+		if err != nil {
+			return fmt.Errorf("invalid brightness level: %s", args[0])
+		}
+		in.Level = &level
+	}
+	switch {
+	case c.clearSchedule:
+		in.Schedule = []api.BrightnessPoint{}
+	case c.schedule != "":
+		var err error
+		in.Schedule, err = parseSchedule(c.schedule)
+		if err != nil {
+			return err
+		}
+	}
+
+	tlsConfig, err := client.TLSConfig(c.caCert, c.clientCert, c.clientKey)
+	if err != nil {
+		return err
+	}
+	cl, err := client.New(c.unikioskServerUrl, client.WithToken(c.token), client.WithTLSConfig(tlsConfig), client.WithDisplay(c.display))
+	if err != nil {
+		return err
+	}
+
+	var result *api.Brightness
+	if in.Level == nil && in.Schedule == nil {
+		result, err = cl.Brightness(ctx)
+	} else {
+		result, err = cl.SetBrightness(ctx, in)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute: %w", err)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// parseSchedule parses schedule in format time=level,time=level
+func parseSchedule(in string) ([]api.BrightnessPoint, error) {
+	var schedule []api.BrightnessPoint
+	for _, part := range strings.Split(in, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid schedule point %q, expected time=level", part)
+		}
+		level, err := strconv.Atoi(strings.TrimSuffix(kv[1], "%"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid brightness level in schedule point %q", part)
+		}
+		schedule = append(schedule, api.BrightnessPoint{Time: kv[0], Level: level})
+	}
+	return schedule, nil
+}
@@ -5,7 +5,14 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/unikiosk/unikiosk/pkg/cli/brightness"
+	"github.com/unikiosk/unikiosk/pkg/cli/eval"
+	"github.com/unikiosk/unikiosk/pkg/cli/output"
+	"github.com/unikiosk/unikiosk/pkg/cli/schedule"
+	"github.com/unikiosk/unikiosk/pkg/cli/screenshot"
 	"github.com/unikiosk/unikiosk/pkg/cli/set"
+	"github.com/unikiosk/unikiosk/pkg/cli/state"
+	"github.com/unikiosk/unikiosk/pkg/cli/watch"
 )
 
 const (
@@ -24,7 +31,14 @@ func RunCLI(ctx context.Context) error {
 		Use:   "unikiosk --help",
 	}
 
+	cmd.AddCommand(eval.New())
+	cmd.AddCommand(output.New())
+	cmd.AddCommand(brightness.New())
+	cmd.AddCommand(schedule.New())
+	cmd.AddCommand(screenshot.New())
 	cmd.AddCommand(set.New())
+	cmd.AddCommand(state.New())
+	cmd.AddCommand(watch.New())
 
 	// This will already have global config enriched with values
 	return cmd.ExecuteContext(ctx)
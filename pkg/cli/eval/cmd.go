@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/unikiosk/unikiosk/pkg/client"
+)
+
+type config struct {
+	unikioskServerUrl string
+	token             string
+	caCert            string
+	clientCert        string
+	clientKey         string
+	timeout           time.Duration
+	display           int
+}
+
+// New returns the cobra command for "eval".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "eval <expression>",
+		Short: "Evaluate javascript in the screen page",
+		Long:  "Evaluate javascript expression in the page shown on the screen and print its json value",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return eval(cmd.Context(), c, strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", client.DefaultServerURL, "Screen management API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().StringVar(&c.caCert, "ca-cert", "", "CA bundle to verify screen certificate when API uses TLS")
+	cmd.Flags().StringVar(&c.clientCert, "client-cert", "", "Client certificate when API requires it")
+	cmd.Flags().StringVar(&c.clientKey, "client-key", "", "Client certificate key")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", 0, "Evaluation timeout, server default is used when not set")
+	cmd.Flags().IntVarP(&c.display, "display", "d", 0, "Id of display to evaluate expression in")
+
+	return cmd
+}
+
+func eval(ctx context.Context, c config, expression string) error {
+	tlsConfig, err := client.TLSConfig(c.caCert, c.clientCert, c.clientKey)
+	if err != nil {
+		return err
+	}
+	cl, err := client.New(c.unikioskServerUrl, client.WithToken(c.token), client.WithTLSConfig(tlsConfig), client.WithDisplay(c.display))
+	if err != nil {
+		return err
+	}
+
+	result, err := cl.Eval(ctx, expression, c.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate expression: %w", err)
+	}
+	if result.Exception != "" {
+		return fmt.Errorf("expression threw exception: %s", result.Exception)
+	}
+	fmt.Println(string(result.Result))
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/unikiosk/unikiosk/pkg/grpc/service"
+)
+
+// DefaultServerAddr is default address of kiosk gRPC api
+const DefaultServerAddr = "localhost:7000"
+
+var dialTimeout = 10 * time.Second
+
+// New dials kiosk gRPC api. Returned close function must be called once client is no longer needed
+func New(ctx context.Context, addr, token string) (service.KioskServiceClient, func() error, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to screen %s: %w", addr, err)
+	}
+
+	return service.NewKioskServiceClient(conn), conn.Close, nil
+}
+
+// tokenCredentials attaches api token to every call
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/client"
+)
+
+type config struct {
+	unikioskServerUrl string
+	token             string
+	caCert            string
+	clientCert        string
+	clientKey         string
+	resolution        string
+	rate              float64
+	rotation          string
+}
+
+// New returns the cobra command for "output".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "output [name]",
+		Short: "List or configure screen outputs",
+		Long:  "List video outputs with their modes, or change resolution, refresh rate and rotation of output",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return list(cmd.Context(), c)
+			}
+			return set(cmd.Context(), c, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", client.DefaultServerURL, "Screen management API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().StringVar(&c.caCert, "ca-cert", "", "CA bundle to verify screen certificate when API uses TLS")
+	cmd.Flags().StringVar(&c.clientCert, "client-cert", "", "Client certificate when API requires it")
+	cmd.Flags().StringVar(&c.clientKey, "client-key", "", "Client certificate key")
+	cmd.Flags().StringVar(&c.resolution, "resolution", "", "Output resolution [widthXheight]. Example: 1920x1080")
+	cmd.Flags().Float64Var(&c.rate, "rate", 0, "Refresh rate in Hz, preferred or the fastest one when not set")
+	cmd.Flags().StringVar(&c.rotation, "rotation", "", "Output rotation [normal,left,inverted,right]")
+
+	return cmd
+}
+
+func newClient(c config) (*client.Client, error) {
+	tlsConfig, err := client.TLSConfig(c.caCert, c.clientCert, c.clientKey)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(c.unikioskServerUrl, client.WithToken(c.token), client.WithTLSConfig(tlsConfig))
+}
+
+func list(ctx context.Context, c config) error {
+	cl, err := newClient(c)
+	if err != nil {
+		return err
+	}
+	outputs, err := cl.Outputs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list outputs: %w", err)
+	}
+	return print(outputs)
+}
+
+func set(ctx context.Context, c config, name string) error {
+	in := api.OutputConfig{Name: name, Rate: c.rate, Rotation: c.rotation}
+	if c.resolution != "" {
+		var err error
+		in.Width, in.Height, err = parseResolution(c.resolution)
+		if err != nil {
+			return err
+		}
+	}
+	if in.Width == 0 && in.Rate == 0 && in.Rotation == "" {
+		return fmt.Errorf("one of --resolution, --rate or --rotation is required")
+	}
+	if !api.ValidRotation(in.Rotation) {
+		return fmt.Errorf("invalid rotation %q, expected one of: normal, left, inverted, right", in.Rotation)
+	}
+
+	cl, err := newClient(c)
+	if err != nil {
+		return err
+	}
+	output, err := cl.SetOutput(ctx, in)
+	if err != nil {
+		return fmt.Errorf("failed to configure output: %w", err)
+	}
+	return print(output)
+}
+
+func print(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// parseResolution parses resolution in format widthXheight
+func parseResolution(in string) (int, int, error) {
+	parts := strings.Split(strings.ToLower(in), "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid resolution: %s", in)
+	}
+	w, err := strconv.Atoi(parts[0])
+	if err != nil || w <= 0 {
+		return 0, 0, fmt.Errorf("invalid resolution: %s", in)
+	}
+	h, err := strconv.Atoi(parts[1])
+	if err != nil || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid resolution: %s", in)
+	}
+	return w, h, nil
+}
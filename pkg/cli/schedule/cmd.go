@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/client"
+)
+
+type config struct {
+	unikioskServerUrl string
+	token             string
+	caCert            string
+	clientCert        string
+	clientKey         string
+	display           int
+	file              string
+	delete            bool
+}
+
+// New returns the cobra command for "schedule".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Get or set screen power schedule",
+		Long:  "Get weekly screen power schedule, set it from json file or remove it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Context(), c)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", client.DefaultServerURL, "Screen management API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().StringVar(&c.caCert, "ca-cert", "", "CA bundle to verify screen certificate when API uses TLS")
+	cmd.Flags().StringVar(&c.clientCert, "client-cert", "", "Client certificate when API requires it")
+	cmd.Flags().StringVar(&c.clientKey, "client-key", "", "Client certificate key")
+	cmd.Flags().IntVarP(&c.display, "display", "d", 0, "Id of display")
+	cmd.Flags().StringVarP(&c.file, "file", "f", "", "Json file with power schedule to set, - reads it from stdin")
+	cmd.Flags().BoolVar(&c.delete, "delete", false, "Remove power schedule")
+
+	return cmd
+}
+
+func run(ctx context.Context, c config) error {
+	tlsConfig, err := client.TLSConfig(c.caCert, c.clientCert, c.clientKey)
+	if err != nil {
+		return err
+	}
+	cl, err := client.New(c.unikioskServerUrl, client.WithToken(c.token), client.WithTLSConfig(tlsConfig), client.WithDisplay(c.display))
+	if err != nil {
+		return err
+	}
+
+	var result *api.PowerScheduleStatus
+	switch {
+	case c.delete:
+		err = cl.DeletePowerSchedule(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to remove power schedule: %w", err)
+		}
+		return nil
+	case c.file != "":
+		in, err := readSchedule(c.file)
+		if err != nil {
+			return err
+		}
+		result, err = cl.SetPowerSchedule(ctx, *in)
+		if err != nil {
+			return fmt.Errorf("failed to set power schedule: %w", err)
+		}
+	default:
+		result, err = cl.PowerSchedule(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get power schedule: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func readSchedule(file string) (*api.PowerSchedule, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	in := &api.PowerSchedule{}
+	err := json.NewDecoder(r).Decode(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read power schedule: %w", err)
+	}
+	return in, nil
+}
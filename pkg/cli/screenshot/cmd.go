@@ -0,0 +1,89 @@
+package screenshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/client"
+)
+
+type config struct {
+	unikioskServerUrl string
+	token             string
+	caCert            string
+	clientCert        string
+	clientKey         string
+	output            string
+	format            string
+	quality           int
+	width             int
+	display           int
+	all               bool
+}
+
+// New returns the cobra command for "screenshot".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "screenshot",
+		Short: "Take screen screenshot",
+		Long:  "Capture screen and save it to file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return screenshot(cmd.Context(), c)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", client.DefaultServerURL, "Screen management API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().StringVar(&c.caCert, "ca-cert", "", "CA bundle to verify screen certificate when API uses TLS")
+	cmd.Flags().StringVar(&c.clientCert, "client-cert", "", "Client certificate when API requires it")
+	cmd.Flags().StringVar(&c.clientKey, "client-key", "", "Client certificate key")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "", "File to write screenshot. Defaults to screenshot.<format>")
+	cmd.Flags().StringVarP(&c.format, "format", "f", api.ScreenshotFormatPNG, "Image format [png,jpeg]")
+	cmd.Flags().IntVarP(&c.quality, "quality", "q", 0, "JPEG quality [1-100]")
+	cmd.Flags().IntVarP(&c.width, "width", "w", 0, "Scale screenshot down to width, keeping aspect ratio")
+	cmd.Flags().IntVarP(&c.display, "display", "d", 0, "Id of display to capture")
+	cmd.Flags().BoolVar(&c.all, "all", false, "Capture all displays into one image")
+
+	return cmd
+}
+
+func screenshot(ctx context.Context, c config) error {
+	tlsConfig, err := client.TLSConfig(c.caCert, c.clientCert, c.clientKey)
+	if err != nil {
+		return err
+	}
+	cl, err := client.New(c.unikioskServerUrl, client.WithToken(c.token), client.WithTLSConfig(tlsConfig))
+	if err != nil {
+		return err
+	}
+
+	if c.output == "" {
+		c.output = "screenshot." + c.format
+	}
+
+	f, err := os.Create(c.output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", c.output, err)
+	}
+	defer f.Close()
+
+	err = cl.Screenshot(ctx, f, api.ScreenshotOptions{
+		Format:  c.format,
+		Quality: c.quality,
+		Width:   c.width,
+		Display: c.display,
+		Stitch:  c.all,
+	})
+	if err != nil {
+		os.Remove(c.output)
+		return fmt.Errorf("failed to take screenshot: %w", err)
+	}
+
+	fmt.Printf("screenshot saved to %s\n", c.output)
+	return nil
+}
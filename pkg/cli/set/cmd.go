@@ -1,25 +1,32 @@
 package set
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/client"
 )
 
 type config struct {
 	url               string
 	unikioskServerUrl string
+	token             string
+	caCert            string
+	clientCert        string
+	clientKey         string
 	file              string
 	action            string
 	screenResolution  string
+	position          string
+	fullscreen        bool
+	allowlist         []string
+	display           int
 }
 
 // New returns the cobra command for "set".
@@ -40,65 +47,121 @@ func New() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&c.url, "url", "u", "", "Set desired URL to be opened")
-	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", "http://localhost:8081/api", "Set desired URL to be opened")
+	cmd.Flags().StringVarP(&c.unikioskServerUrl, "server", "s", client.DefaultServerURL, "Screen management API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().StringVar(&c.caCert, "ca-cert", "", "CA bundle to verify screen certificate when API uses TLS")
+	cmd.Flags().StringVar(&c.clientCert, "client-cert", "", "Client certificate when API requires it")
+	cmd.Flags().StringVar(&c.clientKey, "client-key", "", "Client certificate key")
 	cmd.Flags().StringVarP(&c.file, "file", "f", "", "File to write screenshoot")
 	cmd.Flags().StringVarP(&c.action, "action", "a", "update", "Screen action [start,update,poweron,poweroff]")
 	cmd.Flags().StringVarP(&c.screenResolution, "resolution", "", "", "Screen resolution [widthXheight]. Example: 1920x1080")
+	cmd.Flags().StringVarP(&c.position, "position", "", "", "Window position [x,y], requires --resolution. Example: 0,0")
+	cmd.Flags().BoolVarP(&c.fullscreen, "fullscreen", "", false, "Switch window to fullscreen")
+	cmd.Flags().StringSliceVar(&c.allowlist, "allow", nil, "URL pattern content can navigate to, can be repeated. Example: https://*.synpse.net/*")
+	cmd.Flags().IntVarP(&c.display, "display", "d", 0, "Id of display to change")
 
 	return cmd
 }
 
 func set(ctx context.Context, c config) error {
-	req := api.KioskRequest{}
-	if c.url != "" {
-		req.Content = c.url
+	tlsConfig, err := client.TLSConfig(c.caCert, c.clientCert, c.clientKey)
+	if err != nil {
+		return err
+	}
+	cl, err := client.New(c.unikioskServerUrl, client.WithToken(c.token), client.WithTLSConfig(tlsConfig), client.WithDisplay(c.display))
+	if err != nil {
+		return err
 	}
+
+	content := c.url
 	if c.file != "" {
-		req.Content = c.file
+		content = c.file
 	}
-	// set size
-	res := strings.ToLower(c.screenResolution)
-	if c.screenResolution != "" && strings.Contains(res, "x") {
-		parts := strings.Split(res, "x")
-		w, err := strconv.Atoi(parts[0])
+
+	action, err := api.StringToAction(c.action)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case api.ScreenActionUpdate:
+		if content != "" {
+			_, err = cl.SetContent(ctx, api.Content{Content: content, Allowlist: c.allowlist})
+			if err != nil {
+				return fmt.Errorf("failed to update screen: %w", err)
+			}
+		}
+		if c.screenResolution != "" || c.position != "" || c.fullscreen {
+			window := api.Window{Fullscreen: c.fullscreen}
+			if c.screenResolution != "" {
+				window.Width, window.Height, err = parseResolution(c.screenResolution)
+				if err != nil {
+					return err
+				}
+			}
+			if c.position != "" {
+				window.X, window.Y, err = parsePosition(c.position)
+				if err != nil {
+					return err
+				}
+			}
+			_, err = cl.SetWindow(ctx, window)
+			if err != nil {
+				return fmt.Errorf("failed to resize screen: %w", err)
+			}
+		}
+	case api.ScreenActionPowerOn:
+		_, err = cl.SetPower(ctx, api.PowerStateOn)
+		if err != nil {
+			return fmt.Errorf("failed to power on screen: %w", err)
+		}
+	case api.ScreenActionPowerOff:
+		_, err = cl.SetPower(ctx, api.PowerStateOff)
 		if err != nil {
-			return fmt.Errorf("invalid resolution: %s", c.screenResolution)
+			return fmt.Errorf("failed to power off screen: %w", err)
 		}
-		h, err := strconv.Atoi(parts[1])
+	default:
+		_, err = cl.Apply(ctx, api.KioskRequest{Action: action, Display: c.display})
 		if err != nil {
-			return fmt.Errorf("invalid resolution: %s", c.screenResolution)
+			return fmt.Errorf("failed to update screen: %w", err)
 		}
-		req.SizeW = w
-		req.SizeH = h
 	}
 
-	action, err := api.StringToAction(c.action)
-	if err != nil {
-		return err
-	}
-	req.Action = action
+	return nil
+}
 
-	data, err := json.Marshal(req)
+// parseResolution parses resolution in format widthXheight
+func parseResolution(in string) (int, int, error) {
+	parts := strings.Split(strings.ToLower(in), "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid resolution: %s", in)
+	}
+	w, err := strconv.Atoi(parts[0])
 	if err != nil {
-		return fmt.Errorf("failed to marshal: %s", err.Error())
+		return 0, 0, fmt.Errorf("invalid resolution: %s", in)
 	}
-
-	response, err := http.Post(c.unikioskServerUrl, api.ContentTypeApplicationJSON, bytes.NewBuffer(data))
+	h, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return fmt.Errorf("failed to update screen while reaching out to screen: %s", err.Error())
+		return 0, 0, fmt.Errorf("invalid resolution: %s", in)
 	}
+	return w, h, nil
+}
 
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("screen is not reachable: %d", response.StatusCode)
+// parsePosition parses window position in format x,y
+func parsePosition(in string) (int, int, error) {
+	parts := strings.Split(in, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid position: %s", in)
 	}
-
-	result := api.KioskResponse{}
-	err = json.NewDecoder(response.Body).Decode(&result)
+	x, err := strconv.Atoi(strings.TrimSpace(parts[0]))
 	if err != nil {
-		return fmt.Errorf("failed to read response: %s", err.Error())
+		return 0, 0, fmt.Errorf("invalid position: %s", in)
 	}
-
-	return nil
+	y, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position: %s", in)
+	}
+	return x, y, nil
 }
 
 func validate(c config) error {
@@ -107,6 +170,12 @@ func validate(c config) error {
 			return fmt.Errorf("--action can't be used with --url or --file")
 		}
 	}
+	if len(c.allowlist) > 0 && c.file == "" && c.url == "" {
+		return fmt.Errorf("--allow requires --url or --file")
+	}
+	if c.position != "" && c.screenResolution == "" && !c.fullscreen {
+		return fmt.Errorf("--position requires --resolution")
+	}
 	//if c.file != "" && c.url != "" {
 	//	return fmt.Errorf("only --url or --file can be provided")
 	//}
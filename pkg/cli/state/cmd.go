@@ -0,0 +1,71 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/unikiosk/unikiosk/pkg/cli/grpcclient"
+	"github.com/unikiosk/unikiosk/pkg/client"
+	"github.com/unikiosk/unikiosk/pkg/grpc/service"
+)
+
+type config struct {
+	grpcServerAddr string
+	token          string
+	display        int
+	displays       bool
+}
+
+// New returns the cobra command for "state".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Get screen state",
+		Long:  "Print current screen state or displays of the screen",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return state(cmd.Context(), c)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.grpcServerAddr, "grpc-server", "g", grpcclient.DefaultServerAddr, "Screen gRPC API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+	cmd.Flags().IntVarP(&c.display, "display", "d", 0, "Id of display to print state of")
+	cmd.Flags().BoolVar(&c.displays, "displays", false, "List displays instead of state")
+
+	return cmd
+}
+
+func state(ctx context.Context, c config) error {
+	client, closeConn, err := grpcclient.New(ctx, c.grpcServerAddr, c.token)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	var result proto.Message
+	if c.displays {
+		result, err = client.GetDisplays(ctx, &service.GetDisplaysRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to get displays: %w", err)
+		}
+	} else {
+		result, err = client.GetState(ctx, &service.GetStateRequest{Display: int32(c.display)})
+		if err != nil {
+			return fmt.Errorf("failed to get screen state: %w", err)
+		}
+	}
+
+	data, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal: %s", err.Error())
+	}
+	fmt.Println(string(data))
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/unikiosk/unikiosk/pkg/cli/grpcclient"
+	"github.com/unikiosk/unikiosk/pkg/client"
+	"github.com/unikiosk/unikiosk/pkg/grpc/service"
+)
+
+type config struct {
+	grpcServerAddr string
+	token          string
+}
+
+// New returns the cobra command for "watch".
+func New() *cobra.Command {
+	var c config
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch screen events",
+		Long:  "Stream screen state changes until interrupted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return watch(cmd.Context(), c)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.grpcServerAddr, "grpc-server", "g", grpcclient.DefaultServerAddr, "Screen gRPC API address")
+	cmd.Flags().StringVarP(&c.token, "token", "t", os.Getenv(client.TokenEnv), "API token, defaults to $"+client.TokenEnv)
+
+	return cmd
+}
+
+func watch(ctx context.Context, c config) error {
+	client, closeConn, err := grpcclient.New(ctx, c.grpcServerAddr, c.token)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	stream, err := client.WatchEvents(ctx, &service.WatchEventsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to watch screen events: %w", err)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("event stream failed: %w", err)
+		}
+
+		data, err := protojson.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal: %s", err.Error())
+		}
+		fmt.Println(string(data))
+	}
+}
@@ -0,0 +1,503 @@
+package client
+
+// client is Go client for UniKiosk management api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/util/certs"
+)
+
+var (
+	// DefaultServerURL is default address of kiosk management api
+	DefaultServerURL = "http://localhost:8081"
+	// DefaultTimeout is default timeout of a single request
+	DefaultTimeout = 30 * time.Second
+	// DefaultRetries is default number of retries for idempotent requests
+	DefaultRetries = 3
+	// DefaultRetryWait is initial wait between retries. It doubles with every retry
+	DefaultRetryWait = 500 * time.Millisecond
+	// TokenEnv is environment variable command line tools read api token from
+	TokenEnv = "UNIKIOSK_TOKEN"
+)
+
+// Client talks to kiosk management api
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+	retries    int
+	retryWait  time.Duration
+	// display is id of display requests address
+	display int
+}
+
+// Option configures client
+type Option func(*Client)
+
+// WithTimeout sets timeout of a single request
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithRetries sets number of retries and initial wait between them for idempotent requests
+func WithRetries(retries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryWait = wait
+	}
+}
+
+// WithDisplay addresses requests to display with given id. Requests address the first display by default
+func WithDisplay(display int) Option {
+	return func(c *Client) {
+		c.display = display
+	}
+}
+
+// WithToken sets api token used to authenticate requests
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig sets TLS configuration used to connect to kiosk. nil keeps system defaults
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		if tlsConfig == nil {
+			return
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithHTTPClient sets http client used to execute requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// TLSConfig builds TLS configuration from PEM files. caFile is used to verify kiosk certificate,
+// certFile and keyFile are client certificate presented to kiosk. Returns nil if no files are provided
+func TLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := certs.LoadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
+
+// New returns client for kiosk served at serverURL, example: http://localhost:8081
+func New(serverURL string, opts ...Option) (*Client, error) {
+	// older CLI versions used full legacy endpoint as server address
+	serverURL = strings.TrimSuffix(strings.TrimSuffix(serverURL, "/"), "/api")
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server url %s: %w", serverURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid server url %s: scheme and host are required", serverURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		retries:    DefaultRetries,
+		retryWait:  DefaultRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// State returns full kiosk state
+func (c *Client) State(ctx context.Context) (*api.State, error) {
+	result := &api.State{}
+	return result, c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/state"), nil, result)
+}
+
+// Content returns displayed content
+func (c *Client) Content(ctx context.Context) (*api.Content, error) {
+	result := &api.Content{}
+	return result, c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/content"), nil, result)
+}
+
+// SetContent replaces displayed content
+func (c *Client) SetContent(ctx context.Context, in api.Content) (*api.Content, error) {
+	result := &api.Content{}
+	return result, c.doJSON(ctx, http.MethodPut, c.displayPath("/api/v1/content"), in, result)
+}
+
+// Power returns screen power state
+func (c *Client) Power(ctx context.Context) (api.PowerState, error) {
+	result := &api.Power{}
+	err := c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/power"), nil, result)
+	if err != nil {
+		return api.PowerStateUnknown, err
+	}
+	return api.StringToPowerState(result.State)
+}
+
+// SetPower turns screen on or off
+func (c *Client) SetPower(ctx context.Context, state api.PowerState) (api.PowerState, error) {
+	result := &api.Power{}
+	err := c.doJSON(ctx, http.MethodPut, c.displayPath("/api/v1/power"), api.Power{State: state.String()}, result)
+	if err != nil {
+		return api.PowerStateUnknown, err
+	}
+	return api.StringToPowerState(result.State)
+}
+
+// PowerSchedule returns power schedule with power it requests now
+func (c *Client) PowerSchedule(ctx context.Context) (*api.PowerScheduleStatus, error) {
+	result := &api.PowerScheduleStatus{}
+	return result, c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/power/schedule"), nil, result)
+}
+
+// SetPowerSchedule replaces power schedule
+func (c *Client) SetPowerSchedule(ctx context.Context, in api.PowerSchedule) (*api.PowerScheduleStatus, error) {
+	result := &api.PowerScheduleStatus{}
+	return result, c.doJSON(ctx, http.MethodPut, c.displayPath("/api/v1/power/schedule"), in, result)
+}
+
+// DeletePowerSchedule removes power schedule, screen keeps its power
+func (c *Client) DeletePowerSchedule(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.displayPath("/api/v1/power/schedule"), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Window returns kiosk window size
+func (c *Client) Window(ctx context.Context) (*api.Window, error) {
+	result := &api.Window{}
+	return result, c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/window"), nil, result)
+}
+
+// SetWindow sets kiosk window size
+func (c *Client) SetWindow(ctx context.Context, in api.Window) (*api.Window, error) {
+	result := &api.Window{}
+	return result, c.doJSON(ctx, http.MethodPut, c.displayPath("/api/v1/window"), in, result)
+}
+
+// Displays returns displays kiosk shows content on
+func (c *Client) Displays(ctx context.Context) ([]api.Display, error) {
+	result := []api.Display{}
+	return result, c.doJSON(ctx, http.MethodGet, "/api/v1/displays", nil, &result)
+}
+
+// Outputs returns video outputs of the screen with modes they support
+func (c *Client) Outputs(ctx context.Context) ([]api.Output, error) {
+	result := []api.Output{}
+	return result, c.doJSON(ctx, http.MethodGet, "/api/v1/outputs", nil, &result)
+}
+
+// SetOutput changes resolution, refresh rate and rotation of output. Zero fields keep current value
+func (c *Client) SetOutput(ctx context.Context, in api.OutputConfig) (*api.Output, error) {
+	result := &api.Output{}
+	return result, c.doJSON(ctx, http.MethodPut, "/api/v1/outputs/"+url.PathEscape(in.Name), in, result)
+}
+
+// Brightness returns backlight brightness and its schedule
+func (c *Client) Brightness(ctx context.Context) (*api.Brightness, error) {
+	result := &api.Brightness{}
+	return result, c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/brightness"), nil, result)
+}
+
+// SetBrightness sets backlight brightness level, schedule or both. Nil schedule keeps current one, empty one removes it
+func (c *Client) SetBrightness(ctx context.Context, in api.BrightnessConfig) (*api.Brightness, error) {
+	result := &api.Brightness{}
+	return result, c.doJSON(ctx, http.MethodPut, c.displayPath("/api/v1/brightness"), in, result)
+}
+
+// Screenshot captures screen and writes image into w. Zero options capture display of the client as png,
+// Stitch captures all displays into one image
+func (c *Client) Screenshot(ctx context.Context, w io.Writer, opts api.ScreenshotOptions) error {
+	q := url.Values{}
+	if opts.Format != "" {
+		q.Set("format", opts.Format)
+	}
+	if opts.Quality > 0 {
+		q.Set("quality", strconv.Itoa(opts.Quality))
+	}
+	if opts.Width > 0 {
+		q.Set("width", strconv.Itoa(opts.Width))
+	}
+	switch {
+	case opts.Stitch:
+		q.Set("display", "all")
+	case opts.Display > 0:
+		q.Set("display", strconv.Itoa(opts.Display))
+	case c.display > 0:
+		q.Set("display", strconv.Itoa(c.display))
+	}
+	path := "/api/v1/screenshot"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download screenshot: %w", err)
+	}
+	return nil
+}
+
+// Eval evaluates javascript expression in the current page. Zero timeout uses server default.
+// Exception thrown by expression is returned in result, not as error
+func (c *Client) Eval(ctx context.Context, expression string, timeout time.Duration) (*api.EvalResult, error) {
+	in := api.Eval{Expression: expression}
+	if timeout > 0 {
+		in.Timeout = timeout.String()
+	}
+	result := &api.EvalResult{}
+	return result, c.doJSON(ctx, http.MethodPost, c.displayPath("/api/v1/eval"), in, result)
+}
+
+// Cookies returns browser cookies of domain and its subdomains with given name. Empty domain and name match all cookies
+func (c *Client) Cookies(ctx context.Context, domain, name string) ([]api.Cookie, error) {
+	result := []api.Cookie{}
+	return result, c.doJSON(ctx, http.MethodGet, c.displayPath("/api/v1/browser/cookies"+cookieQuery(domain, name)), nil, &result)
+}
+
+// SetCookies creates or replaces browser cookies
+func (c *Client) SetCookies(ctx context.Context, cookies []api.Cookie) error {
+	body, err := json.Marshal(cookies)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.displayPath("/api/v1/browser/cookies"), body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DeleteCookies removes browser cookies of domain and its subdomains with given name and returns them.
+// Empty domain and name remove all cookies
+func (c *Client) DeleteCookies(ctx context.Context, domain, name string) ([]api.Cookie, error) {
+	result := []api.Cookie{}
+	return result, c.doJSON(ctx, http.MethodDelete, c.displayPath("/api/v1/browser/cookies"+cookieQuery(domain, name)), nil, &result)
+}
+
+// ClearData removes browser cookies, storage or cache
+func (c *Client) ClearData(ctx context.Context, in api.ClearData) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.displayPath("/api/v1/browser/clear"), body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// displayPath adds display of the client to path of display resource
+func (c *Client) displayPath(path string) string {
+	if c.display == 0 {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "display=" + strconv.Itoa(c.display)
+}
+
+func cookieQuery(domain, name string) string {
+	q := url.Values{}
+	if domain != "" {
+		q.Set("domain", domain)
+	}
+	if name != "" {
+		q.Set("name", name)
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// Apply executes action using legacy action based api
+func (c *Client) Apply(ctx context.Context, in api.KioskRequest) (*api.KioskResponse, error) {
+	result := &api.KioskResponse{}
+	return result, c.doJSON(ctx, http.MethodPost, "/api", in, result)
+}
+
+func (c *Client) doJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	resp, err := c.do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil
+}
+
+// do executes request and returns response with 2xx status code. Api errors are returned as *api.ErrorResponse.
+// Idempotent requests are retried on network errors and temporary server errors
+func (c *Client) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	retries := 0
+	if method == http.MethodGet || method == http.MethodPut {
+		retries = c.retries
+	}
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		resp, err := c.once(ctx, method, path, body)
+		if err == nil || attempt >= retries || !retryable(err) {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (c *Client) once(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	u := *c.baseURL
+	path, query := splitQuery(path)
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = query
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", api.ContentTypeApplicationJSON)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &api.ErrorResponse{}
+	err = json.NewDecoder(resp.Body).Decode(apiErr)
+	if err != nil || apiErr.Code == 0 {
+		// not our api or proxy in between
+		return nil, &api.ErrorResponse{
+			Code:    resp.StatusCode,
+			Message: http.StatusText(resp.StatusCode),
+		}
+	}
+	return nil, apiErr
+}
+
+func splitQuery(path string) (string, string) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	apiErr := &api.ErrorResponse{}
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// network errors
+	return true
+}
+
+// IsNotFound returns true if err is api error with 404 status code
+func IsNotFound(err error) bool {
+	return hasCode(err, http.StatusNotFound)
+}
+
+// IsUnauthorized returns true if err is api error with 401 status code
+func IsUnauthorized(err error) bool {
+	return hasCode(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if err is api error with 403 status code
+func IsForbidden(err error) bool {
+	return hasCode(err, http.StatusForbidden)
+}
+
+// IsBadRequest returns true if err is api error with 400 status code
+func IsBadRequest(err error) bool {
+	return hasCode(err, http.StatusBadRequest)
+}
+
+func hasCode(err error, code int) bool {
+	apiErr := &api.ErrorResponse{}
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/web/response"
+)
+
+func TestClient_retries(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			response.Error(w, r, http.StatusServiceUnavailable, "not ready")
+			return
+		}
+		response.JSON(w, http.StatusOK, api.State{Content: "https://synpse.net", Power: "on"})
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL+"/api", WithRetries(3, time.Millisecond))
+	require.NoError(err)
+
+	state, err := c.State(context.Background())
+	require.NoError(err)
+	require.Equal("https://synpse.net", state.Content)
+	require.Equal(int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestClient_errors(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/api/v1/state":
+			response.Error(w, r, http.StatusNotFound, "kiosk state not found")
+		case "/api":
+			response.Error(w, r, http.StatusGatewayTimeout, "callback timeout")
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithRetries(3, time.Millisecond))
+	require.NoError(err)
+
+	_, err = c.State(context.Background())
+	require.True(IsNotFound(err))
+	apiErr := &api.ErrorResponse{}
+	require.True(errors.As(err, &apiErr))
+	require.Equal("kiosk state not found", apiErr.Message)
+	require.Equal(int32(1), atomic.SwapInt32(&calls, 0))
+
+	// non idempotent requests are not retried
+	_, err = c.Apply(context.Background(), api.KioskRequest{Action: api.ScreenActionStart})
+	require.Error(err)
+	require.Equal(int32(1), atomic.SwapInt32(&calls, 0))
+
+	// errors without api envelope are still typed
+	err = c.Screenshot(context.Background(), &bytes.Buffer{}, api.ScreenshotOptions{})
+	require.True(errors.As(err, &apiErr))
+	require.Equal(http.StatusBadGateway, apiErr.Code)
+	require.Equal(int32(4), atomic.SwapInt32(&calls, 0))
+}
+
+func TestClient_token(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			response.Error(w, r, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		response.JSON(w, http.StatusOK, api.Power{State: "on"})
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL)
+	require.NoError(err)
+	_, err = c.Power(context.Background())
+	require.True(IsUnauthorized(err))
+
+	c, err = New(server.URL, WithToken("secret"))
+	require.NoError(err)
+	power, err := c.Power(context.Background())
+	require.NoError(err)
+	require.Equal(api.PowerStateOn, power)
+}
+
+func TestClient_display(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	queries := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.RawQuery
+		switch r.URL.Path {
+		case "/api/v1/screenshot":
+			w.WriteHeader(http.StatusOK)
+		default:
+			response.JSON(w, http.StatusOK, []api.Cookie{})
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithDisplay(1))
+	require.NoError(err)
+
+	_, err = c.Cookies(context.Background(), "synpse.net", "")
+	require.NoError(err)
+	require.Equal("domain=synpse.net&display=1", <-queries)
+
+	require.NoError(c.Screenshot(context.Background(), &bytes.Buffer{}, api.ScreenshotOptions{}))
+	require.Equal("display=1", <-queries)
+
+	require.NoError(c.Screenshot(context.Background(), &bytes.Buffer{}, api.ScreenshotOptions{Stitch: true}))
+	require.Equal("display=all", <-queries)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -26,12 +28,87 @@ type Config struct {
 	// WebServerAddr - address of where internal web server binds
 	WebServerAddr string `yaml:"webServerAddr,omitempty" envconfig:"WEB_SERVER_ADDR"  default:":8081"` // web server bind port
 
+	// WebServerTLS enables TLS on management web server. When cert and key are not provided,
+	// self-signed certificate is generated in StateDir
+	WebServerTLS     bool   `yaml:"webServerTLS,omitempty" envconfig:"WEB_SERVER_TLS"  default:"false"`
+	WebServerTLSCert string `yaml:"webServerTLSCert,omitempty" envconfig:"WEB_SERVER_TLS_CERT"  default:""`
+	WebServerTLSKey  string `yaml:"webServerTLSKey,omitempty" envconfig:"WEB_SERVER_TLS_KEY"  default:""`
+	// WebServerTLSClientCA is CA bundle used to verify client certificates. When set, clients must present certificate
+	WebServerTLSClientCA string `yaml:"webServerTLSClientCA,omitempty" envconfig:"WEB_SERVER_TLS_CLIENT_CA"  default:""`
+	// WebServerLocalAddr is plain HTTP address serving default content to the browser when TLS is enabled
+	WebServerLocalAddr string `yaml:"webServerLocalAddr,omitempty" envconfig:"WEB_SERVER_LOCAL_ADDR"  default:"127.0.0.1:8082"`
+
 	// DefaultWebServerURL is default webserver url. Used to serve default content
 	// Populated automatically
 	DefaultWebServerURL string
 	// ProxyHeaders is key:value pairs of headers proxy will inject into requests. Example: "red:1,green:2,blue:3"
 	ProxyHeaders map[string]string `yaml:"proxyHeaders,omitempty" envconfig:"PROXY_HEADERS"  default:""`
 
+	// APITokens is token:role pairs allowed to use management api. Roles: viewer, operator, admin. Example: "secret1:admin,secret2:viewer"
+	// Additional tokens can be created via api. When no tokens exist, api is not protected
+	APITokens map[string]string `yaml:"apiTokens,omitempty" envconfig:"API_TOKENS"  default:""`
+
+	// AuditRetention defines how long audit log of control actions is kept in StateDir. Zero keeps it forever
+	AuditRetention time.Duration `yaml:"auditRetention,omitempty" envconfig:"AUDIT_RETENTION"  default:"720h"`
+
+	// BrowserDriver selects browser showing content. Options: firefox, chromium
+	BrowserDriver string `yaml:"browserDriver,omitempty" envconfig:"BROWSER_DRIVER"  default:"firefox"`
+	// BrowserBinary is path to browser executable. Looked up in PATH when empty
+	BrowserBinary string `yaml:"browserBinary,omitempty" envconfig:"BROWSER_BIN"  default:""`
+	// BrowserProfileDir is directory where browser keeps its profile. When empty, new temporary profile is used on each browser start
+	BrowserProfileDir string `yaml:"browserProfileDir,omitempty" envconfig:"BROWSER_PROFILE_DIR"  default:""`
+	// BrowserPersistProfile keeps browser profile with cookies and storage in StateDir across restarts, unless BrowserProfileDir is set
+	BrowserPersistProfile bool `yaml:"browserPersistProfile,omitempty" envconfig:"BROWSER_PERSIST_PROFILE"  default:"false"`
+	// BrowserConsoleSize is how many last browser console messages are kept for api
+	BrowserConsoleSize int `yaml:"browserConsoleSize,omitempty" envconfig:"BROWSER_CONSOLE_SIZE"  default:"200"`
+	// BrowserExceptionEvents enables browser_exception events for uncaught page exceptions
+	BrowserExceptionEvents bool `yaml:"browserExceptionEvents,omitempty" envconfig:"BROWSER_EXCEPTION_EVENTS"  default:"false"`
+	// BrowserWatchdogInterval is how often page responsiveness is checked. Zero disables the check
+	BrowserWatchdogInterval time.Duration `yaml:"browserWatchdogInterval,omitempty" envconfig:"BROWSER_WATCHDOG_INTERVAL"  default:"10s"`
+	// BrowserWatchdogTimeout is how long page has to answer the check
+	BrowserWatchdogTimeout time.Duration `yaml:"browserWatchdogTimeout,omitempty" envconfig:"BROWSER_WATCHDOG_TIMEOUT"  default:"5s"`
+	// BrowserWatchdogFailures is number of consecutive failed checks after which browser is restarted
+	BrowserWatchdogFailures int `yaml:"browserWatchdogFailures,omitempty" envconfig:"BROWSER_WATCHDOG_FAILURES"  default:"3"`
+	// BrowserRestartBackoff is delay before first restart, it doubles for every following crash up to BrowserRestartBackoffMax.
+	// Browser running longer than BrowserRestartBackoffMax is considered stable and resets the delay
+	BrowserRestartBackoff    time.Duration `yaml:"browserRestartBackoff,omitempty" envconfig:"BROWSER_RESTART_BACKOFF"  default:"1s"`
+	BrowserRestartBackoffMax time.Duration `yaml:"browserRestartBackoffMax,omitempty" envconfig:"BROWSER_RESTART_BACKOFF_MAX"  default:"1m"`
+	// BrowserCrashLoopRestarts is number of crashes in a row after which restarts are suspended for BrowserCrashLoopPause
+	BrowserCrashLoopRestarts int           `yaml:"browserCrashLoopRestarts,omitempty" envconfig:"BROWSER_CRASH_LOOP_RESTARTS"  default:"5"`
+	BrowserCrashLoopPause    time.Duration `yaml:"browserCrashLoopPause,omitempty" envconfig:"BROWSER_CRASH_LOOP_PAUSE"  default:"5m"`
+	// PageLoadTimeout is how long content has to load before load is considered failed
+	PageLoadTimeout time.Duration `yaml:"pageLoadTimeout,omitempty" envconfig:"PAGE_LOAD_TIMEOUT"  default:"30s"`
+	// PageLoadFallback is shown while content fails to load. Options: last_good (last content which loaded, error page when there is none), error_page, none
+	PageLoadFallback string `yaml:"pageLoadFallback,omitempty" envconfig:"PAGE_LOAD_FALLBACK"  default:"last_good"`
+	// PageErrorTemplate is html/template file of error page shown as fallback. Built-in page is used when empty
+	PageErrorTemplate string `yaml:"pageErrorTemplate,omitempty" envconfig:"PAGE_ERROR_TEMPLATE"  default:""`
+	// PageLoadRetry is delay before failed content is loaded again, it doubles for every following failure up to PageLoadRetryMax.
+	// Zero disables retries
+	PageLoadRetry    time.Duration `yaml:"pageLoadRetry,omitempty" envconfig:"PAGE_LOAD_RETRY"  default:"5s"`
+	PageLoadRetryMax time.Duration `yaml:"pageLoadRetryMax,omitempty" envconfig:"PAGE_LOAD_RETRY_MAX"  default:"5m"`
+	// ScreenDisplay is X11 display screen power is controlled and outputs are listed on. DISPLAY is used when empty, :0 when it is not set either
+	ScreenDisplay string `yaml:"screenDisplay,omitempty" envconfig:"SCREEN_DISPLAY"  default:""`
+	// MultiDisplay runs browser with its own content on every connected output. Only the first display is used otherwise
+	MultiDisplay bool `yaml:"multiDisplay,omitempty" envconfig:"MULTI_DISPLAY"  default:"false"`
+	// PowerCheckInterval is how often actual screen power is read back and corrected to requested one. Zero disables the check
+	PowerCheckInterval time.Duration `yaml:"powerCheckInterval,omitempty" envconfig:"POWER_CHECK_INTERVAL"  default:"30s"`
+	// PowerScheduleInterval is how often power schedule is checked. Zero disables the schedule
+	PowerScheduleInterval time.Duration `yaml:"powerScheduleInterval,omitempty" envconfig:"POWER_SCHEDULE_INTERVAL"  default:"30s"`
+	// BacklightDir is sysfs class directory of backlight devices controlling brightness of the first display
+	BacklightDir string `yaml:"backlightDir,omitempty" envconfig:"BACKLIGHT_DIR"  default:"/sys/class/backlight"`
+	// BacklightDevice is backlight device in BacklightDir. The first device by name is used when empty
+	BacklightDevice string `yaml:"backlightDevice,omitempty" envconfig:"BACKLIGHT_DEVICE"  default:""`
+	// BrightnessCheckInterval is how often brightness schedule is applied. Zero disables the schedule
+	BrightnessCheckInterval time.Duration `yaml:"brightnessCheckInterval,omitempty" envconfig:"BRIGHTNESS_CHECK_INTERVAL"  default:"1m"`
+	// IdleTimeout is how long page can be without user input before kiosk returns to content. Zero disables it
+	IdleTimeout time.Duration `yaml:"idleTimeout,omitempty" envconfig:"IDLE_TIMEOUT"  default:"0"`
+	// IdleSource is how user input is detected. Options: auto (x11 when available, page otherwise), x11 (input on the display), page (input in the page)
+	IdleSource string `yaml:"idleSource,omitempty" envconfig:"IDLE_SOURCE"  default:"auto"`
+	// IdleClearData removes cookies, cache and storage before kiosk returns to content
+	IdleClearData bool `yaml:"idleClearData,omitempty" envconfig:"IDLE_CLEAR_DATA"  default:"false"`
+	// FirefoxUserJSURL is location of base user.js firefox profile is created with. Empty disables download
+	FirefoxUserJSURL string `yaml:"firefoxUserJSURL,omitempty" envconfig:"FIREFOX_USER_JS_URL"  default:"https://raw.githubusercontent.com/unikiosk/user.js/master/user.js"`
+
 	// LogLevel defines log level. Options: info, debug, trace
 	LogLevel string `yaml:"logLevel,omitempty" envconfig:"LOG_LEVEL"  default:"debug"`
 	// StateDir defines where services keeps state
@@ -69,6 +146,13 @@ func Load() (*Config, error) {
 
 	// TODO: add check if user provided full bind URL for webserver
 	c.DefaultWebServerURL = "http://0.0.0.0" + c.WebServerAddr
+	if c.WebServerTLS {
+		// browser keeps loading default content over plain http on local listener
+		c.DefaultWebServerURL = "http://" + c.WebServerLocalAddr
+		if strings.HasPrefix(c.WebServerLocalAddr, ":") {
+			c.DefaultWebServerURL = "http://127.0.0.1" + c.WebServerLocalAddr
+		}
+	}
 
 	return c, err
 }
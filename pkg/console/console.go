@@ -0,0 +1,82 @@
+package console
+
+import (
+	"sync"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// DefaultSize is number of messages kept when size is not configured
+var DefaultSize = 200
+
+// Buffer keeps last browser console messages in memory. Oldest messages are
+// dropped once buffer is full
+type Buffer struct {
+	mu       sync.Mutex
+	messages []api.ConsoleMessage
+	// next is index next message is written to
+	next int
+	full bool
+}
+
+func New(size int) *Buffer {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Buffer{
+		messages: make([]api.ConsoleMessage, size),
+	}
+}
+
+// Add stores message, replacing the oldest one when buffer is full
+func (b *Buffer) Add(m api.ConsoleMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.messages[b.next] = m
+	b.next = (b.next + 1) % len(b.messages)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// List returns messages of level or more severe, newest first. Empty level returns all messages,
+// zero limit returns all matching messages
+func (b *Buffer) List(level string, limit int) []api.ConsoleMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.next
+	if b.full {
+		n = len(b.messages)
+	}
+
+	result := []api.ConsoleMessage{}
+	min := Severity(level)
+	for i := 0; i < n; i++ {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		m := b.messages[(b.next-1-i+len(b.messages))%len(b.messages)]
+		if Severity(m.Level) >= min {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// Severity orders console levels, unknown levels are least severe
+func Severity(level string) int {
+	switch level {
+	case api.ConsoleLevelDebug:
+		return 1
+	case api.ConsoleLevelInfo:
+		return 2
+	case api.ConsoleLevelWarning:
+		return 3
+	case api.ConsoleLevelError:
+		return 4
+	default:
+		return 0
+	}
+}
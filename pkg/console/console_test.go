@@ -0,0 +1,40 @@
+package console
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+func TestBuffer(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	b := New(3)
+	require.Empty(b.List("", 0))
+
+	for i := 0; i < 5; i++ {
+		level := api.ConsoleLevelInfo
+		if i%2 == 0 {
+			level = api.ConsoleLevelError
+		}
+		b.Add(api.ConsoleMessage{Level: level, Text: fmt.Sprint(i)})
+	}
+
+	texts := func(messages []api.ConsoleMessage) []string {
+		result := []string{}
+		for _, m := range messages {
+			result = append(result, m.Text)
+		}
+		return result
+	}
+
+	// oldest messages are dropped, newest are returned first
+	require.Equal([]string{"4", "3", "2"}, texts(b.List("", 0)))
+	require.Equal([]string{"4"}, texts(b.List("", 1)))
+	require.Equal([]string{"4", "2"}, texts(b.List(api.ConsoleLevelWarning, 0)))
+	require.Equal([]string{"4", "3", "2"}, texts(b.List(api.ConsoleLevelDebug, 0)))
+}
@@ -4,7 +4,7 @@ package eventer
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"sync"
 	"time"
 
@@ -25,16 +25,26 @@ var (
 	DefaultCallabackTimeout = 5 * time.Second
 )
 
+var (
+	// ErrCallbackTimeout is returned when event consumer did not respond in time
+	ErrCallbackTimeout = errors.New("callback timeout")
+	// ErrPublishTimeout is returned when event loop did not accept published event in time
+	ErrPublishTimeout = errors.New("publish timeout")
+)
+
 var _ Eventer = &ChannelEventer{}
 
 type EventWrapper struct {
 	Payload  api.Event
 	Callback chan *EventWrapper
+	// Error is set by consumer when event handling failed
+	Error error
 }
 
 type Eventer interface {
 	Subscribe(ctx context.Context) <-chan *EventWrapper
 	Emit(event *EventWrapper) (*EventWrapper, error)
+	Publish(event *EventWrapper) error
 }
 
 // ChannelEventer is a utility to control broadcast of Events to multiple consumers.
@@ -108,7 +118,7 @@ func (e *ChannelEventer) iterateConsumers(event *EventWrapper) {
 		select {
 		case <-time.After(consumer.timeout):
 			filtered = append(filtered, consumer)
-			e.log.Warn("timeout sending event", zap.Any("event", event.Payload))
+			e.log.Warn("timeout sending event", eventFields(event)...)
 		case <-consumer.ctx.Done():
 			// consumer context finished, filtering it out of loop
 			close(consumer.ch)
@@ -159,7 +169,8 @@ func (e *ChannelEventer) Subscribe(ctx context.Context) <-chan *EventWrapper {
 // Emit emits event to all subscribers
 func (e *ChannelEventer) Emit(event *EventWrapper) (*EventWrapper, error) {
 	if event.Callback == nil {
-		event.Callback = make(chan *EventWrapper)
+		// buffered so late consumers never block on abandoned callbacks
+		event.Callback = make(chan *EventWrapper, 1)
 	}
 	callbackTimout := time.NewTimer(DefaultCallabackTimeout)
 
@@ -167,18 +178,43 @@ func (e *ChannelEventer) Emit(event *EventWrapper) (*EventWrapper, error) {
 	case <-e.ctx.Done():
 		return nil, e.ctx.Err()
 	case <-callbackTimout.C:
-		return nil, fmt.Errorf("callback timeout")
+		return nil, ErrCallbackTimeout
 	case e.events <- event:
-		e.log.Warn("emitting event", zap.Any("event", event.Payload))
+		e.log.Debug("emitting event", eventFields(event)...)
 	}
 
 	select {
 	case <-e.ctx.Done():
 		return nil, e.ctx.Err()
 	case <-callbackTimout.C:
-		return nil, fmt.Errorf("callback timeout")
+		return nil, ErrCallbackTimeout
 	case v := <-event.Callback:
+		if v.Error != nil {
+			return v, v.Error
+		}
 		return v, nil
 	}
 
 }
+
+// Publish broadcasts event to all subscribers without waiting for callback.
+// Used for notifications.
+func (e *ChannelEventer) Publish(event *EventWrapper) error {
+	select {
+	case <-e.ctx.Done():
+		return e.ctx.Err()
+	case <-time.After(DefaultSendEventTimeout):
+		return ErrPublishTimeout
+	case e.events <- event:
+		return nil
+	}
+}
+
+// eventFields describe event in logs. Payload is not logged, requests carry cookies, storage and page content
+func eventFields(event *EventWrapper) []zap.Field {
+	return []zap.Field{
+		zap.String("action", event.Payload.Request.Action.String()),
+		zap.Int("display", event.Payload.Request.TargetDisplay()),
+		zap.String("requestId", event.Payload.Caller.RequestID),
+	}
+}
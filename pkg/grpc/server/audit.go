@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/auth"
+)
+
+// recordDenied writes call rejected by authentication or authorization to audit log. Reads are not
+// recorded, as they do not change kiosk, and denied calls of single caller are recorded once per interval
+func (s *Server) recordDenied(ctx context.Context, method string, err error) {
+	if requiredRole(method) == auth.RoleViewer {
+		return
+	}
+	caller := callerFromContext(ctx)
+	if !s.denied.Allow(caller.Address+"/"+caller.Identity, time.Now()) {
+		return
+	}
+	s.record(ctx, api.AuditEntry{
+		Action: method,
+		Result: api.AuditResultDenied,
+	}, err)
+}
+
+// recordFailed writes kiosk request which failed before kiosk handled it to audit log. Requests
+// handled by kiosk are recorded by kiosk itself
+func (s *Server) recordFailed(ctx context.Context, payload api.KioskRequest, err error) {
+	// reads do not change kiosk
+	if !payload.Action.Mutating() {
+		return
+	}
+	s.record(ctx, api.AuditEntry{
+		Display: payload.TargetDisplay(),
+		Action:  payload.Action.String(),
+		Result:  api.AuditResultError,
+	}, err)
+}
+
+func (s *Server) record(ctx context.Context, entry api.AuditEntry, err error) {
+	caller := callerFromContext(ctx)
+	entry.Identity = caller.Identity
+	entry.Address = caller.Address
+	entry.RequestID = caller.RequestID
+	entry.Error = err.Error()
+
+	err = s.audit.Record(entry)
+	if err != nil {
+		s.log.Error("failed to record audit entry", zap.String("action", entry.Action), zap.Error(err))
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/unikiosk/unikiosk/pkg/auth"
+)
+
+// methodRoles defines role required to call each rpc. Unknown methods require admin
+var methodRoles = map[string]auth.Role{
+	"/service.KioskService/GetState":    auth.RoleViewer,
+	"/service.KioskService/GetDisplays": auth.RoleViewer,
+	"/service.KioskService/Screenshot":  auth.RoleViewer,
+	"/service.KioskService/WatchEvents": auth.RoleViewer,
+	"/service.KioskService/SetContent":  auth.RoleOperator,
+	"/service.KioskService/Power":       auth.RoleOperator,
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize authenticates caller using bearer token from "authorization" metadata
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	if !s.auth.Enabled() {
+		return ctx, nil
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if strings.HasPrefix(v, "Bearer ") {
+				token = strings.TrimSpace(strings.TrimPrefix(v, "Bearer "))
+			}
+		}
+	}
+
+	id, err := s.auth.Authenticate(token)
+	if err != nil {
+		s.recordDenied(ctx, method, err)
+		return nil, status.Error(codes.Unauthenticated, "valid api token is required")
+	}
+
+	required := requiredRole(method)
+	if !id.Role.Allows(required) {
+		err := fmt.Errorf("role %s is not allowed to call %s", id.Role, method)
+		s.log.Warn("request forbidden", zap.String("identity", id.Name), zap.String("method", method))
+		s.recordDenied(auth.WithIdentity(ctx, id), method, err)
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return auth.WithIdentity(ctx, id), nil
+}
+
+// requiredRole returns role required to call method
+func requiredRole(method string) auth.Role {
+	required, ok := methodRoles[method]
+	if !ok {
+		return auth.RoleAdmin
+	}
+	return required
+}
+
+// authenticatedStream overrides stream context with caller identity
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
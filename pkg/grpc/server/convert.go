@@ -0,0 +1,137 @@
+package server
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/grpc/models"
+)
+
+func responseToModel(r api.KioskResponse) *models.KioskState {
+	state := &models.KioskState{
+		Display:       int32(r.Display),
+		Content:       r.Content,
+		Title:         r.Title,
+		Width:         int32(r.SizeW),
+		Height:        int32(r.SizeH),
+		PowerState:    powerStateToModel(r.PowerState),
+		Mode:          string(r.KioskMode),
+		X:             int32(r.PosX),
+		Y:             int32(r.PosY),
+		Fullscreen:    r.Fullscreen,
+		Geometry:      geometryToModel(r.Geometry),
+		Restarts:      int32(r.Restarts),
+		RestartReason: r.RestartReason,
+		LastLoad:      loadToModel(r.LastLoad),
+		Allowlist:     r.Allowlist,
+
+		BlockedNavigations: int32(r.BlockedNavigations),
+		LastBlocked:        r.LastBlocked,
+		DesiredPowerState:  powerStateToModel(r.DesiredPowerState),
+	}
+	if !r.LastRestart.IsZero() {
+		state.LastRestart = timestamppb.New(r.LastRestart)
+	}
+	if !r.LastBlockedTime.IsZero() {
+		state.LastBlockedTime = timestamppb.New(r.LastBlockedTime)
+	}
+	return state
+}
+
+func displayToModel(d api.Display) *models.Display {
+	return &models.Display{
+		Id:     int32(d.ID),
+		Name:   d.Name,
+		X:      int32(d.X),
+		Y:      int32(d.Y),
+		Width:  int32(d.Width),
+		Height: int32(d.Height),
+	}
+}
+
+func loadToModel(l *api.LoadResult) *models.LoadResult {
+	if l == nil {
+		return nil
+	}
+	return &models.LoadResult{
+		Url:        l.URL,
+		Time:       timestamppb.New(l.Time),
+		Status:     int32(l.Status),
+		DurationMs: l.Duration.Milliseconds(),
+		Error:      l.Error,
+		Fallback:   l.Fallback,
+		Retries:    int32(l.Retries),
+	}
+}
+
+func geometryToModel(g *api.WindowGeometry) *models.WindowGeometry {
+	if g == nil {
+		return nil
+	}
+	return &models.WindowGeometry{
+		X:          int32(g.X),
+		Y:          int32(g.Y),
+		Width:      int32(g.Width),
+		Height:     int32(g.Height),
+		Fullscreen: g.Fullscreen,
+	}
+}
+
+func powerStateToModel(p api.PowerState) models.PowerState {
+	switch p {
+	case api.PowerStateOn:
+		return models.PowerState_ON
+	case api.PowerStateOff:
+		return models.PowerState_OFF
+	default:
+		return models.PowerState_UNKNOWN
+	}
+}
+
+func eventToModel(e api.Event) *models.Event {
+	var t models.EventType
+	switch e.Type {
+	case api.EventTypeContentChanged:
+		t = models.EventType_CONTENT_CHANGED
+	case api.EventTypePowerChanged:
+		t = models.EventType_POWER_CHANGED
+	case api.EventTypeScreenshotTaken:
+		t = models.EventType_SCREENSHOT_TAKEN
+	case api.EventTypeBrowserRestarted:
+		t = models.EventType_BROWSER_RESTARTED
+	case api.EventTypeBrowserException:
+		t = models.EventType_BROWSER_EXCEPTION
+	case api.EventTypeLoadFailed:
+		t = models.EventType_LOAD_FAILED
+	case api.EventTypeLoadRecovered:
+		t = models.EventType_LOAD_RECOVERED
+	case api.EventTypeNavigationBlocked:
+		t = models.EventType_NAVIGATION_BLOCKED
+	case api.EventTypeIdleTimeout:
+		t = models.EventType_IDLE_TIMEOUT
+	}
+
+	return &models.Event{
+		Type:    t,
+		Time:    timestamppb.New(time.Now()),
+		State:   responseToModel(e.Response),
+		Message: consoleToModel(e.Console),
+	}
+}
+
+func consoleToModel(m *api.ConsoleMessage) *models.ConsoleMessage {
+	if m == nil {
+		return nil
+	}
+	return &models.ConsoleMessage{
+		Time:   timestamppb.New(m.Time),
+		Level:  m.Level,
+		Source: m.Source,
+		Text:   m.Text,
+		Url:    m.URL,
+		Line:   int32(m.Line),
+		Column: int32(m.Column),
+	}
+}
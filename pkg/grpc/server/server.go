@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/auth"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/grpc/models"
+	"github.com/unikiosk/unikiosk/pkg/grpc/service"
+	"github.com/unikiosk/unikiosk/pkg/store"
+)
+
+var _ Interface = &Server{}
+var _ service.KioskServiceServer = &Server{}
+
+type Interface interface {
+	Run(ctx context.Context) error
+}
+
+// Server implements gRPC control api. It is backed by the same eventer path as web api
+type Server struct {
+	log    *zap.Logger
+	config *config.Config
+	events eventer.Eventer
+	store  store.Store
+	auth   *auth.Authenticator
+	audit  audit.Log
+	// denied limits audit entries of denied calls per caller
+	denied *audit.Limiter
+	// displays are displays kiosk shows content on
+	displays []api.Display
+
+	server *grpc.Server
+}
+
+func New(
+	log *zap.Logger,
+	config *config.Config,
+	events eventer.Eventer,
+	store store.Store,
+	authenticator *auth.Authenticator,
+	auditLog audit.Log,
+	displays []api.Display,
+) (*Server, error) {
+	s := &Server{
+		log:      log,
+		config:   config,
+		events:   events,
+		store:    store,
+		auth:     authenticator,
+		audit:    auditLog,
+		denied:   audit.NewLimiter(audit.DeniedInterval),
+		displays: displays,
+	}
+	s.server = grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+
+	service.RegisterKioskServiceServer(s.server, s)
+
+	return s, nil
+}
+
+func (s *Server) Run(ctx context.Context) error {
+	s.log.Info("Starting gRPC Service")
+
+	listener, err := net.Listen("tcp", s.config.GRPCServerAddr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.server.GracefulStop()
+	}()
+
+	s.log.Info("Server will now listen", zap.String("url", s.config.GRPCServerAddr))
+	return s.server.Serve(listener)
+}
+
+func (s *Server) SetContent(ctx context.Context, in *service.SetContentRequest) (*models.KioskState, error) {
+	if in.Content == "" && in.Title == "" && in.Width == 0 && in.Height == 0 {
+		return nil, status.Error(codes.InvalidArgument, "one of content, title or size is required")
+	}
+	if in.Width < 0 || in.Height < 0 {
+		return nil, status.Error(codes.InvalidArgument, "width and height must be positive")
+	}
+
+	result, err := s.update(ctx, api.KioskRequest{
+		Action:  api.ScreenActionUpdate,
+		Display: int(in.Display),
+		Content: in.Content,
+		Title:   in.Title,
+		SizeW:   int(in.Width),
+		SizeH:   int(in.Height),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return responseToModel(result), nil
+}
+
+func (s *Server) GetState(ctx context.Context, in *service.GetStateRequest) (*models.KioskState, error) {
+	display := int(in.Display)
+	if !s.hasDisplay(display) {
+		return nil, toStatus(fmt.Errorf("display %d: %w", display, os.ErrNotExist))
+	}
+	state, err := s.store.Get(store.StateKey(display))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return responseToModel(api.StateToResponse(state)), nil
+}
+
+func (s *Server) Power(ctx context.Context, in *service.PowerRequest) (*models.KioskState, error) {
+	var action api.ScreenAction
+	switch in.State {
+	case models.PowerState_ON:
+		action = api.ScreenActionPowerOn
+	case models.PowerState_OFF:
+		action = api.ScreenActionPowerOff
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid power state %s", in.State)
+	}
+
+	result, err := s.update(ctx, api.KioskRequest{Action: action, Display: int(in.Display)})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return responseToModel(result), nil
+}
+
+func (s *Server) Screenshot(ctx context.Context, in *service.ScreenshotRequest) (*service.ScreenshotResponse, error) {
+	opts := &api.ScreenshotOptions{
+		Format:  strings.ToLower(in.Format),
+		Quality: int(in.Quality),
+		Width:   int(in.Width),
+		Display: int(in.Display),
+		Stitch:  in.Stitch,
+	}
+	switch opts.Format {
+	case "":
+		opts.Format = api.ScreenshotFormatPNG
+	case api.ScreenshotFormatPNG, api.ScreenshotFormatJPEG:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid format %q, expected one of: png, jpeg", in.Format)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 || opts.Width < 0 || opts.Display < 0 {
+		return nil, status.Error(codes.InvalidArgument, "quality, width and display must be positive")
+	}
+
+	result, err := s.update(ctx, api.KioskRequest{
+		Action:            api.ScreenActionScreenShot,
+		ScreenshotOptions: opts,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &service.ScreenshotResponse{
+		Image:       result.Screenshot,
+		ContentType: opts.ContentType(),
+	}, nil
+}
+
+func (s *Server) WatchEvents(in *service.WatchEventsRequest, stream service.KioskService_WatchEventsServer) error {
+	ctx := stream.Context()
+	listener := s.events.Subscribe(ctx)
+
+	// send headers right away so client knows subscription is established
+	err := stream.SendHeader(metadata.MD{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-listener:
+			if !ok {
+				return nil
+			}
+			if event.Payload.Type == api.EventTypeRequest {
+				continue
+			}
+			err := stream.Send(eventToModel(event.Payload))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) GetDisplays(ctx context.Context, in *service.GetDisplaysRequest) (*service.GetDisplaysResponse, error) {
+	displays := make([]*models.Display, 0, len(s.displays))
+	for _, d := range s.displays {
+		displays = append(displays, displayToModel(d))
+	}
+	return &service.GetDisplaysResponse{Displays: displays}, nil
+}
+
+func (s *Server) update(ctx context.Context, payload api.KioskRequest) (api.KioskResponse, error) {
+	// requests of unknown display would not be handled by any kiosk
+	if display := payload.TargetDisplay(); !s.hasDisplay(display) {
+		err := fmt.Errorf("display %d: %w", display, os.ErrNotExist)
+		s.recordFailed(ctx, payload, err)
+		return api.KioskResponse{}, err
+	}
+	result, err := s.events.Emit(&eventer.EventWrapper{
+		Payload: api.Event{
+			Request: payload,
+			Caller:  callerFromContext(ctx),
+		},
+	})
+	if err != nil {
+		// without result kiosk did not respond in time, so request is not recorded by kiosk
+		if result == nil {
+			s.recordFailed(ctx, payload, err)
+		}
+		return api.KioskResponse{}, err
+	}
+	return result.Payload.Response, nil
+}
+
+func (s *Server) hasDisplay(display int) bool {
+	for _, d := range s.displays {
+		if d.ID == display {
+			return true
+		}
+	}
+	return false
+}
+
+// callerFromContext describes call origin for audit log
+func callerFromContext(ctx context.Context) api.Caller {
+	caller := api.Caller{}
+	if p, ok := peer.FromContext(ctx); ok {
+		caller.Address = p.Addr.String()
+		if host, _, err := net.SplitHostPort(caller.Address); err == nil {
+			caller.Address = host
+		}
+	}
+	if id := auth.IdentityFromContext(ctx); id != nil {
+		caller.Identity = id.Name
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get("x-request-id"); len(ids) > 0 {
+			caller.RequestID = ids[0]
+		}
+	}
+	return caller
+}
+
+// toStatus maps internal errors to gRPC status
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, os.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, eventer.ErrCallbackTimeout), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/auth"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/grpc/models"
+	"github.com/unikiosk/unikiosk/pkg/grpc/service"
+	"github.com/unikiosk/unikiosk/pkg/store"
+	"github.com/unikiosk/unikiosk/pkg/store/memory"
+	"github.com/unikiosk/unikiosk/pkg/util/logger"
+)
+
+func newTestClient(t *testing.T) (service.KioskServiceClient, *eventer.ChannelEventer) {
+	client, events, _ := newTestClientWithConfig(t, &config.Config{})
+	return client, events
+}
+
+func newTestClientWithConfig(t *testing.T, c *config.Config) (service.KioskServiceClient, *eventer.ChannelEventer, *Server) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := logger.GetLoggerInstance("", zap.DebugLevel)
+	events := eventer.New(ctx, log)
+	s := memory.New()
+	require.NoError(t, s.Persist(store.KioskStateKey, api.KioskState{Content: "https://synpse.net", SizeW: 1920, SizeH: 1080}))
+	require.NoError(t, s.Persist(store.StateKey(1), api.KioskState{Display: 1, Content: "https://unikiosk.io", SizeW: 1280, SizeH: 1024}))
+
+	c.StateDir = t.TempDir()
+	authenticator, err := auth.New(log, c)
+	require.NoError(t, err)
+
+	auditLog, err := audit.New(log, c)
+	require.NoError(t, err)
+
+	displays := []api.Display{
+		{ID: 0, Name: "HDMI-1", Width: 1920, Height: 1080},
+		{ID: 1, Name: "HDMI-2", X: 1920, Width: 1280, Height: 1024},
+	}
+	srv, err := New(log, c, events, s, authenticator, auditLog, displays)
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go srv.server.Serve(listener)
+	t.Cleanup(srv.server.Stop)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return service.NewKioskServiceClient(conn), events, srv
+}
+
+func TestServer_GetState(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	client, _ := newTestClient(t)
+
+	state, err := client.GetState(context.Background(), &service.GetStateRequest{})
+	require.NoError(err)
+	require.Equal("https://synpse.net", state.Content)
+	require.Equal(int32(1920), state.Width)
+	require.Equal(models.PowerState_ON, state.PowerState)
+}
+
+func TestServer_displays(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	client, _ := newTestClient(t)
+
+	displays, err := client.GetDisplays(context.Background(), &service.GetDisplaysRequest{})
+	require.NoError(err)
+	require.Len(displays.Displays, 2)
+	require.Equal("HDMI-2", displays.Displays[1].Name)
+
+	state, err := client.GetState(context.Background(), &service.GetStateRequest{Display: 1})
+	require.NoError(err)
+	require.Equal(int32(1), state.Display)
+	require.Equal("https://unikiosk.io", state.Content)
+
+	_, err = client.GetState(context.Background(), &service.GetStateRequest{Display: 2})
+	require.Equal(codes.NotFound, status.Code(err))
+
+	// requests of unknown display are not sent to kiosks
+	_, err = client.Power(context.Background(), &service.PowerRequest{State: models.PowerState_OFF, Display: 2})
+	require.Equal(codes.NotFound, status.Code(err))
+}
+
+func TestServer_Power(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, events := newTestClient(t)
+
+	listener := events.Subscribe(ctx)
+	go func() {
+		for event := range listener {
+			require.Equal(api.ScreenActionPowerOff, event.Payload.Request.Action)
+			event.Callback <- &eventer.EventWrapper{
+				Payload: api.Event{Response: api.KioskResponse{PowerState: api.PowerStateOff}},
+			}
+		}
+	}()
+
+	state, err := client.Power(ctx, &service.PowerRequest{State: models.PowerState_OFF})
+	require.NoError(err)
+	require.Equal(models.PowerState_OFF, state.PowerState)
+
+	_, err = client.Power(ctx, &service.PowerRequest{State: models.PowerState_UNKNOWN})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_WatchEvents(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, events := newTestClient(t)
+
+	stream, err := client.WatchEvents(ctx, &service.WatchEventsRequest{})
+	require.NoError(err)
+	// wait for the stream to be established so subscription exists
+	_, err = stream.Header()
+	require.NoError(err)
+
+	require.NoError(events.Publish(&eventer.EventWrapper{
+		Payload: api.Event{
+			Type:     api.EventTypePowerChanged,
+			Response: api.KioskResponse{PowerState: api.PowerStateOff},
+		},
+	}))
+
+	event, err := stream.Recv()
+	require.NoError(err)
+	require.Equal(models.EventType_POWER_CHANGED, event.Type)
+	require.Equal(models.PowerState_OFF, event.State.PowerState)
+}
+
+func TestServer_auth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	client, _, _ := newTestClientWithConfig(t, &config.Config{
+		APITokens: map[string]string{"viewer-secret": "viewer"},
+	})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer viewer-secret")
+
+	_, err := client.GetDisplays(context.Background(), &service.GetDisplaysRequest{})
+	require.Equal(codes.Unauthenticated, status.Code(err))
+
+	// viewer can read state and displays, but can't control kiosk
+	_, err = client.GetState(ctx, &service.GetStateRequest{})
+	require.NoError(err)
+	resp, err := client.GetDisplays(ctx, &service.GetDisplaysRequest{})
+	require.NoError(err)
+	require.Len(resp.Displays, 2)
+	_, err = client.Power(ctx, &service.PowerRequest{State: models.PowerState_OFF})
+	require.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_audit(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	client, _, srv := newTestClientWithConfig(t, &config.Config{
+		APITokens: map[string]string{
+			"viewer-secret":   "viewer",
+			"operator-secret": "operator",
+		},
+	})
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	// rejected calls and requests of unknown display never reach kiosk, so they are recorded by server
+	_, err := client.Power(context.Background(), &service.PowerRequest{State: models.PowerState_OFF})
+	require.Equal(codes.Unauthenticated, status.Code(err))
+	_, err = client.Power(withToken("viewer-secret"), &service.PowerRequest{State: models.PowerState_OFF})
+	require.Equal(codes.PermissionDenied, status.Code(err))
+	_, err = client.Power(withToken("operator-secret"), &service.PowerRequest{State: models.PowerState_OFF, Display: 2})
+	require.Equal(codes.NotFound, status.Code(err))
+
+	entries, err := srv.audit.Query(time.Time{}, time.Time{}, 10)
+	require.NoError(err)
+	require.Len(entries, 3)
+	require.Equal(api.ScreenActionPowerOff.String(), entries[0].Action)
+	require.Equal(2, entries[0].Display)
+	require.Equal(api.AuditResultError, entries[0].Result)
+	require.Contains(entries[0].Identity, "static-")
+	require.Equal("/service.KioskService/Power", entries[1].Action)
+	require.Equal(api.AuditResultDenied, entries[1].Result)
+	require.Contains(entries[1].Identity, "static-")
+	require.Equal(api.AuditResultDenied, entries[2].Result)
+	require.Empty(entries[2].Identity)
+}
+
+func TestServer_toStatus(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	for err, code := range map[error]codes.Code{
+		fmt.Errorf("display 2: %w", os.ErrNotExist):         codes.NotFound,
+		fmt.Errorf("output is required: %w", os.ErrInvalid): codes.InvalidArgument,
+		eventer.ErrCallbackTimeout:                          codes.DeadlineExceeded,
+		fmt.Errorf("eval: %w", context.DeadlineExceeded):    codes.DeadlineExceeded,
+		context.Canceled:                     codes.Unavailable,
+		errors.New("browser is not running"): codes.Internal,
+	} {
+		require.Equal(code, status.Code(toStatus(err)), err.Error())
+	}
+}
@@ -0,0 +1,1024 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0-devel
+// 	protoc        (unknown)
+// source: pkg/grpc/proto/service/kiosk.proto
+
+package service
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	models "github.com/unikiosk/unikiosk/pkg/grpc/models"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type SetContentRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Content string `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Title   string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Width   int32  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height  int32  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	// display is id of display to change, requests without it change the first display
+	Display int32 `protobuf:"varint,5,opt,name=display,proto3" json:"display,omitempty"`
+}
+
+func (x *SetContentRequest) Reset() {
+	*x = SetContentRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetContentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetContentRequest) ProtoMessage() {}
+
+func (x *SetContentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetContentRequest.ProtoReflect.Descriptor instead.
+func (*SetContentRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SetContentRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SetContentRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SetContentRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *SetContentRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *SetContentRequest) GetDisplay() int32 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+type GetStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Display int32 `protobuf:"varint,1,opt,name=display,proto3" json:"display,omitempty"`
+}
+
+func (x *GetStateRequest) Reset() {
+	*x = GetStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateRequest) ProtoMessage() {}
+
+func (x *GetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateRequest.ProtoReflect.Descriptor instead.
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetStateRequest) GetDisplay() int32 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+type PowerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State   models.PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=models.PowerState" json:"state,omitempty"`
+	Display int32             `protobuf:"varint,2,opt,name=display,proto3" json:"display,omitempty"`
+}
+
+func (x *PowerRequest) Reset() {
+	*x = PowerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PowerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PowerRequest) ProtoMessage() {}
+
+func (x *PowerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PowerRequest.ProtoReflect.Descriptor instead.
+func (*PowerRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PowerRequest) GetState() models.PowerState {
+	if x != nil {
+		return x.State
+	}
+	return models.PowerState_ON
+}
+
+func (x *PowerRequest) GetDisplay() int32 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+type ScreenshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// format is one of: png, jpeg. Defaults to png
+	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// quality is jpeg quality 1-100
+	Quality int32 `protobuf:"varint,2,opt,name=quality,proto3" json:"quality,omitempty"`
+	// width scales image down to given width keeping aspect ratio
+	Width int32 `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	// display is id of display to capture
+	Display int32 `protobuf:"varint,4,opt,name=display,proto3" json:"display,omitempty"`
+	// stitch captures all displays into one image
+	Stitch bool `protobuf:"varint,5,opt,name=stitch,proto3" json:"stitch,omitempty"`
+}
+
+func (x *ScreenshotRequest) Reset() {
+	*x = ScreenshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScreenshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotRequest) ProtoMessage() {}
+
+func (x *ScreenshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotRequest.ProtoReflect.Descriptor instead.
+func (*ScreenshotRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScreenshotRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ScreenshotRequest) GetQuality() int32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *ScreenshotRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ScreenshotRequest) GetDisplay() int32 {
+	if x != nil {
+		return x.Display
+	}
+	return 0
+}
+
+func (x *ScreenshotRequest) GetStitch() bool {
+	if x != nil {
+		return x.Stitch
+	}
+	return false
+}
+
+type ScreenshotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Image       []byte `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+}
+
+func (x *ScreenshotResponse) Reset() {
+	*x = ScreenshotResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScreenshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotResponse) ProtoMessage() {}
+
+func (x *ScreenshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotResponse.ProtoReflect.Descriptor instead.
+func (*ScreenshotResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScreenshotResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *ScreenshotResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{5}
+}
+
+type GetDisplaysRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetDisplaysRequest) Reset() {
+	*x = GetDisplaysRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDisplaysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDisplaysRequest) ProtoMessage() {}
+
+func (x *GetDisplaysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDisplaysRequest.ProtoReflect.Descriptor instead.
+func (*GetDisplaysRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{6}
+}
+
+type GetDisplaysResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Displays []*models.Display `protobuf:"bytes,1,rep,name=displays,proto3" json:"displays,omitempty"`
+}
+
+func (x *GetDisplaysResponse) Reset() {
+	*x = GetDisplaysResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDisplaysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDisplaysResponse) ProtoMessage() {}
+
+func (x *GetDisplaysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_grpc_proto_service_kiosk_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDisplaysResponse.ProtoReflect.Descriptor instead.
+func (*GetDisplaysResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetDisplaysResponse) GetDisplays() []*models.Display {
+	if x != nil {
+		return x.Displays
+	}
+	return nil
+}
+
+var File_pkg_grpc_proto_service_kiosk_proto protoreflect.FileDescriptor
+
+var file_pkg_grpc_proto_service_kiosk_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x6b, 0x69, 0x6f, 0x73, 0x6b, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x1a, 0x21, 0x70,
+	0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x73, 0x2f, 0x6b, 0x69, 0x6f, 0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x8b, 0x01, 0x0a, 0x11, 0x53, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x22, 0x2b,
+	0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x22, 0x52, 0x0a, 0x0c, 0x50,
+	0x6f, 0x77, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x73, 0x2e, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x22,
+	0x8d, 0x01, 0x0a, 0x11, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x18, 0x0a,
+	0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x69, 0x74, 0x63,
+	0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x69, 0x74, 0x63, 0x68, 0x22,
+	0x4d, 0x0a, 0x12, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x22, 0x14,
+	0x0a, 0x12, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x14, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x44, 0x69, 0x73, 0x70, 0x6c,
+	0x61, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x42, 0x0a, 0x13, 0x47, 0x65,
+	0x74, 0x44, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x2b, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2e, 0x44, 0x69, 0x73,
+	0x70, 0x6c, 0x61, 0x79, 0x52, 0x08, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x73, 0x32, 0x88,
+	0x03, 0x0a, 0x0c, 0x4b, 0x69, 0x6f, 0x73, 0x6b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x3c, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x6f, 0x64, 0x65,
+	0x6c, 0x73, 0x2e, 0x4b, 0x69, 0x6f, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x38, 0x0a,
+	0x08, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x18, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2e, 0x4b, 0x69, 0x6f,
+	0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x32, 0x0a, 0x05, 0x50, 0x6f, 0x77, 0x65, 0x72,
+	0x12, 0x15, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x77, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73,
+	0x2e, 0x4b, 0x69, 0x6f, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x45, 0x0a, 0x0a, 0x53,
+	0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1a, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x12, 0x1b, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d,
+	0x2e, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12,
+	0x48, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x44, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x73, 0x12, 0x1b,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x69, 0x73, 0x70,
+	0x6c, 0x61, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2f, 0x5a, 0x2d, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x75, 0x6e, 0x69, 0x6b, 0x69, 0x6f, 0x73, 0x6b,
+	0x2f, 0x75, 0x6e, 0x69, 0x6b, 0x69, 0x6f, 0x73, 0x6b, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72,
+	0x70, 0x63, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_pkg_grpc_proto_service_kiosk_proto_rawDescOnce sync.Once
+	file_pkg_grpc_proto_service_kiosk_proto_rawDescData = file_pkg_grpc_proto_service_kiosk_proto_rawDesc
+)
+
+func file_pkg_grpc_proto_service_kiosk_proto_rawDescGZIP() []byte {
+	file_pkg_grpc_proto_service_kiosk_proto_rawDescOnce.Do(func() {
+		file_pkg_grpc_proto_service_kiosk_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_grpc_proto_service_kiosk_proto_rawDescData)
+	})
+	return file_pkg_grpc_proto_service_kiosk_proto_rawDescData
+}
+
+var file_pkg_grpc_proto_service_kiosk_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pkg_grpc_proto_service_kiosk_proto_goTypes = []interface{}{
+	(*SetContentRequest)(nil),   // 0: service.SetContentRequest
+	(*GetStateRequest)(nil),     // 1: service.GetStateRequest
+	(*PowerRequest)(nil),        // 2: service.PowerRequest
+	(*ScreenshotRequest)(nil),   // 3: service.ScreenshotRequest
+	(*ScreenshotResponse)(nil),  // 4: service.ScreenshotResponse
+	(*WatchEventsRequest)(nil),  // 5: service.WatchEventsRequest
+	(*GetDisplaysRequest)(nil),  // 6: service.GetDisplaysRequest
+	(*GetDisplaysResponse)(nil), // 7: service.GetDisplaysResponse
+	(models.PowerState)(0),      // 8: models.PowerState
+	(*models.Display)(nil),      // 9: models.Display
+	(*models.KioskState)(nil),   // 10: models.KioskState
+	(*models.Event)(nil),        // 11: models.Event
+}
+var file_pkg_grpc_proto_service_kiosk_proto_depIdxs = []int32{
+	8,  // 0: service.PowerRequest.state:type_name -> models.PowerState
+	9,  // 1: service.GetDisplaysResponse.displays:type_name -> models.Display
+	0,  // 2: service.KioskService.SetContent:input_type -> service.SetContentRequest
+	1,  // 3: service.KioskService.GetState:input_type -> service.GetStateRequest
+	2,  // 4: service.KioskService.Power:input_type -> service.PowerRequest
+	3,  // 5: service.KioskService.Screenshot:input_type -> service.ScreenshotRequest
+	5,  // 6: service.KioskService.WatchEvents:input_type -> service.WatchEventsRequest
+	6,  // 7: service.KioskService.GetDisplays:input_type -> service.GetDisplaysRequest
+	10, // 8: service.KioskService.SetContent:output_type -> models.KioskState
+	10, // 9: service.KioskService.GetState:output_type -> models.KioskState
+	10, // 10: service.KioskService.Power:output_type -> models.KioskState
+	4,  // 11: service.KioskService.Screenshot:output_type -> service.ScreenshotResponse
+	11, // 12: service.KioskService.WatchEvents:output_type -> models.Event
+	7,  // 13: service.KioskService.GetDisplays:output_type -> service.GetDisplaysResponse
+	8,  // [8:14] is the sub-list for method output_type
+	2,  // [2:8] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_pkg_grpc_proto_service_kiosk_proto_init() }
+func file_pkg_grpc_proto_service_kiosk_proto_init() {
+	if File_pkg_grpc_proto_service_kiosk_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetContentRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PowerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScreenshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScreenshotResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDisplaysRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_grpc_proto_service_kiosk_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDisplaysResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_grpc_proto_service_kiosk_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_grpc_proto_service_kiosk_proto_goTypes,
+		DependencyIndexes: file_pkg_grpc_proto_service_kiosk_proto_depIdxs,
+		MessageInfos:      file_pkg_grpc_proto_service_kiosk_proto_msgTypes,
+	}.Build()
+	File_pkg_grpc_proto_service_kiosk_proto = out.File
+	file_pkg_grpc_proto_service_kiosk_proto_rawDesc = nil
+	file_pkg_grpc_proto_service_kiosk_proto_goTypes = nil
+	file_pkg_grpc_proto_service_kiosk_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// KioskServiceClient is the client API for KioskService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type KioskServiceClient interface {
+	// SetContent changes content displayed by the kiosk. Zero width and height keep current window size
+	SetContent(ctx context.Context, in *SetContentRequest, opts ...grpc.CallOption) (*models.KioskState, error)
+	// GetState returns current kiosk state
+	GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*models.KioskState, error)
+	// Power turns screen on or off
+	Power(ctx context.Context, in *PowerRequest, opts ...grpc.CallOption) (*models.KioskState, error)
+	// Screenshot captures current screen
+	Screenshot(ctx context.Context, in *ScreenshotRequest, opts ...grpc.CallOption) (*ScreenshotResponse, error)
+	// WatchEvents streams kiosk notifications
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (KioskService_WatchEventsClient, error)
+	// GetDisplays lists displays kiosk shows content on
+	GetDisplays(ctx context.Context, in *GetDisplaysRequest, opts ...grpc.CallOption) (*GetDisplaysResponse, error)
+}
+
+type kioskServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKioskServiceClient(cc grpc.ClientConnInterface) KioskServiceClient {
+	return &kioskServiceClient{cc}
+}
+
+func (c *kioskServiceClient) SetContent(ctx context.Context, in *SetContentRequest, opts ...grpc.CallOption) (*models.KioskState, error) {
+	out := new(models.KioskState)
+	err := c.cc.Invoke(ctx, "/service.KioskService/SetContent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioskServiceClient) GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*models.KioskState, error) {
+	out := new(models.KioskState)
+	err := c.cc.Invoke(ctx, "/service.KioskService/GetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioskServiceClient) Power(ctx context.Context, in *PowerRequest, opts ...grpc.CallOption) (*models.KioskState, error) {
+	out := new(models.KioskState)
+	err := c.cc.Invoke(ctx, "/service.KioskService/Power", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioskServiceClient) Screenshot(ctx context.Context, in *ScreenshotRequest, opts ...grpc.CallOption) (*ScreenshotResponse, error) {
+	out := new(ScreenshotResponse)
+	err := c.cc.Invoke(ctx, "/service.KioskService/Screenshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioskServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (KioskService_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_KioskService_serviceDesc.Streams[0], "/service.KioskService/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kioskServiceWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KioskService_WatchEventsClient interface {
+	Recv() (*models.Event, error)
+	grpc.ClientStream
+}
+
+type kioskServiceWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *kioskServiceWatchEventsClient) Recv() (*models.Event, error) {
+	m := new(models.Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *kioskServiceClient) GetDisplays(ctx context.Context, in *GetDisplaysRequest, opts ...grpc.CallOption) (*GetDisplaysResponse, error) {
+	out := new(GetDisplaysResponse)
+	err := c.cc.Invoke(ctx, "/service.KioskService/GetDisplays", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KioskServiceServer is the server API for KioskService service.
+type KioskServiceServer interface {
+	// SetContent changes content displayed by the kiosk. Zero width and height keep current window size
+	SetContent(context.Context, *SetContentRequest) (*models.KioskState, error)
+	// GetState returns current kiosk state
+	GetState(context.Context, *GetStateRequest) (*models.KioskState, error)
+	// Power turns screen on or off
+	Power(context.Context, *PowerRequest) (*models.KioskState, error)
+	// Screenshot captures current screen
+	Screenshot(context.Context, *ScreenshotRequest) (*ScreenshotResponse, error)
+	// WatchEvents streams kiosk notifications
+	WatchEvents(*WatchEventsRequest, KioskService_WatchEventsServer) error
+	// GetDisplays lists displays kiosk shows content on
+	GetDisplays(context.Context, *GetDisplaysRequest) (*GetDisplaysResponse, error)
+}
+
+// UnimplementedKioskServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedKioskServiceServer struct {
+}
+
+func (*UnimplementedKioskServiceServer) SetContent(context.Context, *SetContentRequest) (*models.KioskState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetContent not implemented")
+}
+func (*UnimplementedKioskServiceServer) GetState(context.Context, *GetStateRequest) (*models.KioskState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (*UnimplementedKioskServiceServer) Power(context.Context, *PowerRequest) (*models.KioskState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Power not implemented")
+}
+func (*UnimplementedKioskServiceServer) Screenshot(context.Context, *ScreenshotRequest) (*ScreenshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Screenshot not implemented")
+}
+func (*UnimplementedKioskServiceServer) WatchEvents(*WatchEventsRequest, KioskService_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (*UnimplementedKioskServiceServer) GetDisplays(context.Context, *GetDisplaysRequest) (*GetDisplaysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDisplays not implemented")
+}
+
+func RegisterKioskServiceServer(s *grpc.Server, srv KioskServiceServer) {
+	s.RegisterService(&_KioskService_serviceDesc, srv)
+}
+
+func _KioskService_SetContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioskServiceServer).SetContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.KioskService/SetContent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioskServiceServer).SetContent(ctx, req.(*SetContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KioskService_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioskServiceServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.KioskService/GetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioskServiceServer).GetState(ctx, req.(*GetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KioskService_Power_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioskServiceServer).Power(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.KioskService/Power",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioskServiceServer).Power(ctx, req.(*PowerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KioskService_Screenshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScreenshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioskServiceServer).Screenshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.KioskService/Screenshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioskServiceServer).Screenshot(ctx, req.(*ScreenshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KioskService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KioskServiceServer).WatchEvents(m, &kioskServiceWatchEventsServer{stream})
+}
+
+type KioskService_WatchEventsServer interface {
+	Send(*models.Event) error
+	grpc.ServerStream
+}
+
+type kioskServiceWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *kioskServiceWatchEventsServer) Send(m *models.Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _KioskService_GetDisplays_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDisplaysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioskServiceServer).GetDisplays(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.KioskService/GetDisplays",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioskServiceServer).GetDisplays(ctx, req.(*GetDisplaysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _KioskService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "service.KioskService",
+	HandlerType: (*KioskServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetContent",
+			Handler:    _KioskService_SetContent_Handler,
+		},
+		{
+			MethodName: "GetState",
+			Handler:    _KioskService_GetState_Handler,
+		},
+		{
+			MethodName: "Power",
+			Handler:    _KioskService_Power_Handler,
+		},
+		{
+			MethodName: "Screenshot",
+			Handler:    _KioskService_Screenshot_Handler,
+		},
+		{
+			MethodName: "GetDisplays",
+			Handler:    _KioskService_GetDisplays_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _KioskService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/grpc/proto/service/kiosk.proto",
+}
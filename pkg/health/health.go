@@ -0,0 +1,157 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// checkTimeout limits how long single component check can take
+var checkTimeout = 2 * time.Second
+
+// Status is health of a component or whole service
+type Status string
+
+var (
+	StatusOK      Status = "ok"
+	StatusFailing Status = "failing"
+)
+
+// Check returns nil error when component is healthy. Message describes component state and is
+// reported even when component is healthy
+type Check func(ctx context.Context) (string, error)
+
+// ErrorCheck adapts function which reports only error
+func ErrorCheck(fn func(ctx context.Context) error) Check {
+	return func(ctx context.Context) (string, error) {
+		return "", fn(ctx)
+	}
+}
+
+// ComponentStatus is result of single component check
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+	// Liveness is true when failing component makes service unhealthy, not only unready
+	Liveness bool `json:"liveness"`
+}
+
+// Report is result of all component checks
+type Report struct {
+	Status     Status            `json:"status"`
+	Time       time.Time         `json:"time"`
+	Components []ComponentStatus `json:"components"`
+}
+
+type check struct {
+	name     string
+	liveness bool
+	fn       Check
+}
+
+// Health aggregates component checks. Liveness checks decide if service is healthy,
+// all checks together decide if service is ready
+type Health struct {
+	mu     sync.RWMutex
+	checks []check
+}
+
+func New() *Health {
+	return &Health{}
+}
+
+// Register adds component check. Liveness checks are part of both health and readiness
+func (h *Health) Register(name string, liveness bool, fn Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, liveness: liveness, fn: fn})
+}
+
+// Live runs all checks. Report status is failing only if liveness check fails
+func (h *Health) Live(ctx context.Context) Report {
+	return h.report(ctx, true)
+}
+
+// Ready runs all checks. Report status is failing if any check fails
+func (h *Health) Ready(ctx context.Context) Report {
+	return h.report(ctx, false)
+}
+
+// Check runs named component check
+func (h *Health) Check(ctx context.Context, name string) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, c := range h.checks {
+		if c.name == name {
+			_, err := run(ctx, c.fn)
+			return err
+		}
+	}
+	return fmt.Errorf("unknown component %s", name)
+}
+
+func (h *Health) report(ctx context.Context, liveness bool) Report {
+	h.mu.RLock()
+	checks := make([]check, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	report := Report{
+		Status:     StatusOK,
+		Time:       time.Now().UTC(),
+		Components: make([]ComponentStatus, len(checks)),
+	}
+
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			status := ComponentStatus{
+				Name:     c.name,
+				Status:   StatusOK,
+				Liveness: c.liveness,
+			}
+			message, err := run(ctx, c.fn)
+			status.Message = message
+			if err != nil {
+				status.Status = StatusFailing
+				status.Message = err.Error()
+			}
+			report.Components[i] = status
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, c := range report.Components {
+		if c.Status != StatusOK && (c.Liveness || !liveness) {
+			report.Status = StatusFailing
+		}
+	}
+	return report
+}
+
+type result struct {
+	message string
+	err     error
+}
+
+func run(ctx context.Context, fn Check) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	done := make(chan result, 1)
+	go func() {
+		message, err := fn(ctx)
+		done <- result{message: message, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.message, r.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("check timed out: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	require := require.New(t)
+
+	checkTimeout = 50 * time.Millisecond
+	h := New()
+	h.Register("web", true, ErrorCheck(func(ctx context.Context) error { return nil }))
+	h.Register("browser", false, func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("browser session is not running")
+	})
+	h.Register("store", true, ErrorCheck(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+
+	report := h.Live(context.Background())
+	require.Equal(StatusFailing, report.Status)
+	require.Equal(StatusOK, report.Components[0].Status)
+	require.Equal(StatusFailing, report.Components[1].Status)
+	require.Contains(report.Components[2].Message, "timed out")
+
+	require.NoError(h.Check(context.Background(), "web"))
+	require.Error(h.Check(context.Background(), "browser"))
+	require.Error(h.Check(context.Background(), "unknown"))
+}
@@ -0,0 +1,244 @@
+package kiosk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/util/backlight"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+)
+
+// minutesPerDay is length of brightness schedule
+const minutesPerDay = 24 * 60
+
+// backlight returns backlight device of kiosk display. Backlight belongs to the first display
+func (k *kiosk) backlight() (*backlight.Device, error) {
+	if k.display.ID != 0 {
+		return nil, fmt.Errorf("display %d has no backlight: %w", k.display.ID, os.ErrNotExist)
+	}
+	return backlight.Open(k.config.BacklightDir, k.config.BacklightDevice)
+}
+
+// brightness returns actual backlight brightness with its schedule
+func (k *kiosk) brightness() (*api.Brightness, error) {
+	d, err := k.backlight()
+	if err != nil {
+		return nil, err
+	}
+	level, err := d.Brightness()
+	if err != nil {
+		return nil, err
+	}
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &api.Brightness{Level: level, Device: d.Name, Schedule: state.BrightnessSchedule}
+	if until := state.BrightnessOverrideUntil; len(state.BrightnessSchedule) > 0 && time.Now().Before(until) {
+		b.OverrideUntil = &until
+	}
+	return b, nil
+}
+
+// setBrightness sets backlight level and schedule and persists them in state. Level set by hand
+// overrides schedule until its next point
+func (k *kiosk) setBrightness(in api.BrightnessConfig) error {
+	if in.Level == nil && in.Schedule == nil {
+		return fmt.Errorf("brightness level or schedule is required: %w", os.ErrInvalid)
+	}
+	if in.Level != nil && (*in.Level < 0 || *in.Level > 100) {
+		return fmt.Errorf("brightness %d is out of range 0-100: %w", *in.Level, os.ErrInvalid)
+	}
+	schedule, err := brightnessSchedule(in.Schedule)
+	if err != nil {
+		return err
+	}
+	d, err := k.backlight()
+	if err != nil {
+		return err
+	}
+
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if in.Schedule != nil {
+		state.BrightnessSchedule = schedule
+		state.BrightnessOverrideUntil = time.Time{}
+	}
+	if in.Level != nil {
+		level := *in.Level
+		state.Brightness = &level
+		state.BrightnessOverrideUntil = nextBrightnessPoint(state.BrightnessSchedule, now)
+	}
+
+	if level, ok := desiredBrightness(state, now); ok {
+		k.log.Info("set brightness", zap.String("device", d.Name), zap.Int("level", level))
+		err := d.SetBrightness(level)
+		if err != nil {
+			return err
+		}
+		k.brightnessLevel = level
+	}
+
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		return fmt.Errorf("persist brightness: %w", err)
+	}
+	return nil
+}
+
+// runBrightness restores persisted brightness and follows brightness schedule every BrightnessCheckInterval
+func (k *kiosk) runBrightness(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	if k.display.ID != 0 {
+		return
+	}
+	k.checkBrightness(time.Now())
+
+	interval := k.config.BrightnessCheckInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			k.checkBrightness(now)
+		}
+	}
+}
+
+// checkBrightness sets backlight to brightness requested at now, when it differs from brightness set last time
+func (k *kiosk) checkBrightness(now time.Time) {
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	level, ok := desiredBrightness(state, now)
+	if !ok || level == k.brightnessLevel {
+		return
+	}
+
+	d, err := k.backlight()
+	if err == nil {
+		err = d.SetBrightness(level)
+	}
+	if err != nil {
+		k.log.Warn("failed to set brightness", zap.Int("level", level), zap.Error(err))
+		return
+	}
+	k.log.Info("brightness changed", zap.String("device", d.Name), zap.Int("previous", k.brightnessLevel), zap.Int("level", level))
+	k.brightnessLevel = level
+}
+
+// desiredBrightness returns brightness requested at now, either by schedule or by hand. False is
+// returned when brightness was never requested
+func desiredBrightness(state *api.KioskState, now time.Time) (int, bool) {
+	if len(state.BrightnessSchedule) > 0 && !now.Before(state.BrightnessOverrideUntil) {
+		return scheduledBrightness(state.BrightnessSchedule, now), true
+	}
+	if state.Brightness != nil {
+		return *state.Brightness, true
+	}
+	return 0, false
+}
+
+// brightnessSchedule validates schedule and returns it sorted by time. Nil is returned for empty schedule
+func brightnessSchedule(points []api.BrightnessPoint) ([]api.BrightnessPoint, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+	minutes := make(map[string]int, len(points))
+	seen := make(map[int]bool, len(points))
+	for _, p := range points {
+		m, err := p.Minute()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", err, os.ErrInvalid)
+		}
+		if seen[m] {
+			return nil, fmt.Errorf("brightness schedule has time %s more than once: %w", p.Time, os.ErrInvalid)
+		}
+		if p.Level < 0 || p.Level > 100 {
+			return nil, fmt.Errorf("brightness %d at %s is out of range 0-100: %w", p.Level, p.Time, os.ErrInvalid)
+		}
+		seen[m] = true
+		minutes[p.Time] = m
+	}
+
+	schedule := append([]api.BrightnessPoint{}, points...)
+	sort.Slice(schedule, func(i, j int) bool {
+		return minutes[schedule[i].Time] < minutes[schedule[j].Time]
+	})
+	return schedule, nil
+}
+
+// scheduledBrightness returns brightness of sorted schedule at time of day of now. Brightness changes
+// linearly between points and schedule repeats every day, so the last point leads to the first one
+func scheduledBrightness(schedule []api.BrightnessPoint, now time.Time) int {
+	if len(schedule) == 1 {
+		return schedule[0].Level
+	}
+	minute := float64(now.Hour()*60+now.Minute()) + float64(now.Second())/60
+
+	// point before now is the last one of previous day when now is before the first point
+	prev, next := len(schedule)-1, 0
+	for i, p := range schedule {
+		m, _ := p.Minute()
+		if float64(m) > minute {
+			break
+		}
+		prev, next = i, (i+1)%len(schedule)
+	}
+	from, _ := schedule[prev].Minute()
+	to, _ := schedule[next].Minute()
+	if float64(from) > minute {
+		from -= minutesPerDay
+	}
+	if to <= from {
+		to += minutesPerDay
+	}
+
+	a, b := float64(schedule[prev].Level), float64(schedule[next].Level)
+	return int(math.Round(a + (b-a)*(minute-float64(from))/float64(to-from)))
+}
+
+// nextBrightnessPoint returns time of the first schedule point after now, zero time without schedule
+func nextBrightnessPoint(schedule []api.BrightnessPoint, now time.Time) time.Time {
+	next := time.Time{}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, p := range schedule {
+		m, err := p.Minute()
+		if err != nil {
+			continue
+		}
+		t := day.Add(time.Duration(m) * time.Minute)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	return next
+}
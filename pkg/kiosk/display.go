@@ -0,0 +1,75 @@
+package kiosk
+
+import (
+	"github.com/kbinani/screenshot"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/util/x11"
+)
+
+// Displays returns displays kiosk shows content on. All connected outputs are returned with MultiDisplay,
+// the first screen otherwise. Display without size is returned when there are no screens, e.g. in headless session
+func Displays(log *zap.Logger, config *config.Config) []api.Display {
+	var displays []api.Display
+	if config.MultiDisplay {
+		var err error
+		displays, err = outputs(xDisplay(config.ScreenDisplay))
+		if err != nil {
+			log.Warn("failed to list outputs, using active screens", zap.Error(err))
+		}
+	}
+	if len(displays) == 0 {
+		displays = screens()
+	}
+	if len(displays) == 0 {
+		return []api.Display{{}}
+	}
+	if !config.MultiDisplay {
+		return displays[:1]
+	}
+	return displays
+}
+
+// outputs returns displays of connected outputs in order reported by X server, so ids do not change
+// when outputs are powered off. Disabled outputs are placed right of enabled ones
+func outputs(display string) ([]api.Display, error) {
+	conn, err := x11.ConnectDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	list, err := conn.Outputs()
+	if err != nil {
+		return nil, err
+	}
+	right := 0
+	for _, o := range list {
+		if o.Enabled && o.X+o.Width > right {
+			right = o.X + o.Width
+		}
+	}
+
+	displays := make([]api.Display, 0, len(list))
+	for i, o := range list {
+		d := api.Display{ID: i, Name: o.Name, X: o.X, Y: o.Y, Width: o.Width, Height: o.Height}
+		if !o.Enabled {
+			d.X, d.Y = right, 0
+			right += o.Width
+		}
+		displays = append(displays, d)
+	}
+	return displays, nil
+}
+
+// screens returns displays of active screens reported by Xinerama
+func screens() []api.Display {
+	var displays []api.Display
+	for i := 0; i < screenshot.NumActiveDisplays(); i++ {
+		b := screenshot.GetDisplayBounds(i)
+		displays = append(displays, api.Display{ID: i, X: b.Min.X, Y: b.Min.Y, Width: b.Dx(), Height: b.Dy()})
+	}
+	return displays
+}
@@ -0,0 +1,205 @@
+package kiosk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+	"github.com/unikiosk/unikiosk/pkg/util/x11"
+)
+
+var (
+	// idle sources detecting user input
+	idleSourceAuto = "auto"
+	idleSourceX11  = "x11"
+	idleSourcePage = "page"
+
+	// maxIdleCheckInterval limits how often idle time is checked for long timeouts
+	maxIdleCheckInterval = time.Second
+
+	// pageIdle tracks user input in the page and returns milliseconds since the last one.
+	// Page without input reports time since its navigation started
+	pageIdle = `(() => {
+	if (window.__unikioskInput === undefined) {
+		window.__unikioskInput = performance.timeOrigin;
+		for (const type of ["pointerdown", "pointermove", "keydown", "touchstart", "wheel", "scroll"]) {
+			addEventListener(type, () => { window.__unikioskInput = Date.now() }, {capture: true, passive: true});
+		}
+	}
+	return Math.round(Date.now() - window.__unikioskInput);
+})()`
+)
+
+// validIdleSource reports if source is known option. Empty source defaults to auto
+func validIdleSource(source string) bool {
+	switch source {
+	case "", idleSourceAuto, idleSourceX11, idleSourcePage:
+		return true
+	default:
+		return false
+	}
+}
+
+// idleFunc returns time since last user input
+type idleFunc func(ctx context.Context) (time.Duration, error)
+
+// runIdle returns page to content once there is no user input for IdleTimeout
+func (k *kiosk) runIdle(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	timeout := k.config.IdleTimeout
+	if timeout <= 0 {
+		return
+	}
+	idle, release := k.idleSource()
+	defer release()
+
+	interval := timeout / 10
+	if interval > maxIdleCheckInterval {
+		interval = maxIdleCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		k.checkIdle(ctx, idle, timeout, interval)
+	}
+}
+
+// idleSource returns idle detection selected by IdleSource and function releasing it
+func (k *kiosk) idleSource() (idleFunc, func()) {
+	display := &x11Idle{}
+	switch k.config.IdleSource {
+	case idleSourcePage:
+		return k.pageIdle, func() {}
+	case idleSourceX11:
+		return display.idle, display.close
+	}
+
+	// input on any display resets idle time of the screen, so displays track input of their pages
+	if k.config.MultiDisplay {
+		return k.pageIdle, func() {}
+	}
+	_, err := display.idle(context.Background())
+	if err != nil {
+		k.log.Info("display idle time is not available, detecting input in the page", zap.Error(err))
+		return k.pageIdle, func() {}
+	}
+	return display.idle, display.close
+}
+
+// checkIdle returns page to content when there was user input since content was loaded and none for timeout
+func (k *kiosk) checkIdle(ctx context.Context, idle idleFunc, timeout, checkTimeout time.Duration) {
+	if atomic.LoadInt32(&k.running) == 0 {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	d, err := idle(checkCtx)
+	if err != nil {
+		k.log.Debug("failed to get idle time", zap.Error(err))
+		return
+	}
+	if d < timeout {
+		return
+	}
+	// content loaded after last input is where page should be already
+	if loaded, ok := k.lastLoad.Load().(time.Time); ok && !time.Now().Add(-d).After(loaded) {
+		return
+	}
+	k.idleTimeout(ctx, d)
+}
+
+// idleTimeout loads content again, removing browser data first when IdleClearData is set
+func (k *kiosk) idleTimeout(ctx context.Context, idle time.Duration) {
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	// content which fails to load is retried, fallback stays until it loads
+	if state.LastLoad.Failed() {
+		return
+	}
+	k.log.Info("page is idle, returning to content", zap.Duration("idle", idle), zap.String("content", state.Content))
+
+	if k.config.IdleClearData {
+		err := k.clearData(ctx, api.ClearOptions{Cookies: true, Storage: true, Cache: true})
+		if err != nil {
+			k.log.Warn("failed to clear browser data", zap.Error(err))
+		}
+	}
+	k.startLoad(ctx, state.Content)
+
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+	k.publish(api.EventTypeIdleTimeout, state)
+}
+
+// pageIdle returns time since last user input in the page
+func (k *kiosk) pageIdle(ctx context.Context) (time.Duration, error) {
+	value, err := k.driver.Eval(ctx, pageIdle)
+	if err != nil {
+		return 0, err
+	}
+	var ms float64
+	err = json.Unmarshal(value, &ms)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected idle time %s: %w", value, err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// x11Idle reads idle time of the display, connecting again once connection fails
+type x11Idle struct {
+	conn *x11.Conn
+}
+
+func (x *x11Idle) idle(ctx context.Context) (time.Duration, error) {
+	if x.conn == nil {
+		conn, err := x11.Connect()
+		if err != nil {
+			return 0, err
+		}
+		x.conn = conn
+	}
+	d, err := x.conn.IdleTime()
+	if err != nil {
+		x.close()
+		return 0, err
+	}
+	return d, nil
+}
+
+func (x *x11Idle) close() {
+	if x.conn != nil {
+		x.conn.Close()
+		x.conn = nil
+	}
+}
+
+// origin returns origin of web content, empty for local files
+func origin(content string) string {
+	u, err := url.Parse(content)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,957 @@
+package kiosk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kbinani/screenshot"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/allowlist"
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/browser"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/console"
+	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/store"
+	"github.com/unikiosk/unikiosk/pkg/util/imageutil"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+)
+
+// displayStateKey returns key of display state in the store
+var displayStateKey = store.StateKey
+
+// notificationQueueSize is number of notifications queued for publishing before new ones are dropped
+var notificationQueueSize = 100
+
+// kiosk shows content on single display. Each display is run by its own kiosk with own browser and state
+type kiosk struct {
+	log    *zap.Logger
+	config *config.Config
+
+	// display is display kiosk shows content on, its geometry changes with outputs under displayMu
+	display   api.Display
+	displayMu sync.RWMutex
+	// stateKey is key of display state in the store
+	stateKey string
+
+	events  eventer.Eventer
+	driver  browser.Driver
+	started atomic.Value
+	store   store.Store
+	audit   audit.Log
+	console *console.Buffer
+	// monitor controls screen power and screen configures outputs, replaced in tests
+	monitor monitor
+	screen  screen
+
+	// running is set to 1 while browser session is running
+	running int32
+	// stopReason is why kiosk stopped browser during current run
+	stopReason atomic.Value
+	// lastPing is pingResult of the last watchdog check during current run
+	lastPing atomic.Value
+	// pausedUntil is time.Time until which restarts are paused after crash loop
+	pausedUntil atomic.Value
+	// lastLoad is time.Time of last successful content load
+	lastLoad atomic.Value
+
+	// loadMu serializes state changes of requests, loads and retries
+	loadMu sync.Mutex
+	// navigateMu serializes navigations of content loads, cancelLoad stops load in progress
+	navigateMu sync.Mutex
+	cancelLoad context.CancelFunc
+	// powerMu serializes screen power changes of requests and power checks
+	powerMu sync.Mutex
+	// powerScheduleChanged wakes power schedule up once schedule changes
+	powerScheduleChanged chan struct{}
+	// notifications are queued notifications, published in order by runNotifications
+	notifications chan api.Event
+	// brightnessLevel is brightness set to backlight last time, -1 until it is set. Guarded by loadMu
+	brightnessLevel int
+	// retrying is content which failed to load and is retried, cancelRetry stops the retries
+	retrying    string
+	cancelRetry context.CancelFunc
+
+	// allowlist is *allowlist.List of current content, nil allows all navigations
+	allowlist atomic.Value
+	// navigating is set to 1 while kiosk navigates the page itself
+	navigating int32
+}
+
+type Kiosk interface {
+	Run(ctx context.Context) error
+	Stop() error
+
+	PowerOff() error
+	PowerOn() error
+	Screenshot(ctx context.Context, opts api.ScreenshotOptions) ([]byte, error)
+	Eval(ctx context.Context, opts api.EvalOptions) (*api.EvalResult, error)
+
+	// CheckBrowser returns error when browser session is not running or page does not respond
+	CheckBrowser(ctx context.Context) error
+	// CheckPageLoad reports when content was loaded successfully last time. It returns error
+	// while content fails to load or is still loading
+	CheckPageLoad(ctx context.Context) (string, error)
+}
+
+func New(log *zap.Logger, config *config.Config, display api.Display, events eventer.Eventer, store store.Store, audit audit.Log, driver browser.Driver, console *console.Buffer) (*kiosk, error) {
+	if !validFallback(config.PageLoadFallback) {
+		return nil, fmt.Errorf("unknown page load fallback %q, expected one of: last_good, error_page, none", config.PageLoadFallback)
+	}
+	if !validIdleSource(config.IdleSource) {
+		return nil, fmt.Errorf("unknown idle source %q, expected one of: auto, x11, page", config.IdleSource)
+	}
+
+	k := &kiosk{
+		log:      log,
+		config:   config,
+		display:  display,
+		stateKey: displayStateKey(display.ID),
+		events:   events,
+		store:    store,
+		audit:    audit,
+		driver:   driver,
+		console:  console,
+		monitor:  newMonitor(config, display),
+		screen:   newX11Screen(config.ScreenDisplay),
+		started:  atomic.Value{},
+
+		brightnessLevel:      -1,
+		powerScheduleChanged: make(chan struct{}, 1),
+		notifications:        make(chan api.Event, notificationQueueSize),
+	}
+
+	k.started.Store(false)
+	k.stopReason.Store(stopReason{})
+	k.lastPing.Store(pingResult{})
+	k.pausedUntil.Store(time.Time{})
+	k.allowlist.Store((*allowlist.List)(nil))
+
+	// empty get to we set it on the first run
+	// Id we don't have state - bootstrap with defaults
+	state, err := k.store.Get(k.stateKey)
+	if err != nil || state == nil {
+		k.log.Info("no state found, new start")
+		s := api.KioskState{
+			Display: display.ID,
+			Content: k.config.DefaultWebServerURL,
+			SizeW:   display.Width,
+			SizeH:   display.Height,
+			Title:   "UniKiosk",
+		}
+		err = k.store.Persist(k.stateKey, s)
+		if err != nil {
+			k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+		}
+	}
+
+	return k, nil
+}
+
+func (k *kiosk) Run(ctx context.Context) error {
+	k.log.Info("start kiosk manager", zap.String("browser", k.config.BrowserDriver), zap.Int("display", k.display.ID))
+
+	// browser session blocks, so dispatcher runs as separate thread.
+	// dispatcher responsible for acting to grpc calls and updating the state.
+	// subscribe before browser starts, so no request sent once it is running is missed
+	listener := k.events.Subscribe(ctx)
+	go k.runDispatcher(ctx, listener)
+	go k.runNotifications(ctx)
+	go k.runConsole(ctx)
+	go k.runNavigation(ctx)
+	go k.runIdle(ctx)
+	go k.runPower(ctx)
+	go k.runBrightness(ctx)
+	go k.runPowerSchedule(ctx)
+
+	// outputs are configured before browser starts, so it opens on the final screen
+	k.restoreOutputs()
+
+	b := &backoff{
+		initial: durationOr(k.config.BrowserRestartBackoff, defaultRestartBackoff),
+		max:     durationOr(k.config.BrowserRestartBackoffMax, defaultRestartBackoffMax),
+		limit:   k.config.BrowserCrashLoopRestarts,
+		pause:   durationOr(k.config.BrowserCrashLoopPause, defaultCrashLoopPause),
+	}
+	if b.limit <= 0 {
+		b.limit = defaultCrashLoopRestarts
+	}
+
+	var reason error
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if reason != nil {
+			k.recordRestart(reason)
+		}
+
+		started := time.Now()
+		err := k.startOrRecover(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			err = errors.New("browser exited")
+		}
+		reason = err
+
+		delay, paused := b.next(time.Since(started))
+		if paused {
+			k.log.Error("browser keeps crashing, restarts are paused", zap.Duration("pause", delay), zap.Error(err))
+			k.pausedUntil.Store(time.Now().Add(delay))
+		} else {
+			k.log.Error("browser stopped, restarting", zap.Duration("delay", delay), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		k.pausedUntil.Store(time.Time{})
+	}
+}
+
+// recordRestart counts browser restart in state and notifies about it
+func (k *kiosk) recordRestart(reason error) {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	state.Restarts++
+	state.LastRestart = time.Now()
+	state.RestartReason = reason.Error()
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+	k.publish(api.EventTypeBrowserRestarted, state)
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return fallback
+}
+
+func (k *kiosk) Stop() error {
+	return k.driver.Stop()
+}
+
+// PowerOff - powers off the screen
+func (k *kiosk) PowerOff() error {
+	return k.monitor.SetPower(false)
+}
+
+// PowerOn - powers on the screen
+func (k *kiosk) PowerOn() error {
+	k.log.Debug("execute powerOn")
+	err := k.monitor.SetPower(true)
+	if err != nil {
+		return err
+	}
+	// output turned on again has its preferred mode
+	if k.config.MultiDisplay {
+		k.restoreOutputs()
+	}
+	return nil
+}
+
+// Screenshot captures display and encodes it according to options. When no display
+// can be captured, browser page is captured instead
+func (k *kiosk) Screenshot(ctx context.Context, opts api.ScreenshotOptions) ([]byte, error) {
+	img, err := k.capture(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = imageutil.Encode(buf, imageutil.Thumbnail(img, opts.Width), opts.Format, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Eval evaluates expression in the current page. Exception thrown by expression is
+// returned in result, error is returned only when expression could not be evaluated
+func (k *kiosk) Eval(ctx context.Context, opts api.EvalOptions) (*api.EvalResult, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = api.DefaultEvalTimeout
+	}
+	if timeout > api.MaxEvalTimeout {
+		timeout = api.MaxEvalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	k.log.Info("evaluate expression", zap.String("expression", opts.Expression), zap.Duration("timeout", timeout))
+	value, err := k.driver.Eval(ctx, opts.Expression)
+	exception := &browser.Exception{}
+	switch {
+	case errors.As(err, &exception):
+		return &api.EvalResult{Exception: exception.Message}, nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, fmt.Errorf("evaluation did not finish in %s: %w", timeout, err)
+	case err != nil:
+		return nil, fmt.Errorf("failed to evaluate expression: %w", err)
+	}
+	return &api.EvalResult{Result: value}, nil
+}
+
+// capture returns image of kiosk display or of all screens stitched together. Browser page is captured
+// when there are no screens, e.g. in headless session
+func (k *kiosk) capture(ctx context.Context, opts api.ScreenshotOptions) (image.Image, error) {
+	if !opts.Stitch && opts.Display != k.display.ID {
+		return nil, fmt.Errorf("display %d: %w", opts.Display, os.ErrNotExist)
+	}
+
+	d := k.bounds()
+	n := screenshot.NumActiveDisplays()
+	if n == 0 || d.Width == 0 {
+		data, err := k.driver.Screenshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("no screen found, failed to capture browser: %w", err)
+		}
+		return png.Decode(bytes.NewReader(data))
+	}
+	if opts.Stitch {
+		bounds := image.Rectangle{}
+		for i := 0; i < n; i++ {
+			bounds = bounds.Union(screenshot.GetDisplayBounds(i))
+		}
+		return screenshot.CaptureRect(bounds)
+	}
+
+	return screenshot.CaptureRect(image.Rect(d.X, d.Y, d.X+d.Width, d.Y+d.Height))
+}
+
+func (k *kiosk) startOrRecover(ctx context.Context) error {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return fmt.Errorf("failed to get state: %s", err)
+	}
+
+	k.log.Info("set proxy", zap.String("http", k.config.ProxyHTTPServerAddr), zap.String("https", k.config.ProxyHTTPSServerAddr))
+
+	// browser starts on blank page and content is loaded once it runs, so the load is verified as
+	// loads of requests are. Content which failed to load is not shown again, fallback stays until retry succeeds
+	content := blankPage
+	failed := state.LastLoad.Failed()
+	if failed && state.LastLoad.Fallback != "" {
+		content = state.LastLoad.Fallback
+	}
+
+	// browser applies allowlist once it starts
+	err = k.applyAllowlist(ctx, state)
+	if err != nil {
+		k.log.Warn("failed to apply navigation allowlist", zap.Error(err))
+	}
+
+	k.stopReason.Store(stopReason{})
+	k.lastPing.Store(pingResult{})
+	err = k.driver.Start(ctx, content)
+	if err != nil {
+		return fmt.Errorf("failed to start browser: %w", err)
+	}
+	k.restoreWindow(ctx)
+	k.started.Store(true)
+
+	atomic.StoreInt32(&k.running, 1)
+	defer atomic.StoreInt32(&k.running, 0)
+	k.loadMu.Lock()
+	if failed {
+		k.scheduleRetry(ctx, state.Content)
+	} else {
+		k.startLoad(ctx, state.Content)
+	}
+	k.loadMu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go k.watch(ctx, done)
+
+	err = k.driver.Wait()
+	if reason := k.stopReason.Load().(stopReason); reason.err != nil {
+		return reason.err
+	}
+	return err
+}
+
+func (k *kiosk) CheckBrowser(ctx context.Context) error {
+	if atomic.LoadInt32(&k.running) == 0 {
+		if until := k.pausedUntil.Load().(time.Time); time.Now().Before(until) {
+			return fmt.Errorf("browser keeps crashing, restarts are paused until %s", until.Format(time.RFC3339))
+		}
+		return fmt.Errorf("browser session is not running")
+	}
+
+	// watchdog checks page periodically, without it page is checked right away
+	var err error
+	if k.config.BrowserWatchdogInterval > 0 {
+		err = k.lastPing.Load().(pingResult).err
+	} else {
+		err = k.ping(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("browser is not responding: %w", err)
+	}
+	return nil
+}
+
+func (k *kiosk) CheckPageLoad(ctx context.Context) (string, error) {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get state: %w", err)
+	}
+	if r := state.LastLoad; r.Failed() {
+		return "", fmt.Errorf("%s failed to load: %s, retried %d times", r.URL, r.Error, r.Retries)
+	}
+
+	last, ok := k.lastLoad.Load().(time.Time)
+	if !ok {
+		return "", fmt.Errorf("content was not loaded yet")
+	}
+	// load of changed content is in progress, last successful load was of previous content
+	if state.LastLoad == nil {
+		return "", fmt.Errorf("%s is still loading", state.Content)
+	}
+	return fmt.Sprintf("last successful load at %s", last.UTC().Format(time.RFC3339)), nil
+}
+
+func (k *kiosk) runDispatcher(ctx context.Context, listener <-chan *eventer.EventWrapper) {
+	defer recover.Panic(k.log)
+
+	for {
+		if k.started.Load().(bool) {
+			break
+		}
+	}
+
+	for event := range listener {
+		// notifications are published by kiosk itself, requests of other displays are handled by their kiosks
+		if event.Payload.Type != api.EventTypeRequest || event.Payload.Request.TargetDisplay() != k.display.ID {
+			continue
+		}
+		err := k.handle(ctx, event)
+		if err != nil {
+			k.log.Error("dispatch error", zap.Error(err))
+			event.Callback <- &eventer.EventWrapper{Error: err}
+		}
+	}
+}
+
+// runConsole collects browser console messages and publishes uncaught exceptions when enabled
+func (k *kiosk) runConsole(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-k.driver.Console():
+			m.Display = k.display.ID
+			k.console.Add(m)
+			if m.Source != api.ConsoleSourceException || !k.config.BrowserExceptionEvents {
+				continue
+			}
+			state, err := k.store.Get(k.stateKey)
+			if err != nil {
+				k.log.Warn("failed to get state", zap.Error(err))
+				continue
+			}
+			k.enqueue(api.Event{
+				Type:     api.EventTypeBrowserException,
+				Response: api.StateToResponse(state),
+				Console:  &m,
+			})
+		}
+	}
+}
+
+// handle will handle events intended for lorca
+func (k *kiosk) handle(ctx context.Context, event *eventer.EventWrapper) (err error) {
+	e := event.Payload
+
+	callback := event.Callback
+	hash := k.getURLHash(e.Request.Content)
+
+	// every request is recorded, also the one failed before it was executed
+	var previous *api.KioskState
+	defer func() {
+		k.record(e, previous, err)
+	}()
+
+	previous, err = k.store.Get(k.stateKey)
+	if err != nil {
+		return err
+	}
+
+	// screenshot, evaluation result and cookies are returned to the caller only, they are not persisted in state
+	var screen []byte
+	var eval *api.EvalResult
+	var cookies []api.Cookie
+	var outputs []api.Output
+	var brightness *api.Brightness
+	var powerSchedule *api.PowerScheduleStatus
+
+	k.log.Info("execute action", zap.String("type", e.Request.Action.String()))
+	switch e.Request.Action {
+	case api.ScreenActionPowerOff:
+		k.log.Info("lorca power off")
+		err := k.setPower(ctx, e.Request, k.PowerOff)
+		if err != nil {
+			return err
+		}
+		if !e.Caller.Scheduled {
+			err := k.overridePowerSchedule(api.PowerStateOff, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+	case api.ScreenActionPowerOn:
+		k.log.Info("lorca power on")
+		err := k.setPower(ctx, e.Request, k.PowerOn)
+		if err != nil {
+			return err
+		}
+		if !e.Caller.Scheduled {
+			err := k.overridePowerSchedule(api.PowerStateOn, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+	case api.ScreenActionScreenShot:
+		k.log.Info("lorca screenShot")
+		opts := api.ScreenshotOptions{}
+		if e.Request.ScreenshotOptions != nil {
+			opts = *e.Request.ScreenshotOptions
+		}
+		screen, err = k.Screenshot(ctx, opts)
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionEval:
+		if e.Request.EvalOptions == nil || e.Request.EvalOptions.Expression == "" {
+			return fmt.Errorf("expression is required: %w", os.ErrInvalid)
+		}
+		eval, err = k.Eval(ctx, *e.Request.EvalOptions)
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionGetCookies:
+		cookies, err = k.cookies(ctx, e.Request.CookieOptions)
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionSetCookies:
+		err = k.setCookies(ctx, e.Request.Cookies)
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionDeleteCookies:
+		cookies, err = k.deleteCookies(ctx, e.Request.CookieOptions)
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionClearData:
+		if e.Request.ClearOptions == nil {
+			return fmt.Errorf("clear options are required: %w", os.ErrInvalid)
+		}
+		err = k.clearData(ctx, *e.Request.ClearOptions)
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionGetOutputs:
+		outputs, err = k.screen.Outputs()
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionSetOutput:
+		if e.Request.Output == nil {
+			return fmt.Errorf("output is required: %w", os.ErrInvalid)
+		}
+		err = k.setOutput(ctx, *e.Request.Output)
+		if err != nil {
+			return err
+		}
+		outputs, err = k.screen.Outputs()
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionGetBrightness:
+		brightness, err = k.brightness()
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionSetBrightness:
+		if e.Request.Brightness == nil {
+			return fmt.Errorf("brightness is required: %w", os.ErrInvalid)
+		}
+		err = k.setBrightness(*e.Request.Brightness)
+		if err != nil {
+			return err
+		}
+		brightness, err = k.brightness()
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionGetPowerSchedule:
+		powerSchedule, err = k.powerSchedule()
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionSetPowerSchedule:
+		err = k.setPowerSchedule(e.Request.PowerSchedule)
+		if err != nil {
+			return err
+		}
+		powerSchedule, err = k.powerSchedule()
+		if err != nil {
+			return err
+		}
+	case api.ScreenActionUpdate:
+		k.log.Info("lorca update")
+		err := k.updateState(ctx, e.Request, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	// once steps has been handled - get state and return
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return err
+	}
+
+	result := &eventer.EventWrapper{
+		Payload: api.Event{
+			Response: api.StateToResponse(state),
+		},
+	}
+	result.Payload.Response.Screenshot = screen
+	result.Payload.Response.Eval = eval
+	result.Payload.Response.Cookies = cookies
+	result.Payload.Response.Outputs = outputs
+	result.Payload.Response.Brightness = brightness
+	result.Payload.Response.PowerSchedule = powerSchedule
+
+	callback <- result
+
+	k.notify(e.Request.Action, previous, state)
+	return nil
+}
+
+// record writes action outcome to audit log. Previous state is nil when it could not be read
+func (k *kiosk) record(e api.Event, previous *api.KioskState, err error) {
+	// reads do not change kiosk
+	if !e.Request.Action.Mutating() {
+		return
+	}
+
+	entry := api.AuditEntry{
+		Time:      time.Now(),
+		Identity:  e.Caller.Identity,
+		Address:   e.Caller.Address,
+		RequestID: e.Caller.RequestID,
+		Display:   k.display.ID,
+		Action:    e.Request.Action.String(),
+		Result:    api.AuditResultSuccess,
+	}
+	if previous != nil {
+		entry.PreviousContent = previous.Content
+		entry.Content = previous.Content
+	}
+	if current, getErr := k.store.Get(k.stateKey); getErr == nil {
+		entry.Content = current.Content
+	}
+	if err != nil {
+		entry.Result = api.AuditResultError
+		entry.Error = err.Error()
+	}
+
+	auditErr := k.audit.Record(entry)
+	if auditErr != nil {
+		k.log.Error("failed to record audit entry", zap.String("action", entry.Action), zap.Error(auditErr))
+	}
+}
+
+// notify publishes state transitions caused by action
+func (k *kiosk) notify(action api.ScreenAction, previous, current *api.KioskState) {
+	var types []api.EventType
+	if previous.Content != current.Content {
+		types = append(types, api.EventTypeContentChanged)
+	}
+	if previous.PowerState != current.PowerState {
+		types = append(types, api.EventTypePowerChanged)
+	}
+	if action == api.ScreenActionScreenShot {
+		types = append(types, api.EventTypeScreenshotTaken)
+	}
+	for _, t := range types {
+		k.publish(t, current)
+	}
+}
+
+// publish queues notification with kiosk state
+func (k *kiosk) publish(t api.EventType, state *api.KioskState) {
+	k.enqueue(api.Event{
+		Type:     t,
+		Response: api.StateToResponse(state),
+	})
+}
+
+// enqueue queues notification without waiting, as dispatcher is subscribed too and must not wait
+// for its own notifications to be delivered
+func (k *kiosk) enqueue(event api.Event) {
+	select {
+	case k.notifications <- event:
+	default:
+		k.log.Warn("notification queue is full, dropping notification", zap.String("type", string(event.Type)))
+	}
+}
+
+// runNotifications publishes queued notifications one by one, so subscribers receive them in order
+// they were queued
+func (k *kiosk) runNotifications(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-k.notifications:
+			err := k.events.Publish(&eventer.EventWrapper{Payload: event})
+			if err != nil {
+				k.log.Warn("failed to publish event", zap.String("type", string(event.Type)), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (k *kiosk) getURLHash(in string) string {
+	h := sha256.New()
+	h.Write([]byte(in))
+
+	return string(h.Sum(nil))
+}
+
+func (k *kiosk) updateState(ctx context.Context, in api.KioskRequest, urlHash string) error {
+	// state is not changed by retries of failed content while request is handled
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Error("failed to get state", zap.Error(err))
+		return err
+	}
+
+	if in.Allowlist != nil {
+		_, err := allowlist.New(in.Allowlist)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Dispatch is async, so we need to persist inside of it :/ this is not ideal as context are mixed
+	contentChanged := in.Content != "" && urlHash != state.ContentHash
+	if contentChanged {
+		state.Content = in.Content
+		state.ContentHash = urlHash
+	}
+	// allowlist belongs to content, it is applied before content is loaded
+	if contentChanged || in.Allowlist != nil {
+		state.Allowlist = in.Allowlist
+		err := k.applyAllowlist(ctx, state)
+		if err != nil {
+			k.log.Warn("failed to apply navigation allowlist", zap.Error(err))
+		}
+	}
+	// content loads in background, its outcome is recorded in state once it is known
+	if contentChanged {
+		state.LastLoad = nil
+		k.startLoad(ctx, state.Content)
+	}
+	if in.Title != "" {
+		state.Title = in.Title
+	}
+	// zero size means size was not requested
+	window := false
+	if in.SizeW > 0 && in.SizeH > 0 {
+		state.SizeW = in.SizeW
+		state.SizeH = in.SizeH
+		state.Windowed = true
+		window = true
+	}
+	if in.Position != nil {
+		state.PosX = in.Position.X
+		state.PosY = in.Position.Y
+		state.Windowed = true
+		window = true
+	}
+	if in.Fullscreen != nil {
+		state.Windowed = !*in.Fullscreen
+		window = true
+	}
+	var windowErr error
+	if window && !windowApplied(state) {
+		windowErr = k.applyWindow(ctx, state)
+	}
+
+	switch in.Action {
+	case api.ScreenActionPowerOff:
+		state.DesiredPowerState = api.PowerStateOff
+		state.PowerState = k.readPower()
+	case api.ScreenActionPowerOn:
+		state.DesiredPowerState = api.PowerStateOn
+		state.PowerState = k.readPower()
+	}
+
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+		return err
+	}
+	return windowErr
+}
+
+// windowSettleTimeout is how long window is polled until it reports requested geometry
+var windowSettleTimeout = time.Second
+
+// applyWindow changes browser window to size, position and fullscreen mode of state and
+// records geometry read back from the browser in state.Geometry
+func (k *kiosk) applyWindow(ctx context.Context, state *api.KioskState) error {
+	x, y := k.offset()
+	bounds := browser.Bounds{State: browser.WindowStateFullscreen}
+	if state.Windowed {
+		bounds = browser.Bounds{
+			Left:   x + state.PosX,
+			Top:    y + state.PosY,
+			Width:  state.SizeW,
+			Height: state.SizeH,
+			State:  browser.WindowStateNormal,
+		}
+	} else if k.config.MultiDisplay {
+		// window goes fullscreen on display it is on, so it is moved to its display first
+		d := k.bounds()
+		err := k.driver.SetBounds(ctx, browser.Bounds{Left: d.X, Top: d.Y, Width: d.Width, Height: d.Height, State: browser.WindowStateNormal})
+		if err != nil {
+			return fmt.Errorf("failed to move window to display %d: %w", d.ID, err)
+		}
+	}
+
+	err := k.driver.SetBounds(ctx, bounds)
+	if err != nil {
+		return fmt.Errorf("failed to change window: %w", err)
+	}
+
+	// window managers apply changes asynchronously, so wait until window settles
+	deadline := time.Now().Add(windowSettleTimeout)
+	for {
+		err := k.readWindow(ctx, state)
+		if err != nil {
+			return err
+		}
+		if windowApplied(state) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			k.log.Warn("window geometry differs from requested",
+				zap.Bool("windowed", state.Windowed),
+				zap.Int("x", state.PosX), zap.Int("y", state.PosY),
+				zap.Int("width", state.SizeW), zap.Int("height", state.SizeH),
+				zap.Any("actual", state.Geometry))
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// readWindow records actual window geometry in state.Geometry
+func (k *kiosk) readWindow(ctx context.Context, state *api.KioskState) error {
+	actual, err := k.driver.Bounds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read window geometry: %w", err)
+	}
+	x, y := k.offset()
+	state.Geometry = &api.WindowGeometry{
+		X:          actual.Left - x,
+		Y:          actual.Top - y,
+		Width:      actual.Width,
+		Height:     actual.Height,
+		Fullscreen: actual.State == browser.WindowStateFullscreen,
+	}
+	return nil
+}
+
+// offset returns position of kiosk display on the screen. With multiple displays window position is relative to it
+func (k *kiosk) offset() (int, int) {
+	if !k.config.MultiDisplay {
+		return 0, 0
+	}
+	d := k.bounds()
+	return d.X, d.Y
+}
+
+// windowApplied reports if actual window geometry matches requested one
+func windowApplied(state *api.KioskState) bool {
+	g := state.Geometry
+	if g == nil {
+		return false
+	}
+	if !state.Windowed {
+		return g.Fullscreen
+	}
+	return !g.Fullscreen && g.X == state.PosX && g.Y == state.PosY && g.Width == state.SizeW && g.Height == state.SizeH
+}
+
+// restoreWindow applies persisted window state to newly started browser. Browser starts in
+// fullscreen on the first display, so window is only changed when it was requested or belongs to other display
+func (k *kiosk) restoreWindow(ctx context.Context) {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	if state.Windowed || k.config.MultiDisplay {
+		err = k.applyWindow(ctx, state)
+	} else {
+		err = k.readWindow(ctx, state)
+	}
+	if err != nil {
+		k.log.Warn("failed to restore window", zap.Error(err))
+		return
+	}
+
+	// state might change while window settles, only geometry is updated
+	current, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	current.Geometry = state.Geometry
+	err = k.store.Persist(k.stateKey, *current)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+}
@@ -0,0 +1,1500 @@
+package kiosk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/browser"
+	"github.com/unikiosk/unikiosk/pkg/browser/fake"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/console"
+	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/store"
+	"github.com/unikiosk/unikiosk/pkg/store/memory"
+	"github.com/unikiosk/unikiosk/pkg/util/logger"
+	"github.com/unikiosk/unikiosk/pkg/util/x11"
+)
+
+var defaultURL = "http://127.0.0.1:8081"
+
+// stateKey is state key of the first display, tests run kiosk on it unless they say otherwise
+var stateKey = store.StateKey(0)
+
+// newTestKiosk runs kiosk with fake browser. Kiosk is stopped when test finishes
+func newTestKiosk(t *testing.T, s store.Store) (*kiosk, *fake.Driver, *eventer.ChannelEventer) {
+	return newTestKioskWithConfig(t, s, &config.Config{})
+}
+
+func newTestKioskWithConfig(t *testing.T, s store.Store, c *config.Config) (*kiosk, *fake.Driver, *eventer.ChannelEventer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	events := eventer.New(ctx, logger.GetLoggerInstance("", zap.DebugLevel))
+	k, driver := runTestKiosk(t, s, c, api.Display{}, events)
+	return k, driver, events
+}
+
+// runTestKiosk runs kiosk of display with fake browser. Kiosks of the same test share config and events
+func runTestKiosk(t *testing.T, s store.Store, c *config.Config, display api.Display, events eventer.Eventer) (*kiosk, *fake.Driver) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// config is set once, it is read by kiosks which already run
+	if c.StateDir == "" {
+		c.DefaultWebServerURL = defaultURL
+		c.StateDir = t.TempDir()
+		c.BrowserExceptionEvents = true
+	}
+	if c.BrowserRestartBackoff == 0 {
+		c.BrowserRestartBackoff = 10 * time.Millisecond
+	}
+	log := logger.GetLoggerInstance("", zap.DebugLevel)
+
+	auditLog, err := audit.New(log, c)
+	require.NoError(t, err)
+
+	driver := fake.New()
+	k, err := New(log, c, display, events, s, auditLog, driver, console.New(10))
+	require.NoError(t, err)
+	k.monitor = &fakeMonitor{}
+	k.screen = &fakeScreen{}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- k.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		driver.Stop()
+		<-done
+	})
+
+	// browser starts on blank page and loads content
+	require.Eventually(t, func() bool {
+		return driver.Running() && driver.URL() != blankPage
+	}, time.Second, 10*time.Millisecond)
+	return k, driver
+}
+
+// waitLoad waits until load of content is recorded in state of the first display and returns its outcome
+func waitLoad(t *testing.T, s store.Store, content string) *api.LoadResult {
+	var result *api.LoadResult
+	require.Eventually(t, func() bool {
+		state, err := s.Get(stateKey)
+		if err != nil || state.LastLoad == nil || state.LastLoad.URL != content {
+			return false
+		}
+		result = state.LastLoad
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	return result
+}
+
+// failingStore fails to persist state while failing is set
+type failingStore struct {
+	*memory.MemoryStore
+	failing int32
+}
+
+func (s *failingStore) Persist(key string, in api.KioskState) error {
+	if atomic.LoadInt32(&s.failing) == 1 {
+		return errors.New("no space left on device")
+	}
+	return s.MemoryStore.Persist(key, in)
+}
+
+func request(events eventer.Eventer, req api.KioskRequest) (*api.KioskResponse, error) {
+	result, err := events.Emit(&eventer.EventWrapper{
+		Payload: api.Event{
+			Request: req,
+			Caller:  api.Caller{Identity: "test"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Payload.Response, nil
+}
+
+// fakeMonitor records power requests. Power reads back requested power unless it is changed by the test
+type fakeMonitor struct {
+	mu       sync.Mutex
+	power    api.PowerState
+	err      error
+	readErr  error
+	requests []bool
+}
+
+func (m *fakeMonitor) SetPower(on bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.requests = append(m.requests, on)
+	m.power = api.PowerStateOff
+	if on {
+		m.power = api.PowerStateOn
+	}
+	return nil
+}
+
+func (m *fakeMonitor) Power() (api.PowerState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readErr != nil {
+		return api.PowerStateUnknown, m.readErr
+	}
+	return m.power, nil
+}
+
+func (m *fakeMonitor) set(power api.PowerState, err, readErr error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.power = power
+	m.err = err
+	m.readErr = readErr
+}
+
+func (m *fakeMonitor) Requests() []bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]bool{}, m.requests...)
+}
+
+// fakeScreen records output requests and applies them to its outputs
+type fakeScreen struct {
+	mu       sync.Mutex
+	outputs  []api.Output
+	requests []api.OutputConfig
+}
+
+func (f *fakeScreen) Outputs() ([]api.Output, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]api.Output{}, f.outputs...), nil
+}
+
+func (f *fakeScreen) SetOutput(config api.OutputConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, config)
+	for i, o := range f.outputs {
+		if o.Name != config.Name {
+			continue
+		}
+		if !o.Enabled {
+			return fmt.Errorf("output %s: %w", o.Name, x11.ErrOutputDisabled)
+		}
+		if config.Width != 0 && config.Width != 1920 && config.Width != 1280 {
+			return fmt.Errorf("mode %dx%d is not supported: %w", config.Width, config.Height, os.ErrInvalid)
+		}
+		if config.Width != 0 {
+			o.Width, o.Height = config.Width, config.Height
+		}
+		if config.Rotation != "" {
+			o.Rotation = config.Rotation
+		}
+		if o.Rotation == api.RotationLeft || o.Rotation == api.RotationRight {
+			o.Width, o.Height = o.Height, o.Width
+		}
+		f.outputs[i] = o
+		return nil
+	}
+	return fmt.Errorf("output %s: %w", config.Name, os.ErrNotExist)
+}
+
+func (f *fakeScreen) set(outputs []api.Output) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outputs = outputs
+}
+
+func (f *fakeScreen) Requests() []api.OutputConfig {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]api.OutputConfig{}, f.requests...)
+}
+
+// waitEvent returns first notification of type t published to listener, skipping other events
+func waitEvent(t *testing.T, listener <-chan *eventer.EventWrapper, eventType api.EventType) api.Event {
+	for {
+		select {
+		case event := <-listener:
+			if event.Payload.Type == eventType {
+				return event.Payload
+			}
+		case <-time.After(5 * time.Second):
+			require.Failf(t, "event was not published", "type %s", eventType)
+		}
+	}
+}
+
+func TestKiosk_bootstrap(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, driver, _ := newTestKiosk(t, s)
+
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(defaultURL, state.Content)
+	require.Equal("UniKiosk", state.Title)
+	require.Equal(defaultURL, driver.URL())
+	require.Equal([]string{defaultURL}, driver.Loads())
+	require.False(waitLoad(t, s, defaultURL).Failed())
+}
+
+func TestKiosk_update(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKiosk(t, s)
+
+	resp, err := request(events, api.KioskRequest{
+		Action:  api.ScreenActionUpdate,
+		Content: "https://synpse.net",
+		Title:   "Synpse",
+		SizeW:   800,
+		SizeH:   600,
+	})
+	require.NoError(err)
+	require.Equal("https://synpse.net", resp.Content)
+	require.Equal("Synpse", resp.Title)
+	waitLoad(t, s, "https://synpse.net")
+	require.Equal([]string{defaultURL, "https://synpse.net"}, driver.Loads())
+
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal("https://synpse.net", state.Content)
+	require.Equal(k.getURLHash("https://synpse.net"), state.ContentHash)
+	require.Equal("Synpse", state.Title)
+	require.Equal(800, state.SizeW)
+	require.Equal(600, state.SizeH)
+
+	// same content is not loaded again
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net"})
+	require.NoError(err)
+	require.Len(driver.Loads(), 2)
+
+	// title only update keeps content
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Title: "Dashboard"})
+	require.NoError(err)
+	require.Len(driver.Loads(), 2)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.Equal("https://synpse.net", state.Content)
+	require.Equal("Dashboard", state.Title)
+
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://unikiosk.io"})
+	require.NoError(err)
+	waitLoad(t, s, "https://unikiosk.io")
+	require.Equal([]string{defaultURL, "https://synpse.net", "https://unikiosk.io"}, driver.Loads())
+
+	entries, err := k.audit.Query(time.Time{}, time.Time{}, 0)
+	require.NoError(err)
+	require.Len(entries, 4)
+	require.Equal("update", entries[0].Action)
+	require.Equal("test", entries[0].Identity)
+	require.Equal("https://synpse.net", entries[0].PreviousContent)
+	require.Equal("https://unikiosk.io", entries[0].Content)
+	require.Equal(api.AuditResultSuccess, entries[0].Result)
+}
+
+func TestKiosk_notificationOrder(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, _, events := newTestKiosk(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := events.Subscribe(ctx)
+	changed := make(chan string, 20)
+	go func() {
+		for event := range listener {
+			if event.Payload.Type == api.EventTypeContentChanged {
+				changed <- event.Payload.Response.Content
+			}
+		}
+	}()
+
+	// notifications of successive requests are published in order of the requests
+	var contents []string
+	for i := 0; i < 10; i++ {
+		content := fmt.Sprintf("https://%d.example", i)
+		contents = append(contents, content)
+		_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: content})
+		require.NoError(err)
+	}
+	for _, content := range contents {
+		select {
+		case c := <-changed:
+			require.Equal(content, c)
+		case <-time.After(5 * time.Second):
+			require.Fail("content change was not published", content)
+		}
+	}
+}
+
+func TestKiosk_window(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, driver, events := newTestKiosk(t, s)
+
+	// browser starts in fullscreen and its geometry is recorded
+	require.Eventually(func() bool {
+		state, err := s.Get(stateKey)
+		return err == nil && state.Geometry != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.False(state.Windowed)
+	require.True(state.Geometry.Fullscreen)
+
+	resp, err := request(events, api.KioskRequest{
+		Action:   api.ScreenActionUpdate,
+		SizeW:    800,
+		SizeH:    600,
+		Position: &api.Position{X: 100, Y: 50},
+	})
+	require.NoError(err)
+	require.False(resp.Fullscreen)
+	require.Equal(&api.WindowGeometry{X: 100, Y: 50, Width: 800, Height: 600}, resp.Geometry)
+	bounds, err := driver.Bounds(context.Background())
+	require.NoError(err)
+	require.Equal(browser.Bounds{Left: 100, Top: 50, Width: 800, Height: 600, State: browser.WindowStateNormal}, bounds)
+
+	fullscreen := true
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Fullscreen: &fullscreen})
+	require.NoError(err)
+	require.True(resp.Fullscreen)
+	require.True(resp.Geometry.Fullscreen)
+	// requested size is kept for switching out of fullscreen
+	require.Equal(800, resp.SizeW)
+
+	// failed window change is reported to the caller
+	driver.SetBoundsError(browser.ErrNotSupported)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, SizeW: 640, SizeH: 480})
+	require.Error(err)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.True(state.Geometry.Fullscreen)
+}
+
+func TestKiosk_restoreWindow(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, _, events := newTestKiosk(t, s)
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, SizeW: 800, SizeH: 600})
+	require.NoError(err)
+
+	// restarted kiosk applies persisted window to new browser
+	_, driver, _ := newTestKiosk(t, s)
+	require.Eventually(func() bool {
+		bounds, err := driver.Bounds(context.Background())
+		return err == nil && bounds.State == browser.WindowStateNormal && bounds.Width == 800
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKiosk_power(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, _, events := newTestKiosk(t, s)
+	monitor := k.monitor.(*fakeMonitor)
+
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionPowerOff})
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, resp.PowerState)
+	require.Equal(api.PowerStateOff, resp.DesiredPowerState)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, state.PowerState)
+
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOn})
+	require.NoError(err)
+	require.Equal(api.PowerStateOn, resp.PowerState)
+	require.Equal(api.PowerStateOn, resp.DesiredPowerState)
+	require.Equal([]bool{false, true}, monitor.Requests())
+
+	// power which can't be read is reported as unknown
+	monitor.set(api.PowerStateOn, nil, errors.New("display is not DPMS capable"))
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOff})
+	require.NoError(err)
+	require.Equal(api.PowerStateUnknown, resp.PowerState)
+	require.Equal(api.PowerStateOff, resp.DesiredPowerState)
+
+	// failed request keeps state and is reported to the caller
+	monitor.set(api.PowerStateOn, errors.New("unable to open display"), nil)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOn})
+	require.Error(err)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(api.PowerStateUnknown, state.PowerState)
+	require.Equal(api.PowerStateOff, state.DesiredPowerState)
+
+	entries, err := k.audit.Query(time.Time{}, time.Time{}, 1)
+	require.NoError(err)
+	require.Equal("poweron", entries[0].Action)
+	require.Equal(api.AuditResultError, entries[0].Result)
+}
+
+func TestKiosk_powerDrift(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, _, events := newTestKioskWithConfig(t, s, &config.Config{PowerCheckInterval: 10 * time.Millisecond})
+	monitor := k.monitor.(*fakeMonitor)
+
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionPowerOff})
+	require.NoError(err)
+
+	// screen turned on outside of kiosk is turned off again
+	monitor.set(api.PowerStateOn, nil, nil)
+	require.Eventually(func() bool {
+		requests := monitor.Requests()
+		return len(requests) >= 2 && !requests[len(requests)-1]
+	}, 5*time.Second, 10*time.Millisecond)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, state.PowerState)
+
+	// power which can't be read is recorded, but not corrected
+	count := len(monitor.Requests())
+	monitor.set(api.PowerStateOn, nil, errors.New("display is not DPMS capable"))
+	require.Eventually(func() bool {
+		state, err := s.Get(stateKey)
+		return err == nil && state.PowerState == api.PowerStateUnknown
+	}, 5*time.Second, 10*time.Millisecond)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, state.DesiredPowerState)
+	require.Len(monitor.Requests(), count)
+}
+
+func TestKiosk_screenshot(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKiosk(t, s)
+	require.NoError(driver.SetBounds(context.Background(), browser.Bounds{Width: 320, Height: 240}))
+
+	resp, err := request(events, api.KioskRequest{
+		Action:            api.ScreenActionScreenShot,
+		ScreenshotOptions: &api.ScreenshotOptions{Format: api.ScreenshotFormatPNG, Width: 100},
+	})
+	require.NoError(err)
+	img, err := png.Decode(bytes.NewReader(resp.Screenshot))
+	require.NoError(err)
+	require.LessOrEqual(img.Bounds().Dx(), 100)
+
+	// screenshots change neither state nor content
+	require.Equal([]string{defaultURL}, driver.Loads())
+	entries, err := k.audit.Query(time.Time{}, time.Time{}, 0)
+	require.NoError(err)
+	require.Empty(entries)
+}
+
+func TestKiosk_displays(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	c := &config.Config{MultiDisplay: true}
+	k0, driver0, events := newTestKioskWithConfig(t, s, c)
+	k1, driver1 := runTestKiosk(t, s, c, api.Display{ID: 1, Name: "HDMI-2", X: 1920, Width: 1280, Height: 1024}, events)
+
+	// each display has its own state, browser window is moved to its display
+	state, err := s.Get(store.StateKey(1))
+	require.NoError(err)
+	require.Equal(1, state.Display)
+	require.Equal(1280, state.SizeW)
+	require.Eventually(func() bool {
+		bounds, err := driver1.Bounds(context.Background())
+		return err == nil && bounds.Left == 1920 && bounds.State == browser.WindowStateFullscreen
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net", Display: 1})
+	require.NoError(err)
+	require.Equal(1, resp.Display)
+	require.Equal("https://synpse.net", resp.Content)
+	require.Eventually(func() bool {
+		return driver1.URL() == "https://synpse.net"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal([]string{defaultURL}, driver0.Loads())
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(defaultURL, state.Content)
+
+	// console messages are kept by kiosk of their display
+	driver1.Log(api.ConsoleMessage{Level: api.ConsoleLevelError, Source: api.ConsoleSourceException, Text: "TypeError"})
+	require.Eventually(func() bool {
+		messages := k1.console.List("", 0)
+		return len(messages) == 1 && messages[0].Display == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Empty(k0.console.List("", 0))
+
+	// window position is relative to display
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, SizeW: 800, SizeH: 600, Position: &api.Position{X: 10, Y: 20}, Display: 1})
+	require.NoError(err)
+	require.Equal(&api.WindowGeometry{X: 10, Y: 20, Width: 800, Height: 600}, resp.Geometry)
+	bounds, err := driver1.Bounds(context.Background())
+	require.NoError(err)
+	require.Equal(1930, bounds.Left)
+
+	// displays are powered separately
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOff, Display: 1})
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, resp.PowerState)
+	require.Equal([]bool{false}, k1.monitor.(*fakeMonitor).Requests())
+	require.Empty(k0.monitor.(*fakeMonitor).Requests())
+
+	// screenshots are routed by their display
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionScreenShot, ScreenshotOptions: &api.ScreenshotOptions{Display: 1}})
+	require.NoError(err)
+	require.NotEmpty(resp.Screenshot)
+
+	entries, err := k1.audit.Query(time.Time{}, time.Time{}, 1)
+	require.NoError(err)
+	require.Equal(1, entries[0].Display)
+}
+
+func TestKiosk_outputs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := &failingStore{MemoryStore: memory.New()}
+	rotated := api.OutputConfig{Name: "HDMI-1", Rotation: api.RotationLeft}
+	require.NoError(s.Persist(stateKey, api.KioskState{Content: defaultURL, OutputConfigs: []api.OutputConfig{rotated}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	events := eventer.New(ctx, logger.GetLoggerInstance("", zap.DebugLevel))
+	k, _ := runTestKiosk(t, s, &config.Config{}, api.Display{ID: 0, Name: "HDMI-1", Width: 1920, Height: 1080}, events)
+	screen := k.screen.(*fakeScreen)
+
+	// persisted outputs are configured before browser starts
+	require.Equal([]api.OutputConfig{rotated}, screen.Requests())
+
+	screen.set([]api.Output{
+		{Name: "HDMI-1", Enabled: true, Width: 1920, Height: 1080, Rotation: api.RotationNormal},
+		{Name: "HDMI-2", Width: 1280, Height: 1024},
+	})
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionGetOutputs})
+	require.NoError(err)
+	require.Len(resp.Outputs, 2)
+
+	// requested fields are merged with persisted config
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionSetOutput, Output: &api.OutputConfig{Name: "HDMI-1", Width: 1280, Height: 720}})
+	require.NoError(err)
+	expected := api.OutputConfig{Name: "HDMI-1", Width: 1280, Height: 720, Rotation: api.RotationLeft}
+	require.Equal(expected, screen.Requests()[1])
+	require.Equal([]api.OutputConfig{expected}, resp.OutputConfigs)
+	require.Equal(720, resp.Outputs[0].Width)
+	require.Equal(1280, k.bounds().Height)
+
+	// unsupported mode is not persisted
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetOutput, Output: &api.OutputConfig{Name: "HDMI-1", Width: 800, Height: 600}})
+	require.True(errors.Is(err, os.ErrInvalid))
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetOutput, Output: &api.OutputConfig{Name: "HDMI-1", Rotation: "upside"}})
+	require.True(errors.Is(err, os.ErrInvalid))
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetOutput})
+	require.True(errors.Is(err, os.ErrInvalid))
+
+	// disabled output gets its mode once it is turned on
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetOutput, Output: &api.OutputConfig{Name: "HDMI-2", Rotation: api.RotationRight}})
+	require.NoError(err)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal([]api.OutputConfig{expected, {Name: "HDMI-2", Rotation: api.RotationRight}}, state.OutputConfigs)
+
+	// config which would not be applied again after restart is reported as failure
+	atomic.StoreInt32(&s.failing, 1)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetOutput, Output: &api.OutputConfig{Name: "HDMI-2", Rotation: api.RotationLeft}})
+	require.Error(err)
+	atomic.StoreInt32(&s.failing, 0)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.Equal([]api.OutputConfig{expected, {Name: "HDMI-2", Rotation: api.RotationRight}}, state.OutputConfigs)
+
+	// reads are not audited
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionGetOutputs})
+	require.NoError(err)
+	entries, err := k.audit.Query(time.Time{}, time.Time{}, 1)
+	require.NoError(err)
+	require.Equal("setoutput", entries[0].Action)
+}
+
+// readBacklight returns raw brightness of fake backlight device
+func readBacklight(t *testing.T, dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "panel", "brightness"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestKiosk_brightness(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir := t.TempDir()
+	require.NoError(os.MkdirAll(filepath.Join(dir, "panel"), 0755))
+	require.NoError(os.WriteFile(filepath.Join(dir, "panel", "max_brightness"), []byte("200\n"), 0644))
+	require.NoError(os.WriteFile(filepath.Join(dir, "panel", "brightness"), []byte("200\n"), 0644))
+
+	s := &failingStore{MemoryStore: memory.New()}
+	level := 30
+	require.NoError(s.Persist(stateKey, api.KioskState{Content: defaultURL, Brightness: &level}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	events := eventer.New(ctx, logger.GetLoggerInstance("", zap.DebugLevel))
+	k, _ := runTestKiosk(t, s, &config.Config{BacklightDir: dir}, api.Display{ID: 0}, events)
+
+	// persisted brightness is restored on start
+	require.Eventually(func() bool { return readBacklight(t, dir) == "60" }, time.Second, 10*time.Millisecond)
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionGetBrightness})
+	require.NoError(err)
+	require.Equal(&api.Brightness{Level: 30, Device: "panel"}, resp.Brightness)
+
+	level = 70
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Level: &level}})
+	require.NoError(err)
+	require.Equal(70, resp.Brightness.Level)
+	require.Equal("140", readBacklight(t, dir))
+
+	// schedule takes over brightness set by hand
+	schedule := []api.BrightnessPoint{{Time: "22:00", Level: 10}, {Time: "06:00", Level: 90}}
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Schedule: schedule}})
+	require.NoError(err)
+	require.Equal([]api.BrightnessPoint{schedule[1], schedule[0]}, resp.BrightnessSchedule)
+	night := time.Now().Add(24 * time.Hour)
+	night = time.Date(night.Year(), night.Month(), night.Day(), 2, 0, 0, 0, time.Local)
+	k.checkBrightness(night)
+	require.Equal("100", readBacklight(t, dir))
+
+	// brightness set by hand lasts until the next point of schedule
+	level = 20
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Level: &level}})
+	require.NoError(err)
+	require.NotNil(resp.Brightness.OverrideUntil)
+	require.Equal("40", readBacklight(t, dir))
+	k.checkBrightness(time.Now())
+	require.Equal("40", readBacklight(t, dir))
+	k.checkBrightness(*resp.Brightness.OverrideUntil)
+	require.NotEqual("40", readBacklight(t, dir))
+
+	level = 101
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Level: &level}})
+	require.True(errors.Is(err, os.ErrInvalid))
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness})
+	require.True(errors.Is(err, os.ErrInvalid))
+	for _, invalid := range [][]api.BrightnessPoint{
+		{{Time: "25:00", Level: 10}},
+		{{Time: "07:00", Level: -1}},
+		{{Time: "07:00", Level: 10}, {Time: "07:00", Level: 20}},
+	} {
+		_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Schedule: invalid}})
+		require.True(errors.Is(err, os.ErrInvalid))
+	}
+
+	// empty schedule removes it
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Schedule: []api.BrightnessPoint{}}})
+	require.NoError(err)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Nil(state.BrightnessSchedule)
+
+	// brightness which would be lost on restart is reported as failure
+	atomic.StoreInt32(&s.failing, 1)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Schedule: schedule}})
+	require.Error(err)
+	atomic.StoreInt32(&s.failing, 0)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.Nil(state.BrightnessSchedule)
+}
+
+func TestScheduledBrightness(t *testing.T) {
+	t.Parallel()
+
+	schedule := []api.BrightnessPoint{{Time: "06:00", Level: 80}, {Time: "12:00", Level: 100}, {Time: "22:00", Level: 20}}
+	for _, tc := range []struct {
+		time  string
+		level int
+	}{
+		{time: "06:00", level: 80},
+		{time: "09:00", level: 90},
+		{time: "17:00", level: 60},
+		{time: "22:00", level: 20},
+		{time: "02:00", level: 50},
+		{time: "23:59", level: 35},
+	} {
+		tc := tc
+		t.Run(tc.time, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			now, err := time.Parse("15:04", tc.time)
+			require.NoError(err)
+			require.Equal(tc.level, scheduledBrightness(schedule, now))
+		})
+	}
+
+	now := time.Date(2022, 1, 1, 23, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2022, 1, 2, 6, 0, 0, 0, time.UTC), nextBrightnessPoint(schedule, now))
+}
+
+func TestPowerSchedule(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := parsePowerSchedule(&api.PowerSchedule{
+		Timezone: "Europe/Vilnius",
+		Windows: []api.PowerWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, On: "08:00", Off: "20:00"},
+			{Days: []string{"sat"}, On: "22:00", Off: "02:00"},
+		},
+		Exceptions: []api.PowerException{
+			{Date: "2022-06-23", On: "10:00", Off: "12:00"},
+			{Date: "2022-06-24"},
+		},
+	})
+	require.NoError(t, err)
+	vilnius, err := time.LoadLocation("Europe/Vilnius")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		now   string
+		power api.PowerState
+		next  string
+	}{
+		{name: "before window", now: "2022-06-20 07:59", power: api.PowerStateOff, next: "2022-06-20 08:00"},
+		{name: "in window", now: "2022-06-20 09:00", power: api.PowerStateOn, next: "2022-06-20 20:00"},
+		{name: "exception window", now: "2022-06-23 09:00", power: api.PowerStateOff, next: "2022-06-23 10:00"},
+		{name: "holiday", now: "2022-06-23 12:00", power: api.PowerStateOff, next: "2022-06-25 22:00"},
+		{name: "window after midnight", now: "2022-06-26 01:00", power: api.PowerStateOn, next: "2022-06-26 02:00"},
+		{name: "weekend", now: "2022-06-26 03:00", power: api.PowerStateOff, next: "2022-06-27 08:00"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			now, err := time.ParseInLocation("2006-01-02 15:04", tc.now, vilnius)
+			require.NoError(err)
+			require.Equal(tc.power, schedule.power(now.UTC()))
+
+			next, power := schedule.next(now.UTC())
+			require.Equal(tc.next, next.In(vilnius).Format("2006-01-02 15:04"))
+			require.NotEqual(tc.power, power)
+		})
+	}
+}
+
+func TestPowerSchedule_invalid(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// schedule without windows keeps screen off
+	schedule, err := parsePowerSchedule(&api.PowerSchedule{})
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, schedule.power(time.Now()))
+	next, _ := schedule.next(time.Now())
+	require.True(next.IsZero())
+
+	// exception is looked up beyond powerScheduleDays
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	schedule, err = parsePowerSchedule(&api.PowerSchedule{Timezone: "UTC", Exceptions: []api.PowerException{{Date: "2022-07-01", On: "10:00", Off: "12:00"}}})
+	require.NoError(err)
+	next, power := schedule.next(now)
+	require.Equal(time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC), next)
+	require.Equal(api.PowerStateOn, power)
+
+	for _, invalid := range []api.PowerSchedule{
+		{Timezone: "Mars/Olympus"},
+		{Windows: []api.PowerWindow{{Days: []string{"monday"}, On: "08:00", Off: "20:00"}}},
+		{Windows: []api.PowerWindow{{On: "08:00", Off: "20:00"}}},
+		{Windows: []api.PowerWindow{{Days: []string{"mon"}, On: "08:00", Off: "08:00"}}},
+		{Windows: []api.PowerWindow{{Days: []string{"mon"}, On: "8", Off: "20:00"}}},
+		{Exceptions: []api.PowerException{{Date: "24/06/2022"}}},
+		{Exceptions: []api.PowerException{{Date: "2022-06-24", On: "08:00"}}},
+	} {
+		_, err := parsePowerSchedule(&invalid)
+		require.True(errors.Is(err, os.ErrInvalid), "%+v", invalid)
+	}
+}
+
+func TestKiosk_powerScheduleNotPersisted(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := &failingStore{MemoryStore: memory.New()}
+	_, _, events := newTestKiosk(t, s)
+
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, PowerSchedule: &api.PowerSchedule{Windows: []api.PowerWindow{}}})
+	require.NoError(err)
+
+	// schedule and override which would be lost on restart are reported as failures
+	atomic.StoreInt32(&s.failing, 1)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule})
+	require.Error(err)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOn})
+	require.Error(err)
+
+	atomic.StoreInt32(&s.failing, 0)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.NotNil(state.PowerSchedule)
+	require.False(state.PowerOverride)
+}
+
+func TestKiosk_powerSchedule(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, _, events := newTestKiosk(t, s)
+	monitor := k.monitor.(*fakeMonitor)
+	ctx := context.Background()
+
+	// schedule without windows turns screen off
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, PowerSchedule: &api.PowerSchedule{Windows: []api.PowerWindow{}}})
+	require.NoError(err)
+	require.Equal(api.PowerStateOff.String(), resp.PowerSchedule.Scheduled)
+	k.checkPowerSchedule(ctx, time.Now())
+	require.Equal([]bool{false}, monitor.Requests())
+	entries, err := k.audit.Query(time.Time{}, time.Time{}, 1)
+	require.NoError(err)
+	require.Equal("poweroff", entries[0].Action)
+	require.Equal(powerScheduleIdentity, entries[0].Identity)
+
+	// power requested by hand overrides schedule without transitions until schedule changes
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOn})
+	require.NoError(err)
+	k.checkPowerSchedule(ctx, time.Now().Add(time.Hour))
+	require.Equal([]bool{false, true}, monitor.Requests())
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionGetPowerSchedule})
+	require.NoError(err)
+	require.True(resp.PowerSchedule.Override)
+	require.Nil(resp.PowerSchedule.OverrideUntil)
+
+	// screen is on for an hour around now every day
+	now := time.Now()
+	schedule := &api.PowerSchedule{Windows: []api.PowerWindow{{
+		Days: []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"},
+		On:   now.Add(-time.Hour).Format("15:04"),
+		Off:  now.Add(time.Hour).Format("15:04"),
+	}}}
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, PowerSchedule: schedule})
+	require.NoError(err)
+	require.False(resp.PowerSchedule.Override)
+	require.Equal(api.PowerStateOn.String(), resp.PowerSchedule.Scheduled)
+	require.Equal(api.PowerStateOff.String(), resp.PowerSchedule.NextPower)
+
+	// override expires at the next transition
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOff})
+	require.NoError(err)
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionGetPowerSchedule})
+	require.NoError(err)
+	require.True(resp.PowerSchedule.Override)
+	require.Equal(resp.PowerSchedule.Next, resp.PowerSchedule.OverrideUntil)
+	k.checkPowerSchedule(ctx, now)
+	require.Equal([]bool{false, true, false}, monitor.Requests())
+	k.checkPowerSchedule(ctx, *resp.PowerSchedule.OverrideUntil)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.False(state.PowerOverride)
+
+	// caller identity does not make request scheduled
+	_, err = events.Emit(&eventer.EventWrapper{
+		Payload: api.Event{
+			Request: api.KioskRequest{Action: api.ScreenActionPowerOff},
+			Caller:  api.Caller{Identity: powerScheduleIdentity},
+		},
+	})
+	require.NoError(err)
+	state, err = s.Get(stateKey)
+	require.NoError(err)
+	require.True(state.PowerOverride)
+
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, PowerSchedule: &api.PowerSchedule{Timezone: "Mars/Olympus"}})
+	require.True(errors.Is(err, os.ErrInvalid))
+
+	// removed schedule does not request power
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule})
+	require.NoError(err)
+	k.checkPowerSchedule(ctx, now)
+	require.Equal([]bool{false, true, false, false}, monitor.Requests())
+}
+
+func TestKiosk_eval(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKiosk(t, s)
+	driver.SetEval(func(ctx context.Context, expression string) (json.RawMessage, error) {
+		switch expression {
+		case "document.title":
+			return json.RawMessage(`"UniKiosk"`), nil
+		case "while(true){}":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		default:
+			return nil, &browser.Exception{Message: "ReferenceError: foo is not defined"}
+		}
+	})
+
+	resp, err := request(events, api.KioskRequest{
+		Action:      api.ScreenActionEval,
+		EvalOptions: &api.EvalOptions{Expression: "document.title"},
+	})
+	require.NoError(err)
+	require.Equal(&api.EvalResult{Result: json.RawMessage(`"UniKiosk"`)}, resp.Eval)
+
+	// exception is a result, not a failure
+	resp, err = request(events, api.KioskRequest{
+		Action:      api.ScreenActionEval,
+		EvalOptions: &api.EvalOptions{Expression: "foo()"},
+	})
+	require.NoError(err)
+	require.Equal("ReferenceError: foo is not defined", resp.Eval.Exception)
+
+	_, err = request(events, api.KioskRequest{
+		Action:      api.ScreenActionEval,
+		EvalOptions: &api.EvalOptions{Expression: "while(true){}", Timeout: 50 * time.Millisecond},
+	})
+	require.Error(err)
+	require.ErrorIs(err, context.DeadlineExceeded)
+
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionEval})
+	require.ErrorIs(err, os.ErrInvalid)
+
+	entries, err := k.audit.Query(time.Time{}, time.Time{}, 0)
+	require.NoError(err)
+	require.Len(entries, 4)
+	require.Equal("eval", entries[0].Action)
+}
+
+func TestKiosk_console(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKiosk(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := events.Subscribe(ctx)
+
+	driver.Log(api.ConsoleMessage{Level: api.ConsoleLevelInfo, Source: api.ConsoleSourceConsole, Text: "loaded"})
+	driver.Log(api.ConsoleMessage{Level: api.ConsoleLevelError, Source: api.ConsoleSourceException, Text: "TypeError: x is undefined"})
+
+	require.Eventually(func() bool {
+		return len(k.console.List("", 0)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// only exceptions are published
+	select {
+	case event := <-listener:
+		require.Equal(api.EventTypeBrowserException, event.Payload.Type)
+		require.Equal("TypeError: x is undefined", event.Payload.Console.Text)
+		require.Equal(defaultURL, event.Payload.Response.Content)
+	case <-time.After(5 * time.Second):
+		require.Fail("browser exception was not published")
+	}
+}
+
+func TestKiosk_slowLoad(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKiosk(t, s)
+	driver.SetLoadDelay(500 * time.Millisecond)
+
+	// request is answered before content loads, other requests are not blocked by the load
+	started := time.Now()
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net"})
+	require.NoError(err)
+	require.Nil(resp.LastLoad)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Title: "Synpse"})
+	require.NoError(err)
+	require.Less(time.Since(started), 500*time.Millisecond)
+	// previous content loaded, but requested one is not loaded yet
+	_, err = k.CheckPageLoad(context.Background())
+	require.Error(err)
+
+	result := waitLoad(t, s, "https://synpse.net")
+	require.False(result.Failed())
+	require.Equal([]string{defaultURL, "https://synpse.net"}, driver.Loads())
+
+	last, ok := k.lastLoad.Load().(time.Time)
+	require.True(ok)
+	require.True(last.After(started))
+	_, err = k.CheckPageLoad(context.Background())
+	require.NoError(err)
+}
+
+func TestKiosk_loadFallback(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKioskWithConfig(t, s, &config.Config{
+		PageLoadRetry:    20 * time.Millisecond,
+		PageLoadRetryMax: 50 * time.Millisecond,
+	})
+
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net"})
+	require.NoError(err)
+	result := waitLoad(t, s, "https://synpse.net")
+	require.False(result.Failed())
+	require.Equal(200, result.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := events.Subscribe(ctx)
+
+	// retries probe content before browser loads it again
+	var status int32 = http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	// failed content is replaced by last good content
+	driver.SetStatus(server.URL, 500)
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: server.URL})
+	require.NoError(err)
+	require.Equal(server.URL, resp.Content)
+	result = waitLoad(t, s, server.URL)
+	require.True(result.Failed())
+	require.Equal(500, result.Status)
+	require.Equal("https://synpse.net", result.Fallback)
+	require.Equal("https://synpse.net", driver.URL())
+	_, err = k.CheckPageLoad(context.Background())
+	require.Error(err)
+
+	waitEvent(t, listener, api.EventTypeLoadFailed)
+
+	// content is retried while it fails and fallback is kept on the screen
+	require.Eventually(func() bool {
+		state, err := s.Get(stateKey)
+		return err == nil && state.LastLoad.Retries >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal("https://synpse.net", driver.URL())
+	require.Equal([]string{defaultURL, "https://synpse.net", server.URL, "https://synpse.net"}, driver.Loads())
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	driver.SetStatus(server.URL, 200)
+	event := waitEvent(t, listener, api.EventTypeLoadRecovered)
+	require.False(event.Response.LastLoad.Failed())
+	require.GreaterOrEqual(event.Response.LastLoad.Retries, 2)
+	require.Equal(server.URL, driver.URL())
+	_, err = k.CheckPageLoad(context.Background())
+	require.NoError(err)
+}
+
+func TestKiosk_loadErrorPage(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, driver, events := newTestKioskWithConfig(t, s, &config.Config{PageLoadFallback: fallbackErrorPage})
+
+	driver.SetStatus("https://unikiosk.io", 502)
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://unikiosk.io"})
+	require.NoError(err)
+	result := waitLoad(t, s, "https://unikiosk.io")
+	require.True(strings.HasPrefix(driver.URL(), defaultURL+api.ErrorPagePath+"?"))
+	require.Contains(driver.URL(), "url=https%3A%2F%2Funikiosk.io")
+	require.Equal(driver.URL(), result.Fallback)
+
+	// browser is restarted with fallback while content fails
+	driver.Crash(errors.New("segmentation fault"))
+	require.Eventually(func() bool {
+		return driver.Starts() == 2 && driver.Running()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(result.Fallback, driver.URL())
+}
+
+func TestKiosk_allowlist(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, driver, events := newTestKiosk(t, s)
+
+	resp, err := request(events, api.KioskRequest{
+		Action:    api.ScreenActionUpdate,
+		Content:   "https://synpse.net",
+		Allowlist: []string{"https://synpse.net/docs/*"},
+	})
+	require.NoError(err)
+	require.Equal([]string{"https://synpse.net/docs/*"}, resp.Allowlist)
+	waitLoad(t, s, "https://synpse.net")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := events.Subscribe(ctx)
+
+	// content, allowed pages and web server pages can be opened
+	for _, url := range []string{"https://synpse.net/", "https://synpse.net/docs/install", defaultURL + api.ErrorPagePath} {
+		driver.Navigate(url)
+		require.Equal(url, driver.URL())
+	}
+
+	// navigation outside of allowlist takes page back to content
+	driver.Navigate("https://google.com")
+	event := waitEvent(t, listener, api.EventTypeNavigationBlocked)
+	require.Equal("https://google.com", event.Response.LastBlocked)
+	require.Eventually(func() bool {
+		return driver.URL() == "https://synpse.net"
+	}, 5*time.Second, 10*time.Millisecond)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(1, state.BlockedNavigations)
+	require.False(state.LastBlockedTime.IsZero())
+
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Allowlist: []string{"google.com"}})
+	require.Error(err)
+
+	// new content without allowlist allows all navigations
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://unikiosk.io"})
+	require.NoError(err)
+	require.Empty(resp.Allowlist)
+	waitLoad(t, s, "https://unikiosk.io")
+	driver.Navigate("https://google.com")
+	require.Equal("https://google.com", driver.URL())
+}
+
+func TestKiosk_idle(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, driver, events := newTestKioskWithConfig(t, s, &config.Config{
+		IdleTimeout:   100 * time.Millisecond,
+		IdleSource:    idleSourcePage,
+		IdleClearData: true,
+	})
+
+	// page reports time since last input, or since navigation started when there was none
+	var input atomic.Value
+	input.Store(time.Now().Add(-time.Second))
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net/docs"})
+	require.NoError(err)
+	waitLoad(t, s, "https://synpse.net/docs")
+	driver.SetEval(func(ctx context.Context, expression string) (json.RawMessage, error) {
+		require.Equal(pageIdle, expression)
+		return json.RawMessage(fmt.Sprint(time.Since(input.Load().(time.Time)).Milliseconds())), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := events.Subscribe(ctx)
+
+	// page without input since content loaded stays
+	time.Sleep(300 * time.Millisecond)
+	require.Empty(driver.Cleared())
+
+	driver.Navigate("https://google.com")
+	input.Store(time.Now())
+	event := waitEvent(t, listener, api.EventTypeIdleTimeout)
+	require.Equal("https://synpse.net/docs", event.Response.Content)
+	require.Eventually(func() bool {
+		return driver.URL() == "https://synpse.net/docs"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal([]api.ClearOptions{{Cookies: true, Storage: true, Cache: true, Origins: []string{"https://synpse.net"}}}, driver.Cleared())
+
+	// content is loaded once per idle period
+	loads := len(driver.Loads())
+	time.Sleep(300 * time.Millisecond)
+	require.Len(driver.Loads(), loads)
+}
+
+func TestKiosk_cookies(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, driver, events := newTestKiosk(t, s)
+
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net/docs"})
+	require.NoError(err)
+
+	_, err = request(events, api.KioskRequest{
+		Action: api.ScreenActionSetCookies,
+		Cookies: []api.Cookie{
+			{Name: "session", Value: "1", Domain: ".synpse.net"},
+			{Name: "session", Value: "2", Domain: "cloud.synpse.net"},
+			{Name: "consent", Value: "yes", Domain: "google.com"},
+		},
+	})
+	require.NoError(err)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionSetCookies, Cookies: []api.Cookie{{Name: "session"}}})
+	require.ErrorIs(err, os.ErrInvalid)
+
+	// domain selects cookies of subdomains too
+	resp, err := request(events, api.KioskRequest{Action: api.ScreenActionGetCookies, CookieOptions: &api.CookieOptions{Domain: "synpse.net"}})
+	require.NoError(err)
+	require.Len(resp.Cookies, 2)
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionGetCookies})
+	require.NoError(err)
+	require.Len(resp.Cookies, 3)
+
+	resp, err = request(events, api.KioskRequest{Action: api.ScreenActionDeleteCookies, CookieOptions: &api.CookieOptions{Domain: "cloud.synpse.net", Name: "session"}})
+	require.NoError(err)
+	require.Equal([]api.Cookie{{Name: "session", Value: "2", Domain: "cloud.synpse.net", Path: "/"}}, resp.Cookies)
+	remaining, err := driver.Cookies(context.Background())
+	require.NoError(err)
+	require.Len(remaining, 2)
+
+	// storage of content is cleared together with storage of requested origins
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionClearData, ClearOptions: &api.ClearOptions{}})
+	require.ErrorIs(err, os.ErrInvalid)
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionClearData})
+	require.ErrorIs(err, os.ErrInvalid)
+	_, err = request(events, api.KioskRequest{
+		Action:       api.ScreenActionClearData,
+		ClearOptions: &api.ClearOptions{Cookies: true, Storage: true, Origins: []string{"https://google.com"}},
+	})
+	require.NoError(err)
+	require.Equal([]api.ClearOptions{{Cookies: true, Storage: true, Origins: []string{"https://google.com", "https://synpse.net"}}}, driver.Cleared())
+	remaining, err = driver.Cookies(context.Background())
+	require.NoError(err)
+	require.Empty(remaining)
+}
+
+func TestKiosk_crash(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, events := newTestKiosk(t, s)
+
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net"})
+	require.NoError(err)
+	waitLoad(t, s, "https://synpse.net")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := events.Subscribe(ctx)
+
+	driver.Crash(errors.New("segmentation fault"))
+
+	// browser is restarted with persisted content
+	require.Eventually(func() bool {
+		return driver.Starts() == 2 && driver.Running() && driver.URL() == "https://synpse.net"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(k.CheckBrowser(context.Background()))
+	state, err := k.store.Get(stateKey)
+	require.NoError(err)
+	require.Equal(1, state.Restarts)
+	require.Equal("segmentation fault", state.RestartReason)
+	require.False(state.LastRestart.IsZero())
+
+	event := waitEvent(t, listener, api.EventTypeBrowserRestarted)
+	require.Equal("https://synpse.net", event.Response.Content)
+}
+
+func TestKiosk_watchdog(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, _ := newTestKioskWithConfig(t, s, &config.Config{
+		BrowserWatchdogInterval: 10 * time.Millisecond,
+		BrowserWatchdogTimeout:  10 * time.Millisecond,
+		BrowserWatchdogFailures: 2,
+	})
+
+	// page stops responding and browser is restarted
+	var hung int32 = 1
+	driver.SetEval(func(ctx context.Context, expression string) (json.RawMessage, error) {
+		if atomic.LoadInt32(&hung) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return json.RawMessage("1"), nil
+	})
+	require.Eventually(func() bool {
+		return driver.Starts() >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	atomic.StoreInt32(&hung, 0)
+
+	require.Eventually(func() bool {
+		return k.CheckBrowser(context.Background()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.GreaterOrEqual(state.Restarts, 1)
+	require.Equal(errUnresponsive.Error(), state.RestartReason)
+}
+
+func TestKiosk_watchdogDefaultFailures(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// unset failures do not restart browser on the first failed check
+	k, driver, _ := newTestKioskWithConfig(t, memory.New(), &config.Config{
+		BrowserWatchdogInterval: 10 * time.Millisecond,
+		BrowserWatchdogTimeout:  10 * time.Millisecond,
+	})
+	var pings int32
+	driver.SetEval(func(ctx context.Context, expression string) (json.RawMessage, error) {
+		if atomic.AddInt32(&pings, 1) == 1 {
+			return nil, errors.New("page crashed")
+		}
+		return json.RawMessage("1"), nil
+	})
+	require.Eventually(func() bool {
+		return atomic.LoadInt32(&pings) >= 5
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(1, driver.Starts())
+	require.NoError(k.CheckBrowser(context.Background()))
+}
+
+func TestKiosk_checkBrowser(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var hung int32 = 1
+	eval := func(ctx context.Context, expression string) (json.RawMessage, error) {
+		if atomic.LoadInt32(&hung) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return json.RawMessage("1"), nil
+	}
+
+	// result of the last watchdog check is reported while browser is not restarted yet
+	k, driver, _ := newTestKioskWithConfig(t, memory.New(), &config.Config{
+		BrowserWatchdogInterval: 10 * time.Millisecond,
+		BrowserWatchdogTimeout:  10 * time.Millisecond,
+		BrowserWatchdogFailures: 1000,
+	})
+	driver.SetEval(eval)
+	require.Eventually(func() bool {
+		err := k.CheckBrowser(context.Background())
+		return err != nil && strings.Contains(err.Error(), "not responding")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// without watchdog page is checked right away
+	unwatched, driver, _ := newTestKiosk(t, memory.New())
+	driver.SetEval(eval)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Error(unwatched.CheckBrowser(ctx))
+
+	atomic.StoreInt32(&hung, 0)
+	require.NoError(unwatched.CheckBrowser(context.Background()))
+	require.Eventually(func() bool {
+		return k.CheckBrowser(context.Background()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKiosk_crashLoop(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	k, driver, _ := newTestKioskWithConfig(t, s, &config.Config{
+		BrowserCrashLoopRestarts: 3,
+		BrowserCrashLoopPause:    time.Hour,
+	})
+
+	// browser fails to start again after crash
+	driver.SetStartError(errors.New("no display"))
+	driver.Crash(errors.New("segmentation fault"))
+
+	require.Eventually(func() bool {
+		err := k.CheckBrowser(context.Background())
+		return err != nil && strings.Contains(err.Error(), "paused")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(1, driver.Starts())
+
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal(2, state.Restarts)
+	require.Contains(state.RestartReason, "no display")
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	b := &backoff{initial: time.Second, max: 4 * time.Second, limit: 5, pause: time.Hour}
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		delay, paused := b.next(0)
+		require.False(paused)
+		delays = append(delays, delay)
+	}
+	require.Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}, delays)
+
+	// crash loop pauses restarts
+	delay, paused := b.next(0)
+	require.True(paused)
+	require.Equal(time.Hour, delay)
+
+	// stable run resets backoff
+	b.next(0)
+	b.next(0)
+	delay, paused = b.next(time.Minute)
+	require.False(paused)
+	require.Equal(time.Second, delay)
+}
+
+func TestKiosk_persistence(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s := memory.New()
+	_, _, events := newTestKiosk(t, s)
+
+	_, err := request(events, api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net", Title: "Synpse"})
+	require.NoError(err)
+	waitLoad(t, s, "https://synpse.net")
+	_, err = request(events, api.KioskRequest{Action: api.ScreenActionPowerOff})
+	require.NoError(err)
+
+	// kiosk started with existing state does not reset it and shows persisted content
+	_, driver, _ := newTestKiosk(t, s)
+	require.Equal("https://synpse.net", driver.URL())
+
+	state, err := s.Get(stateKey)
+	require.NoError(err)
+	require.Equal("https://synpse.net", state.Content)
+	require.Equal("Synpse", state.Title)
+	require.Equal(api.PowerStateOff, state.PowerState)
+}
@@ -0,0 +1,344 @@
+package kiosk
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+)
+
+var (
+	// fallback options shown while content fails to load
+	fallbackLastGood  = "last_good"
+	fallbackErrorPage = "error_page"
+	fallbackNone      = "none"
+
+	defaultLoadTimeout  = 30 * time.Second
+	defaultLoadRetryMax = 5 * time.Minute
+
+	// blankPage is shown by browser on start until content loads
+	blankPage = "about:blank"
+)
+
+// validFallback reports if fallback is known option. Empty fallback defaults to last_good
+func validFallback(fallback string) bool {
+	switch fallback {
+	case "", fallbackLastGood, fallbackErrorPage, fallbackNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// startLoad loads content in background, replacing load and retries of content requested before. Outcome
+// is recorded in state once content loads or fails, so requests are answered without waiting for slow
+// content. Caller holds loadMu
+func (k *kiosk) startLoad(ctx context.Context, content string) {
+	k.stopRetry()
+	if k.cancelLoad != nil {
+		k.cancelLoad()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	k.cancelLoad = cancel
+	go func() {
+		defer recover.Panic(k.log)
+		k.loadAndRecord(ctx, content)
+	}()
+}
+
+// loadAndRecord loads content, shows fallback when it fails and records the outcome in state. Outcome is
+// dropped when load was replaced meanwhile. It reports if content loaded
+func (k *kiosk) loadAndRecord(ctx context.Context, content string) bool {
+	// navigation of replaced load finishes before the next one starts
+	k.navigateMu.Lock()
+	result := k.load(ctx, content)
+	if result.Failed() && ctx.Err() == nil {
+		result.Fallback = k.showFallback(ctx, result)
+	}
+	k.navigateMu.Unlock()
+
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+	if ctx.Err() != nil {
+		return false
+	}
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return false
+	}
+	if state.Content != content {
+		return false
+	}
+
+	// content which failed before is retried, so its outcome is retry outcome
+	previous := state.LastLoad
+	retried := previous.Failed() && previous.URL == content
+	state.LastLoad = result
+	var event api.EventType
+	if !result.Failed() {
+		state.LastGoodContent = content
+		k.lastLoad.Store(time.Now())
+		k.stopRetry()
+		if retried {
+			k.log.Info("content recovered", zap.String("content", content), zap.Int("retries", previous.Retries))
+			result.Retries = previous.Retries
+			event = api.EventTypeLoadRecovered
+		}
+	} else {
+		if retried {
+			result.Retries = previous.Retries + 1
+			// fallback is kept when it can't be shown now, e.g. while browser restarts
+			if result.Fallback == "" {
+				result.Fallback = previous.Fallback
+			}
+		} else {
+			event = api.EventTypeLoadFailed
+		}
+		k.scheduleRetry(ctx, content)
+	}
+
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+	if event != "" {
+		k.publish(event, state)
+	}
+	return !result.Failed()
+}
+
+// load navigates browser to content and returns outcome of the load. HTTP error statuses are
+// reported as failure, as the page does not show content then
+func (k *kiosk) load(ctx context.Context, content string) *api.LoadResult {
+	ctx, cancel := context.WithTimeout(ctx, durationOr(k.config.PageLoadTimeout, defaultLoadTimeout))
+	defer cancel()
+
+	result := &api.LoadResult{URL: content, Time: time.Now()}
+	loaded, err := k.navigate(ctx, content)
+	result.Status = loaded.Status
+	result.Duration = loaded.Duration
+	if err == nil && loaded.Status >= http.StatusBadRequest {
+		err = fmt.Errorf("server responded with %d %s", loaded.Status, http.StatusText(loaded.Status))
+	}
+	if err != nil {
+		k.log.Error("failed to load content", zap.String("content", content), zap.Int("status", loaded.Status), zap.Error(err))
+		result.Error = err.Error()
+		return result
+	}
+
+	k.log.Info("content loaded", zap.String("content", content), zap.Int("status", loaded.Status), zap.Duration("duration", loaded.Duration))
+	return result
+}
+
+// showFallback loads fallback content configured by PageLoadFallback in place of content which failed to load
+// and returns it. Empty string is returned when fallback is disabled or can't be shown
+func (k *kiosk) showFallback(ctx context.Context, result *api.LoadResult) string {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return ""
+	}
+	content := k.fallbackContent(state.LastGoodContent, result)
+	if content == "" {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, durationOr(k.config.PageLoadTimeout, defaultLoadTimeout))
+	defer cancel()
+	_, err = k.navigate(ctx, content)
+	if err != nil {
+		k.log.Error("failed to load fallback content", zap.String("fallback", content), zap.Error(err))
+		return ""
+	}
+	k.log.Info("showing fallback content", zap.String("content", result.URL), zap.String("fallback", content))
+	return content
+}
+
+// fallbackContent returns content shown while content of failed load is retried, empty when fallback is disabled
+func (k *kiosk) fallbackContent(lastGood string, result *api.LoadResult) string {
+	switch k.config.PageLoadFallback {
+	case fallbackNone:
+		return ""
+	case fallbackErrorPage:
+	default:
+		if lastGood != "" && lastGood != result.URL {
+			return lastGood
+		}
+	}
+	return errorPageURL(k.config.DefaultWebServerURL, result)
+}
+
+// errorPageURL returns url of web server error page describing failed load
+func errorPageURL(server string, result *api.LoadResult) string {
+	q := url.Values{}
+	q.Set("url", result.URL)
+	q.Set("error", result.Error)
+	return strings.TrimSuffix(server, "/") + api.ErrorPagePath + "?" + q.Encode()
+}
+
+// scheduleRetry starts loading content again until it loads, replacing retries of other content. Caller holds loadMu
+func (k *kiosk) scheduleRetry(ctx context.Context, content string) {
+	if k.config.PageLoadRetry <= 0 || k.retrying == content {
+		return
+	}
+	k.stopRetry()
+
+	ctx, cancel := context.WithCancel(ctx)
+	k.retrying = content
+	k.cancelRetry = cancel
+	go k.retry(ctx, content)
+}
+
+// stopRetry stops retries of failed content. Caller holds loadMu
+func (k *kiosk) stopRetry() {
+	if k.cancelRetry != nil {
+		k.cancelRetry()
+	}
+	k.retrying = ""
+	k.cancelRetry = nil
+}
+
+// retry loads content with growing delay until it loads or other content is requested
+func (k *kiosk) retry(ctx context.Context, content string) {
+	defer recover.Panic(k.log)
+
+	delay := k.config.PageLoadRetry
+	max := durationOr(k.config.PageLoadRetryMax, defaultLoadRetryMax)
+	for {
+		if delay > max {
+			delay = max
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if k.retryLoad(ctx, content) {
+			return
+		}
+		delay *= 2
+	}
+}
+
+// retryLoad loads content again once it responds and reports if retries are finished. Fallback stays on
+// the screen until then
+func (k *kiosk) retryLoad(ctx context.Context, content string) bool {
+	err := k.probe(ctx, content)
+	if ctx.Err() != nil {
+		return true
+	}
+	if err != nil {
+		k.log.Debug("content does not respond yet", zap.String("content", content), zap.Error(err))
+		k.recordRetry(ctx, content, err)
+		return false
+	}
+	return k.loadAndRecord(ctx, content)
+}
+
+// recordRetry counts failed retry of content in state
+func (k *kiosk) recordRetry(ctx context.Context, content string, err error) {
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+	// retry was replaced while it waited for the lock
+	if ctx.Err() != nil {
+		return
+	}
+
+	state, getErr := k.store.Get(k.stateKey)
+	if getErr != nil {
+		k.log.Warn("failed to get state", zap.Error(getErr))
+		return
+	}
+	if state.Content != content || !state.LastLoad.Failed() {
+		return
+	}
+	// result is shared with stored state, so it is copied
+	last := *state.LastLoad
+	last.Retries++
+	last.Error = err.Error()
+	state.LastLoad = &last
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+}
+
+// probe requests content through the proxy, the same way browser loads it, and returns error unless it
+// responds with success status. Content which is not loaded over http is not probed
+func (k *kiosk) probe(ctx context.Context, content string) error {
+	u, err := url.Parse(content)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, durationOr(k.config.PageLoadTimeout, defaultLoadTimeout))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, content, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.probeClient().Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	// servers which do not support HEAD respond, they are checked by the load itself
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+		return fmt.Errorf("server responded with %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
+
+// probeClient returns http client which sends requests through the proxy browser uses. Proxy certificate
+// is trusted, as https proxy terminates TLS
+func (k *kiosk) probeClient() *http.Client {
+	proxies := map[string]*url.URL{
+		"http":  proxyURL(k.config.ProxyHTTPServerAddr),
+		"https": proxyURL(k.config.ProxyHTTPSServerAddr),
+	}
+	transport := &http.Transport{
+		Proxy: func(r *http.Request) (*url.URL, error) {
+			return proxies[r.URL.Scheme], nil
+		},
+	}
+	if ca, err := os.ReadFile(k.config.ProxyHTTPSCertLocation); err == nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM(ca)
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{
+		Transport: transport,
+		// redirect is response of the server
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// proxyURL returns url of proxy listening on addr, nil when proxy address is not set
+func proxyURL(addr string) *url.URL {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return &url.URL{Scheme: "http", Host: net.JoinHostPort(host, port)}
+}
@@ -0,0 +1,95 @@
+package kiosk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/allowlist"
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/browser"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+)
+
+// navigate loads url in the browser. Navigations made by kiosk itself are not checked against allowlist
+func (k *kiosk) navigate(ctx context.Context, url string) (browser.LoadResult, error) {
+	atomic.StoreInt32(&k.navigating, 1)
+	defer atomic.StoreInt32(&k.navigating, 0)
+	return k.driver.Load(ctx, url)
+}
+
+// allowNavigation reports if page can navigate to url
+func (k *kiosk) allowNavigation(url string) bool {
+	if atomic.LoadInt32(&k.navigating) == 1 {
+		return true
+	}
+	list, _ := k.allowlist.Load().(*allowlist.List)
+	return list == nil || list.Allows(url)
+}
+
+// applyAllowlist makes browser block navigations outside of allowlist of state. Content itself
+// and pages served by the web server are always allowed
+func (k *kiosk) applyAllowlist(ctx context.Context, state *api.KioskState) error {
+	if len(state.Allowlist) == 0 {
+		k.allowlist.Store((*allowlist.List)(nil))
+		return k.driver.SetNavigationPolicy(ctx, nil)
+	}
+
+	patterns := append([]string{strings.TrimSuffix(k.config.DefaultWebServerURL, "/") + "/*"}, state.Allowlist...)
+	// local files are loaded by kiosk only, they can't be matched by pattern
+	if allowlist.Validate(state.Content) == nil {
+		patterns = append(patterns, state.Content)
+	}
+	list, err := allowlist.New(patterns)
+	if err != nil {
+		return err
+	}
+	k.allowlist.Store(list)
+	err = k.driver.SetNavigationPolicy(ctx, k.allowNavigation)
+	if err != nil {
+		return fmt.Errorf("failed to apply navigation allowlist: %w", err)
+	}
+	return nil
+}
+
+// runNavigation takes page back to content when navigation outside of allowlist is blocked
+func (k *kiosk) runNavigation(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case url := <-k.driver.BlockedNavigations():
+			k.navigationBlocked(ctx, url)
+		}
+	}
+}
+
+// navigationBlocked counts blocked navigation and loads content again
+func (k *kiosk) navigationBlocked(ctx context.Context, url string) {
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	k.log.Warn("navigation outside of allowlist blocked", zap.String("url", url), zap.String("content", state.Content))
+
+	state.BlockedNavigations++
+	state.LastBlocked = url
+	state.LastBlockedTime = time.Now()
+	k.startLoad(ctx, state.Content)
+
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+	k.publish(api.EventTypeNavigationBlocked, state)
+}
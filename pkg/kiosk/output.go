@@ -0,0 +1,224 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/util/x11"
+)
+
+// outputsMu serializes output changes of kiosks sharing the screen, as each change resizes the screen
+var outputsMu sync.Mutex
+
+// screen lists and configures video outputs
+type screen interface {
+	// Outputs returns connected outputs
+	Outputs() ([]api.Output, error)
+	// SetOutput changes resolution, refresh rate and rotation of output. x11.ErrOutputDisabled is
+	// returned when output is turned off
+	SetOutput(config api.OutputConfig) error
+}
+
+// x11Screen configures outputs over RandR extension of X11 display. Display is connected for each call
+type x11Screen struct {
+	display string
+}
+
+func newX11Screen(display string) *x11Screen {
+	return &x11Screen{display: xDisplay(display)}
+}
+
+func (s *x11Screen) Outputs() ([]api.Output, error) {
+	conn, err := x11.ConnectDisplay(s.display)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	list, err := conn.Outputs()
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]api.Output, 0, len(list))
+	for _, o := range list {
+		out := api.Output{
+			Name:     o.Name,
+			Enabled:  o.Enabled,
+			X:        o.X,
+			Y:        o.Y,
+			Width:    o.Width,
+			Height:   o.Height,
+			Rotation: string(o.Rotation),
+			Rate:     o.Rate,
+			Modes:    make([]api.OutputMode, 0, len(o.Modes)),
+		}
+		for _, m := range o.Modes {
+			out.Modes = append(out.Modes, api.OutputMode{Width: m.Width, Height: m.Height, Rate: m.Rate, Preferred: m.Preferred})
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+func (s *x11Screen) SetOutput(config api.OutputConfig) error {
+	conn, err := x11.ConnectDisplay(s.display)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.SetOutputMode(config.Name, x11.OutputMode{
+		Width:    config.Width,
+		Height:   config.Height,
+		Rate:     config.Rate,
+		Rotation: x11.Rotation(config.Rotation),
+	})
+}
+
+// setOutput changes mode and rotation of output and persists it in state, so it is applied again before
+// browser starts. Output which is turned off gets the mode once it is turned on
+func (k *kiosk) setOutput(ctx context.Context, in api.OutputConfig) error {
+	if in.Name == "" {
+		return fmt.Errorf("output name is required: %w", os.ErrInvalid)
+	}
+	if !api.ValidRotation(in.Rotation) {
+		return fmt.Errorf("unknown rotation %q: %w", in.Rotation, os.ErrInvalid)
+	}
+
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return err
+	}
+	config := mergeOutputConfig(state.OutputConfigs, in)
+	k.log.Info("configure output", zap.Any("output", config))
+
+	err = k.applyOutput(config)
+	if errors.Is(err, x11.ErrOutputDisabled) {
+		k.log.Info("output is turned off, mode is applied once it is on", zap.String("output", config.Name))
+	} else if err != nil {
+		return err
+	}
+
+	state.OutputConfigs = replaceOutputConfig(state.OutputConfigs, config)
+	// output is configured already, so window is placed on resized display even when config is not persisted
+	persistErr := k.store.Persist(k.stateKey, *state)
+
+	// fullscreen window covers the whole display only once it is placed on it again
+	if k.refreshDisplay() && k.config.MultiDisplay && atomic.LoadInt32(&k.running) == 1 {
+		err = k.applyWindow(ctx, state)
+		if err != nil {
+			k.log.Warn("failed to place window on resized display", zap.Error(err))
+		}
+	}
+	if persistErr != nil {
+		return fmt.Errorf("persist output config: %w", persistErr)
+	}
+	return nil
+}
+
+// restoreOutputs applies persisted output modes and rotations
+func (k *kiosk) restoreOutputs() {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+	if len(state.OutputConfigs) == 0 {
+		return
+	}
+	for _, config := range state.OutputConfigs {
+		err := k.applyOutput(config)
+		if err != nil && !errors.Is(err, x11.ErrOutputDisabled) {
+			k.log.Warn("failed to restore output", zap.String("output", config.Name), zap.Error(err))
+		}
+	}
+	k.refreshDisplay()
+}
+
+func (k *kiosk) applyOutput(config api.OutputConfig) error {
+	outputsMu.Lock()
+	defer outputsMu.Unlock()
+	return k.screen.SetOutput(config)
+}
+
+// refreshDisplay reads geometry of kiosk display again after outputs changed and reports if it changed
+func (k *kiosk) refreshDisplay() bool {
+	current := k.bounds()
+	updated := current
+	if k.display.Name != "" {
+		outputs, err := k.screen.Outputs()
+		if err != nil {
+			k.log.Warn("failed to list outputs", zap.Error(err))
+			return false
+		}
+		for _, o := range outputs {
+			if o.Name == k.display.Name && o.Enabled {
+				updated.X, updated.Y, updated.Width, updated.Height = o.X, o.Y, o.Width, o.Height
+			}
+		}
+	} else if list := screens(); k.display.ID < len(list) {
+		d := list[k.display.ID]
+		updated.X, updated.Y, updated.Width, updated.Height = d.X, d.Y, d.Width, d.Height
+	}
+	if updated == current {
+		return false
+	}
+
+	k.log.Info("display geometry changed", zap.Any("display", updated))
+	k.displayMu.Lock()
+	k.display.X, k.display.Y, k.display.Width, k.display.Height = updated.X, updated.Y, updated.Width, updated.Height
+	k.displayMu.Unlock()
+	return true
+}
+
+// bounds returns kiosk display with its current geometry
+func (k *kiosk) bounds() api.Display {
+	k.displayMu.RLock()
+	defer k.displayMu.RUnlock()
+	return k.display
+}
+
+// mergeOutputConfig returns persisted config of output updated with set fields of in. Rate of
+// persisted resolution does not apply to other one
+func mergeOutputConfig(configs []api.OutputConfig, in api.OutputConfig) api.OutputConfig {
+	config := api.OutputConfig{Name: in.Name}
+	for _, c := range configs {
+		if c.Name == in.Name {
+			config = c
+		}
+	}
+	if in.Width != 0 && in.Height != 0 {
+		if in.Width != config.Width || in.Height != config.Height {
+			config.Rate = 0
+		}
+		config.Width, config.Height = in.Width, in.Height
+	}
+	if in.Rate != 0 {
+		config.Rate = in.Rate
+	}
+	if in.Rotation != "" {
+		config.Rotation = in.Rotation
+	}
+	return config
+}
+
+// replaceOutputConfig returns configs with config of the same output replaced by config
+func replaceOutputConfig(configs []api.OutputConfig, config api.OutputConfig) []api.OutputConfig {
+	result := make([]api.OutputConfig, 0, len(configs)+1)
+	for _, c := range configs {
+		if c.Name != config.Name {
+			result = append(result, c)
+		}
+	}
+	return append(result, config)
+}
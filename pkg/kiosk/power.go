@@ -0,0 +1,210 @@
+package kiosk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+	"github.com/unikiosk/unikiosk/pkg/util/x11"
+)
+
+// defaultDisplay is used when neither ScreenDisplay nor DISPLAY is set
+var defaultDisplay = ":0"
+
+// monitor controls screen power
+type monitor interface {
+	// SetPower turns screen on or off
+	SetPower(on bool) error
+	// Power returns actual screen power
+	Power() (api.PowerState, error)
+}
+
+// newMonitor returns monitor of display. Outputs of multi display screen are powered separately,
+// DPMS powers the whole screen otherwise
+func newMonitor(config *config.Config, display api.Display) monitor {
+	if config.MultiDisplay && display.Name != "" {
+		return &outputMonitor{xdisplay: xDisplay(config.ScreenDisplay), display: display}
+	}
+	return newX11Monitor(config.ScreenDisplay)
+}
+
+// xDisplay returns X11 display to connect to, DISPLAY is used when display is empty
+func xDisplay(display string) string {
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+	if display == "" {
+		display = defaultDisplay
+	}
+	return display
+}
+
+// x11Monitor controls screen power over DPMS extension of X11 display. Display is connected
+// for each call, so X server restarts do not break it
+type x11Monitor struct {
+	display string
+}
+
+func newX11Monitor(display string) *x11Monitor {
+	return &x11Monitor{display: xDisplay(display)}
+}
+
+func (m *x11Monitor) SetPower(on bool) error {
+	conn, err := x11.ConnectDisplay(m.display)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.SetPower(on)
+}
+
+func (m *x11Monitor) Power() (api.PowerState, error) {
+	conn, err := x11.ConnectDisplay(m.display)
+	if err != nil {
+		return api.PowerStateUnknown, err
+	}
+	defer conn.Close()
+
+	level, enabled, err := conn.Power()
+	if err != nil {
+		return api.PowerStateUnknown, err
+	}
+	// monitor is not powered down while DPMS is disabled
+	if !enabled || level == x11.PowerLevelOn {
+		return api.PowerStateOn, nil
+	}
+	return api.PowerStateOff, nil
+}
+
+// outputMonitor powers output of display over RandR extension. Output without signal puts monitor
+// to standby, it is enabled again at position of display
+type outputMonitor struct {
+	xdisplay string
+	display  api.Display
+}
+
+func (m *outputMonitor) SetPower(on bool) error {
+	conn, err := x11.ConnectDisplay(m.xdisplay)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.SetOutputEnabled(m.display.Name, on, m.display.X, m.display.Y)
+}
+
+func (m *outputMonitor) Power() (api.PowerState, error) {
+	conn, err := x11.ConnectDisplay(m.xdisplay)
+	if err != nil {
+		return api.PowerStateUnknown, err
+	}
+	defer conn.Close()
+
+	outputs, err := conn.Outputs()
+	if err != nil {
+		return api.PowerStateUnknown, err
+	}
+	for _, o := range outputs {
+		if o.Name != m.display.Name {
+			continue
+		}
+		if o.Enabled {
+			return api.PowerStateOn, nil
+		}
+		return api.PowerStateOff, nil
+	}
+	return api.PowerStateUnknown, fmt.Errorf("output %s: %w", m.display.Name, os.ErrNotExist)
+}
+
+// setPower turns screen on or off with power and records requested and actual power in state
+func (k *kiosk) setPower(ctx context.Context, in api.KioskRequest, power func() error) error {
+	k.powerMu.Lock()
+	defer k.powerMu.Unlock()
+
+	err := power()
+	if err != nil {
+		return err
+	}
+	err = k.updateState(ctx, in, "")
+	if err != nil {
+		k.log.Warn("failed to update power state", zap.Error(err))
+	}
+	return nil
+}
+
+// readPower returns actual screen power, unknown when it can't be read
+func (k *kiosk) readPower() api.PowerState {
+	power, err := k.monitor.Power()
+	if err != nil {
+		k.log.Debug("failed to read screen power", zap.Error(err))
+		return api.PowerStateUnknown
+	}
+	return power
+}
+
+// runPower reads actual screen power every PowerCheckInterval and corrects it when it drifts from requested
+func (k *kiosk) runPower(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	interval := k.config.PowerCheckInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		k.checkPower()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkPower turns screen on or off when it differs from requested power, e.g. after DPMS timeout
+// or monitor power button, and records actual power in state
+func (k *kiosk) checkPower() {
+	k.powerMu.Lock()
+	defer k.powerMu.Unlock()
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return
+	}
+
+	actual := k.readPower()
+	desired := state.DesiredPowerState
+	if actual != api.PowerStateUnknown && desired != api.PowerStateUnknown && actual != desired {
+		k.log.Warn("screen power differs from requested, correcting", zap.String("actual", actual.String()), zap.String("desired", desired.String()))
+		power := k.PowerOff
+		if desired == api.PowerStateOn {
+			power = k.PowerOn
+		}
+		err := power()
+		if err != nil {
+			k.log.Warn("failed to correct screen power", zap.Error(err))
+		} else {
+			actual = k.readPower()
+		}
+	}
+	if actual == state.PowerState {
+		return
+	}
+
+	k.log.Info("screen power changed", zap.String("previous", state.PowerState.String()), zap.String("power", actual.String()))
+	state.PowerState = actual
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+	}
+	k.publish(api.EventTypePowerChanged, state)
+}
@@ -0,0 +1,384 @@
+package kiosk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+)
+
+var (
+	// powerScheduleIdentity is caller identity of power requests sent by power schedule in audit log
+	powerScheduleIdentity = "schedule"
+
+	// powerScheduleDays is how many days ahead next transition is looked up, days until the last exception
+	// are looked up too
+	powerScheduleDays = 8
+
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday,
+		"mon": time.Monday,
+		"tue": time.Tuesday,
+		"wed": time.Wednesday,
+		"thu": time.Thursday,
+		"fri": time.Friday,
+		"sat": time.Saturday,
+	}
+)
+
+// powerSchedule is parsed api.PowerSchedule
+type powerSchedule struct {
+	location   *time.Location
+	windows    map[time.Weekday][]dayWindow
+	exceptions map[string][]dayWindow
+}
+
+// dayWindow is minutes of the day screen is turned on and off. Window with off before on ends the next day
+type dayWindow struct {
+	on  int
+	off int
+}
+
+// parsePowerSchedule validates schedule and parses it
+func parsePowerSchedule(in *api.PowerSchedule) (*powerSchedule, error) {
+	s := &powerSchedule{
+		location:   time.Local,
+		windows:    map[time.Weekday][]dayWindow{},
+		exceptions: map[string][]dayWindow{},
+	}
+	if in.Timezone != "" {
+		location, err := time.LoadLocation(in.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", in.Timezone, os.ErrInvalid)
+		}
+		s.location = location
+	}
+
+	for _, w := range in.Windows {
+		window, err := parseDayWindow(w.On, w.Off)
+		if err != nil {
+			return nil, err
+		}
+		if len(w.Days) == 0 {
+			return nil, fmt.Errorf("window %s-%s has no days: %w", w.On, w.Off, os.ErrInvalid)
+		}
+		for _, d := range w.Days {
+			day, ok := weekdays[strings.ToLower(d)]
+			if !ok {
+				return nil, fmt.Errorf("unknown day %q, expected one of: mon, tue, wed, thu, fri, sat, sun: %w", d, os.ErrInvalid)
+			}
+			s.windows[day] = append(s.windows[day], window)
+		}
+	}
+
+	for _, e := range in.Exceptions {
+		_, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exception date %q, expected format 2006-01-02: %w", e.Date, os.ErrInvalid)
+		}
+		// date is off the whole day without window
+		windows := s.exceptions[e.Date]
+		if e.On != "" || e.Off != "" {
+			window, err := parseDayWindow(e.On, e.Off)
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, window)
+		}
+		s.exceptions[e.Date] = windows
+	}
+	return s, nil
+}
+
+func parseDayWindow(on, off string) (dayWindow, error) {
+	w := dayWindow{}
+	for _, t := range []struct {
+		value  string
+		minute *int
+	}{{on, &w.on}, {off, &w.off}} {
+		parsed, err := time.Parse("15:04", t.value)
+		if err != nil {
+			return w, fmt.Errorf("invalid time %q, expected format 15:04: %w", t.value, os.ErrInvalid)
+		}
+		*t.minute = parsed.Hour()*60 + parsed.Minute()
+	}
+	if w.on == w.off {
+		return w, fmt.Errorf("window %s-%s is empty: %w", on, off, os.ErrInvalid)
+	}
+	return w, nil
+}
+
+// intervals returns times screen is on during windows of day, which starts at midnight of schedule location
+func (s *powerSchedule) intervals(day time.Time) [][2]time.Time {
+	windows, ok := s.exceptions[day.Format("2006-01-02")]
+	if !ok {
+		windows = s.windows[day.Weekday()]
+	}
+	at := func(day time.Time, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, s.location)
+	}
+
+	intervals := make([][2]time.Time, 0, len(windows))
+	for _, w := range windows {
+		end := at(day, w.off)
+		if w.off < w.on {
+			end = at(day.AddDate(0, 0, 1), w.off)
+		}
+		intervals = append(intervals, [2]time.Time{at(day, w.on), end})
+	}
+	return intervals
+}
+
+// day returns midnight of day of t in schedule location shifted by offset days
+func (s *powerSchedule) day(t time.Time, offset int) time.Time {
+	t = t.In(s.location)
+	return time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, s.location)
+}
+
+// power returns power requested at t. Window of previous day which ends after midnight is still on
+func (s *powerSchedule) power(t time.Time) api.PowerState {
+	for offset := -1; offset <= 0; offset++ {
+		for _, i := range s.intervals(s.day(t, offset)) {
+			if !t.Before(i[0]) && t.Before(i[1]) {
+				return api.PowerStateOn
+			}
+		}
+	}
+	return api.PowerStateOff
+}
+
+// next returns time of the first transition after now and power requested then. Zero time is returned
+// when power does not change within powerScheduleDays and until the last exception
+func (s *powerSchedule) next(now time.Time) (time.Time, api.PowerState) {
+	days := powerScheduleDays
+	today := s.day(now, 0)
+	for date := range s.exceptions {
+		d, err := time.ParseInLocation("2006-01-02", date, s.location)
+		if err != nil {
+			continue
+		}
+		// exception window can end the next day
+		if offset := int(d.Sub(today).Hours()/24) + 1; offset > days {
+			days = offset
+		}
+	}
+
+	var candidates []time.Time
+	for offset := -1; offset <= days; offset++ {
+		for _, i := range s.intervals(s.day(now, offset)) {
+			for _, t := range i {
+				if t.After(now) {
+					candidates = append(candidates, t)
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+
+	// adjacent and overlapping windows do not change power at each of their bounds
+	current := s.power(now)
+	for _, t := range candidates {
+		if power := s.power(t); power != current {
+			return t, power
+		}
+	}
+	return time.Time{}, current
+}
+
+// powerScheduleStatus returns power schedule of state with power it requests at now
+func powerScheduleStatus(state *api.KioskState, now time.Time) *api.PowerScheduleStatus {
+	status := &api.PowerScheduleStatus{Schedule: state.PowerSchedule, Override: state.PowerOverride}
+	if state.PowerOverride && !state.PowerOverrideUntil.IsZero() {
+		until := state.PowerOverrideUntil
+		status.OverrideUntil = &until
+	}
+	if state.PowerSchedule == nil {
+		return status
+	}
+	s, err := parsePowerSchedule(state.PowerSchedule)
+	if err != nil {
+		return status
+	}
+	status.Scheduled = s.power(now).String()
+	if next, power := s.next(now); !next.IsZero() {
+		status.Next = &next
+		status.NextPower = power.String()
+	}
+	return status
+}
+
+// powerSchedule returns power schedule with power it requests now
+func (k *kiosk) powerSchedule() (*api.PowerScheduleStatus, error) {
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return nil, err
+	}
+	return powerScheduleStatus(state, time.Now()), nil
+}
+
+// setPowerSchedule replaces power schedule and removes override of previous one. Nil schedule removes it
+func (k *kiosk) setPowerSchedule(in *api.PowerSchedule) error {
+	if in != nil {
+		_, err := parsePowerSchedule(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return err
+	}
+	state.PowerSchedule = in
+	state.PowerOverride = false
+	state.PowerOverrideUntil = time.Time{}
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		return fmt.Errorf("persist schedule: %w", err)
+	}
+
+	// schedule is applied by its loop, as handler can't wait for its own request
+	select {
+	case k.powerScheduleChanged <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// overridePowerSchedule records power requested by hand, which differs from power schedule, as override until
+// the next scheduled transition
+func (k *kiosk) overridePowerSchedule(power api.PowerState, now time.Time) error {
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		return err
+	}
+	if state.PowerSchedule == nil {
+		return nil
+	}
+	s, err := parsePowerSchedule(state.PowerSchedule)
+	if err != nil {
+		k.log.Warn("invalid power schedule", zap.Error(err))
+		return nil
+	}
+
+	state.PowerOverride = s.power(now) != power
+	state.PowerOverrideUntil = time.Time{}
+	if state.PowerOverride {
+		state.PowerOverrideUntil, _ = s.next(now)
+		k.log.Info("power schedule is overridden", zap.String("power", power.String()), zap.Time("until", state.PowerOverrideUntil))
+	}
+	err = k.store.Persist(k.stateKey, *state)
+	if err != nil {
+		return fmt.Errorf("persist schedule override: %w", err)
+	}
+	return nil
+}
+
+// runPowerSchedule turns screen on and off by power schedule every PowerScheduleInterval and once schedule changes
+func (k *kiosk) runPowerSchedule(ctx context.Context) {
+	defer recover.Panic(k.log)
+
+	interval := k.config.PowerScheduleInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		k.checkPowerSchedule(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-k.powerScheduleChanged:
+		}
+	}
+}
+
+// checkPowerSchedule requests power scheduled at now, unless it is requested already or overridden by hand.
+// Power is requested through eventer, so it is handled and audited as any other request
+func (k *kiosk) checkPowerSchedule(ctx context.Context, now time.Time) {
+	power, ok := k.scheduledPower(now)
+	if !ok {
+		return
+	}
+	action := api.ScreenActionPowerOff
+	if power == api.PowerStateOn {
+		action = api.ScreenActionPowerOn
+	}
+	k.log.Info("power schedule changes screen power", zap.String("power", power.String()))
+
+	_, err := k.events.Emit(&eventer.EventWrapper{
+		Payload: api.Event{
+			Type:    api.EventTypeRequest,
+			Request: api.KioskRequest{Action: action, Display: k.display.ID},
+			Caller:  api.Caller{Identity: powerScheduleIdentity, Scheduled: true},
+		},
+	})
+	if err != nil {
+		k.log.Warn("failed to request scheduled power", zap.String("power", power.String()), zap.Error(err))
+	}
+}
+
+// scheduledPower returns power scheduled at now and reports if it differs from requested power. Expired
+// override is removed
+func (k *kiosk) scheduledPower(now time.Time) (api.PowerState, bool) {
+	k.loadMu.Lock()
+	defer k.loadMu.Unlock()
+
+	state, err := k.store.Get(k.stateKey)
+	if err != nil {
+		k.log.Warn("failed to get state", zap.Error(err))
+		return api.PowerStateUnknown, false
+	}
+	if state.PowerSchedule == nil {
+		return api.PowerStateUnknown, false
+	}
+	s, err := parsePowerSchedule(state.PowerSchedule)
+	if err != nil {
+		k.log.Warn("invalid power schedule", zap.Error(err))
+		return api.PowerStateUnknown, false
+	}
+	if state.PowerOverride {
+		// override of schedule without transitions lasts until schedule changes its power
+		if state.PowerOverrideUntil.IsZero() {
+			state.PowerOverrideUntil, _ = s.next(now)
+			if state.PowerOverrideUntil.IsZero() {
+				return api.PowerStateUnknown, false
+			}
+			err := k.store.Persist(k.stateKey, *state)
+			if err != nil {
+				k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+			}
+		}
+		if now.Before(state.PowerOverrideUntil) {
+			return api.PowerStateUnknown, false
+		}
+		k.log.Info("power schedule override expired", zap.Time("until", state.PowerOverrideUntil))
+		state.PowerOverride = false
+		state.PowerOverrideUntil = time.Time{}
+		err := k.store.Persist(k.stateKey, *state)
+		if err != nil {
+			k.log.Warn("failed to persist store, will not recover after restart", zap.Error(err))
+		}
+	}
+
+	power := s.power(now)
+	return power, power != state.DesiredPowerState
+}
@@ -0,0 +1,78 @@
+package kiosk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// cookies returns browser cookies selected by opts
+func (k *kiosk) cookies(ctx context.Context, opts *api.CookieOptions) ([]api.Cookie, error) {
+	all, err := k.driver.Cookies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		return all, nil
+	}
+
+	selected := []api.Cookie{}
+	for _, c := range all {
+		if (opts.Name == "" || c.Name == opts.Name) && (opts.Domain == "" || domainMatches(c.Domain, opts.Domain)) {
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// setCookies creates or replaces cookies, each of them needs name and domain
+func (k *kiosk) setCookies(ctx context.Context, cookies []api.Cookie) error {
+	if len(cookies) == 0 {
+		return fmt.Errorf("cookies are required: %w", os.ErrInvalid)
+	}
+	for _, c := range cookies {
+		if c.Name == "" || c.Domain == "" {
+			return fmt.Errorf("cookie name and domain are required: %w", os.ErrInvalid)
+		}
+	}
+	return k.driver.SetCookies(ctx, cookies)
+}
+
+// deleteCookies removes cookies selected by opts and returns them
+func (k *kiosk) deleteCookies(ctx context.Context, opts *api.CookieOptions) ([]api.Cookie, error) {
+	cookies, err := k.cookies(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return cookies, nil
+	}
+	return cookies, k.driver.DeleteCookies(ctx, cookies)
+}
+
+// clearData removes browser data selected by opts. Storage of content is removed together with storage of opts.Origins
+func (k *kiosk) clearData(ctx context.Context, opts api.ClearOptions) error {
+	if !opts.Cookies && !opts.Storage && !opts.Cache {
+		return fmt.Errorf("one of cookies, storage or cache is required: %w", os.ErrInvalid)
+	}
+	if opts.Storage {
+		state, err := k.store.Get(k.stateKey)
+		if err != nil {
+			return err
+		}
+		if o := origin(state.Content); o != "" {
+			opts.Origins = append(append([]string{}, opts.Origins...), o)
+		}
+	}
+	return k.driver.ClearData(ctx, opts)
+}
+
+// domainMatches reports if cookie of cookieDomain belongs to domain or its subdomain
+func domainMatches(cookieDomain, domain string) bool {
+	c := strings.TrimPrefix(strings.ToLower(cookieDomain), ".")
+	d := strings.TrimPrefix(strings.ToLower(domain), ".")
+	return c == d || strings.HasSuffix(c, "."+d)
+}
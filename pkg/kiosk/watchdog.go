@@ -0,0 +1,139 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/util/recover"
+)
+
+var (
+	// defaults are used when restart configuration is not set
+	defaultRestartBackoff    = time.Second
+	defaultRestartBackoffMax = time.Minute
+	defaultCrashLoopRestarts = 5
+	defaultCrashLoopPause    = 5 * time.Minute
+	// defaultWatchdogFailures is used when watchdog failures are not set
+	defaultWatchdogFailures = 3
+	// defaultPingTimeout is used when neither watchdog timeout nor interval is set
+	defaultPingTimeout = 5 * time.Second
+
+	errUnresponsive = errors.New("page is not responding")
+)
+
+// backoff computes delay before browser restart. Delay grows exponentially while browser keeps
+// crashing soon after start and circuit opens, pausing restarts, once crash loop is detected
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	// limit is number of crashes in a row which opens the circuit for pause
+	limit int
+	pause time.Duration
+
+	crashes int
+	delay   time.Duration
+}
+
+// next returns delay before next start, given how long last browser run lasted. open is true
+// when crash loop was detected and restarts are paused
+func (b *backoff) next(run time.Duration) (delay time.Duration, open bool) {
+	// browser which was running long enough is not crashing in a loop
+	if run >= b.max {
+		b.crashes = 0
+		b.delay = 0
+	}
+
+	b.crashes++
+	if b.crashes >= b.limit {
+		b.crashes = 0
+		b.delay = 0
+		return b.pause, true
+	}
+
+	if b.delay == 0 {
+		b.delay = b.initial
+	} else {
+		b.delay *= 2
+	}
+	if b.delay > b.max {
+		b.delay = b.max
+	}
+	return b.delay, false
+}
+
+// watch checks if page responds while browser runs and stops browser when it does not
+// respond BrowserWatchdogFailures times in a row. It stops browser when ctx is cancelled too
+func (k *kiosk) watch(ctx context.Context, done <-chan struct{}) {
+	defer recover.Panic(k.log)
+
+	interval := k.config.BrowserWatchdogInterval
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	limit := k.config.BrowserWatchdogFailures
+	if limit <= 0 {
+		limit = defaultWatchdogFailures
+	}
+
+	failures := 0
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			k.stopBrowser(nil)
+			return
+		case <-tick:
+		}
+
+		err := k.ping(ctx)
+		k.lastPing.Store(pingResult{err: err})
+		if err == nil {
+			failures = 0
+			continue
+		}
+		failures++
+		k.log.Warn("browser did not respond", zap.Int("failures", failures), zap.Error(err))
+		if failures >= limit {
+			k.log.Error("browser is not responding, restarting", zap.Int("failures", failures))
+			k.stopBrowser(errUnresponsive)
+			return
+		}
+	}
+}
+
+// ping evaluates trivial expression, which fails when page javascript is blocked
+func (k *kiosk) ping(ctx context.Context) error {
+	timeout := durationOr(k.config.BrowserWatchdogTimeout, durationOr(k.config.BrowserWatchdogInterval, defaultPingTimeout))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err := k.driver.Eval(ctx, "1")
+	return err
+}
+
+// stopBrowser stops browser, reason is reported as error of its run
+func (k *kiosk) stopBrowser(reason error) {
+	k.stopReason.Store(stopReason{err: reason})
+	err := k.driver.Stop()
+	if err != nil {
+		k.log.Error("failed to stop browser", zap.Error(err))
+	}
+}
+
+// stopReason wraps error, as atomic.Value can't store nil
+type stopReason struct {
+	err error
+}
+
+// pingResult wraps error of the last watchdog check, as atomic.Value can't store nil
+type pingResult struct {
+	err error
+}
@@ -64,7 +64,7 @@ func (p *proxy) runHTTP(ctx context.Context) error {
 	// restart if panics or errors
 	go func() {
 		for {
-			err := http.ListenAndServe(p.config.ProxyHTTPServerAddr, p.proxyHTTP)
+			err := p.serve(p.config.ProxyHTTPServerAddr, p.proxyHTTP, &p.httpBound)
 			if err != nil {
 				p.log.Debug("http proxy failed. Restarting", zap.Error(err))
 				time.Sleep(time.Second)
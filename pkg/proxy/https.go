@@ -29,7 +29,7 @@ func (p *proxy) runHTTPS(ctx context.Context) error {
 	// restart if panics or errors
 	go func() {
 		for {
-			err := http.ListenAndServe(p.config.ProxyHTTPSServerAddr, p.proxyHTTPS)
+			err := p.serve(p.config.ProxyHTTPSServerAddr, p.proxyHTTPS, &p.httpsBound)
 			if err != nil {
 				p.log.Debug("https proxy failed. Restarting", zap.Error(err))
 				time.Sleep(time.Second)
@@ -2,6 +2,10 @@ package proxy
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
 
 	"github.com/elazarl/goproxy"
 	"go.uber.org/zap"
@@ -13,6 +17,9 @@ import (
 
 type Proxy interface {
 	Run(ctx context.Context) error
+	// CheckHTTP and CheckHTTPS return error when proxy listener is not bound
+	CheckHTTP(ctx context.Context) error
+	CheckHTTPS(ctx context.Context) error
 }
 
 type proxy struct {
@@ -20,6 +27,10 @@ type proxy struct {
 	proxyHTTP  *goproxy.ProxyHttpServer
 	proxyHTTPS *goproxy.ProxyHttpServer
 
+	// httpBound and httpsBound are set to 1 while listeners are bound
+	httpBound  int32
+	httpsBound int32
+
 	log *zap.Logger
 }
 
@@ -67,3 +78,29 @@ func (p *proxy) Stop(ctx context.Context) error {
 	// TODO: implement
 	return nil
 }
+
+func (p *proxy) CheckHTTP(ctx context.Context) error {
+	if atomic.LoadInt32(&p.httpBound) == 0 {
+		return fmt.Errorf("http proxy is not listening on %s", p.config.ProxyHTTPServerAddr)
+	}
+	return nil
+}
+
+func (p *proxy) CheckHTTPS(ctx context.Context) error {
+	if atomic.LoadInt32(&p.httpsBound) == 0 {
+		return fmt.Errorf("https proxy is not listening on %s", p.config.ProxyHTTPSServerAddr)
+	}
+	return nil
+}
+
+// serve listens on addr and serves handler until it fails. bound is set while listener is open
+func (p *proxy) serve(addr string, handler http.Handler, bound *int32) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(bound, 1)
+	defer atomic.StoreInt32(bound, 0)
+
+	return http.Serve(listener, handler)
+}
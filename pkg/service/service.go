@@ -2,20 +2,40 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/auth"
+	"github.com/unikiosk/unikiosk/pkg/browser"
 	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/console"
 	"github.com/unikiosk/unikiosk/pkg/eventer"
-	"github.com/unikiosk/unikiosk/pkg/firefox"
+	"github.com/unikiosk/unikiosk/pkg/grpc/server"
+	"github.com/unikiosk/unikiosk/pkg/health"
+	"github.com/unikiosk/unikiosk/pkg/kiosk"
 	"github.com/unikiosk/unikiosk/pkg/proxy"
 	"github.com/unikiosk/unikiosk/pkg/store/disk"
 	"github.com/unikiosk/unikiosk/pkg/util/recover"
 	"github.com/unikiosk/unikiosk/pkg/web"
 )
 
+var (
+	componentWeb        = "web"
+	componentProxyHTTP  = "proxy_http"
+	componentProxyHTTPS = "proxy_https"
+	componentStore      = "store"
+	componentBrowser    = "browser"
+	componentPageLoad   = "page_load"
+
+	// startupTimeout limits how long browser waits for web server and proxy to start
+	startupTimeout = time.Minute
+)
+
 type Service interface {
 	Run(ctx context.Context) error
 }
@@ -24,9 +44,11 @@ type ServiceManager struct {
 	log    *zap.Logger
 	config *config.Config
 
-	firefox firefox.Kiosk
-	web     web.Interface
-	proxy   proxy.Proxy
+	kiosks []kiosk.Kiosk
+	web    web.Interface
+	grpc   server.Interface
+	proxy  proxy.Proxy
+	health *health.Health
 }
 
 func New(ctx context.Context, log *zap.Logger, config *config.Config) (*ServiceManager, error) {
@@ -38,12 +60,44 @@ func New(ctx context.Context, log *zap.Logger, config *config.Config) (*ServiceM
 		return nil, err
 	}
 
-	firefox, err := firefox.New(log.Named("firefox"), config, events, store)
+	auditLog, err := audit.New(log.Named("audit"), config)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := health.New()
+
+	// every display runs its own browser
+	displays := kiosk.Displays(log.Named("kiosk"), config)
+	kiosks := make([]kiosk.Kiosk, 0, len(displays))
+	consoles := make(map[int]*console.Buffer, len(displays))
+	for _, d := range displays {
+		consoles[d.ID] = console.New(config.BrowserConsoleSize)
+		driver, err := browser.New(log.Named("browser").With(zap.Int("display", d.ID)), config, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		k, err := kiosk.New(log.Named("kiosk").With(zap.Int("display", d.ID)), config, d, events, store, auditLog, driver, consoles[d.ID])
+		if err != nil {
+			return nil, err
+		}
+		kiosks = append(kiosks, k)
+
+		checks.Register(displayComponent(componentBrowser, d.ID), false, health.ErrorCheck(k.CheckBrowser))
+		checks.Register(displayComponent(componentPageLoad, d.ID), false, k.CheckPageLoad)
+	}
+
+	authenticator, err := auth.New(log.Named("auth"), config)
 	if err != nil {
 		return nil, err
 	}
 
-	web, err := web.New(log.Named("webserver"), config, events, store)
+	web, err := web.New(log.Named("webserver"), config, events, store, authenticator, auditLog, checks, consoles, displays)
+	if err != nil {
+		return nil, err
+	}
+
+	grpc, err := server.New(log.Named("grpc"), config, events, store, authenticator, auditLog, displays)
 	if err != nil {
 		return nil, err
 	}
@@ -53,13 +107,20 @@ func New(ctx context.Context, log *zap.Logger, config *config.Config) (*ServiceM
 		return nil, err
 	}
 
+	checks.Register(componentWeb, true, health.ErrorCheck(web.Check))
+	checks.Register(componentProxyHTTP, true, health.ErrorCheck(proxy.CheckHTTP))
+	checks.Register(componentProxyHTTPS, true, health.ErrorCheck(proxy.CheckHTTPS))
+	checks.Register(componentStore, true, health.ErrorCheck(store.Check))
+
 	return &ServiceManager{
 		log:    log,
 		config: config,
 
-		firefox: firefox,
-		web:     web,
-		proxy:   proxy,
+		kiosks: kiosks,
+		web:    web,
+		grpc:   grpc,
+		proxy:  proxy,
+		health: checks,
 	}, nil
 }
 
@@ -74,14 +135,54 @@ func (s *ServiceManager) Run(ctx context.Context) error {
 	})
 	g.Go(func() error {
 		defer recover.Panic(s.log)
-		return s.proxy.Run(ctx)
+		return s.grpc.Run(ctx)
 	})
-
-	time.Sleep(time.Second * 5)
 	g.Go(func() error {
-		defer s.firefox.Stop()
-		return s.firefox.Run(ctx)
+		defer recover.Panic(s.log)
+		return s.proxy.Run(ctx)
 	})
 
+	// browser loads content via proxy from web server, so both must be listening first
+	err := s.waitFor(ctx, componentWeb, componentProxyHTTP, componentProxyHTTPS)
+	if err != nil {
+		return err
+	}
+	for _, k := range s.kiosks {
+		k := k
+		g.Go(func() error {
+			defer k.Stop()
+			return k.Run(ctx)
+		})
+	}
+
 	return g.Wait()
 }
+
+// displayComponent returns name of health check component of display. Components of the first display keep their names
+func displayComponent(component string, display int) string {
+	if display == 0 {
+		return component
+	}
+	return component + "_" + strconv.Itoa(display)
+}
+
+// waitFor blocks until all components are healthy
+func (s *ServiceManager) waitFor(ctx context.Context, components ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+
+	for _, c := range components {
+		for {
+			err := s.health.Check(ctx, c)
+			if err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s did not start: %w", c, err)
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+	return nil
+}
@@ -1,7 +1,12 @@
 package disk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/peterbourgon/diskv"
 	"go.uber.org/zap"
@@ -55,3 +60,19 @@ func (s *DiskStore) Persist(key string, in api.KioskState) error {
 
 	return s.store.Write(key, data)
 }
+
+// healthCheckKey is written and removed to verify state directory is writable
+const healthCheckKey = "healthcheck"
+
+func (s *DiskStore) Check(ctx context.Context) error {
+	_, err := s.store.Read(store.KioskStateKey)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("state is not readable: %w", err)
+	}
+
+	err = s.store.Write(healthCheckKey, []byte(time.Now().UTC().Format(time.RFC3339)))
+	if err != nil {
+		return fmt.Errorf("state is not writable: %w", err)
+	}
+	return s.store.Erase(healthCheckKey)
+}
@@ -0,0 +1,39 @@
+package disk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/store"
+	"github.com/unikiosk/unikiosk/pkg/util/logger"
+)
+
+func TestDiskStore_legacyPowerState(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir := t.TempDir()
+	s, err := New(logger.GetLoggerInstance("", zap.DebugLevel), &config.Config{StateDir: dir})
+	require.NoError(err)
+
+	// state persisted before desired power was added keeps screen off
+	require.NoError(os.WriteFile(filepath.Join(dir, store.KioskStateKey), []byte(`{"Content":"https://synpse.net","PowerState":1}`), 0600))
+	state, err := s.Get(store.KioskStateKey)
+	require.NoError(err)
+	require.Equal(api.PowerStateOff, state.PowerState)
+	require.Equal(api.PowerStateOff, state.DesiredPowerState)
+
+	// actual power does not replace persisted desired power
+	state.PowerState = api.PowerStateUnknown
+	require.NoError(s.Persist(store.KioskStateKey, *state))
+	state, err = s.Get(store.KioskStateKey)
+	require.NoError(err)
+	require.Equal(api.PowerStateUnknown, state.PowerState)
+	require.Equal(api.PowerStateOff, state.DesiredPowerState)
+}
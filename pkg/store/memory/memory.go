@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/store"
+)
+
+var _ store.Store = &MemoryStore{}
+
+// MemoryStore keeps state in memory. Used for tests and ephemeral setups
+type MemoryStore struct {
+	mu    sync.RWMutex
+	state map[string]api.KioskState
+}
+
+func New() *MemoryStore {
+	return &MemoryStore{
+		state: map[string]api.KioskState{},
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*api.KioskState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.state[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return &r, nil
+}
+
+func (s *MemoryStore) Persist(key string, in api.KioskState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state[key] = in
+	return nil
+}
+
+func (s *MemoryStore) Check(ctx context.Context) error {
+	return nil
+}
@@ -1,10 +1,26 @@
 package store
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/unikiosk/unikiosk/pkg/api"
 )
 
+// KioskStateKey is the key under which kiosk state of the first display is persisted
+const KioskStateKey = "gofirefox"
+
+// StateKey returns key under which kiosk state of display is persisted
+func StateKey(display int) string {
+	if display == 0 {
+		return KioskStateKey
+	}
+	return KioskStateKey + "-" + strconv.Itoa(display)
+}
+
 type Store interface {
 	Get(keys string) (*api.KioskState, error)
 	Persist(key string, in api.KioskState) error
+	// Check returns error if store is not readable or writable
+	Check(ctx context.Context) error
 }
@@ -0,0 +1,106 @@
+package backlight
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultDir is where kernel exposes backlight devices
+var DefaultDir = "/sys/class/backlight"
+
+// Device is backlight device of sysfs class directory. Brightness is set in percent of max_brightness
+type Device struct {
+	Name string
+	dir  string
+}
+
+// Open returns backlight device name of dir, the first device by name when name is empty
+func Open(dir, name string) (*Device, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if name == "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backlight devices: %w", err)
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no backlight device in %s: %w", dir, os.ErrNotExist)
+		}
+		sort.Strings(names)
+		name = names[0]
+	}
+
+	d := &Device{Name: name, dir: filepath.Join(dir, name)}
+	_, err := d.max()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Brightness returns actual brightness in percent
+func (d *Device) Brightness() (int, error) {
+	max, err := d.max()
+	if err != nil {
+		return 0, err
+	}
+	// actual_brightness is brightness hardware reports, not all drivers have it
+	value, err := d.read("actual_brightness")
+	if errors.Is(err, os.ErrNotExist) {
+		value, err = d.read("brightness")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return (value*100 + max/2) / max, nil
+}
+
+// SetBrightness sets brightness in percent 0-100. Zero turns backlight off on some panels
+func (d *Device) SetBrightness(percent int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("brightness %d is out of range 0-100: %w", percent, os.ErrInvalid)
+	}
+	max, err := d.max()
+	if err != nil {
+		return err
+	}
+	value := (percent*max + 50) / 100
+	err = os.WriteFile(filepath.Join(d.dir, "brightness"), []byte(strconv.Itoa(value)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to set brightness of %s: %w", d.Name, err)
+	}
+	return nil
+}
+
+func (d *Device) max() (int, error) {
+	max, err := d.read("max_brightness")
+	if err != nil {
+		return 0, err
+	}
+	if max <= 0 {
+		return 0, fmt.Errorf("backlight %s has no brightness levels", d.Name)
+	}
+	return max, nil
+}
+
+func (d *Device) read(file string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(d.dir, file))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s of backlight %s: %w", file, d.Name, err)
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected %s of backlight %s: %w", file, d.Name, err)
+	}
+	return value, nil
+}
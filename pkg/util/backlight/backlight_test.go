@@ -0,0 +1,63 @@
+package backlight
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// device creates fake sysfs backlight device in dir
+func device(t *testing.T, dir, name string, brightness, max int, actual bool) {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(path, 0755))
+	write := func(file string, value int) {
+		require.NoError(t, os.WriteFile(filepath.Join(path, file), []byte(fmt.Sprintf("%d\n", value)), 0644))
+	}
+	write("brightness", brightness)
+	write("max_brightness", max)
+	if actual {
+		write("actual_brightness", brightness)
+	}
+}
+
+func TestDevice(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir := t.TempDir()
+	device(t, dir, "intel_backlight", 2400, 4800, true)
+	device(t, dir, "acpi_video0", 7, 7, false)
+
+	// the first device by name is used by default
+	d, err := Open(dir, "")
+	require.NoError(err)
+	require.Equal("acpi_video0", d.Name)
+	level, err := d.Brightness()
+	require.NoError(err)
+	require.Equal(100, level)
+
+	require.NoError(d.SetBrightness(50))
+	level, err = d.Brightness()
+	require.NoError(err)
+	require.Equal(57, level)
+	data, err := os.ReadFile(filepath.Join(dir, "acpi_video0", "brightness"))
+	require.NoError(err)
+	require.Equal("4", string(data))
+
+	d, err = Open(dir, "intel_backlight")
+	require.NoError(err)
+	level, err = d.Brightness()
+	require.NoError(err)
+	require.Equal(50, level)
+
+	require.True(errors.Is(d.SetBrightness(101), os.ErrInvalid))
+
+	_, err = Open(dir, "missing")
+	require.True(errors.Is(err, os.ErrNotExist))
+	_, err = Open(t.TempDir(), "")
+	require.True(errors.Is(err, os.ErrNotExist))
+}
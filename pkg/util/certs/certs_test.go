@@ -0,0 +1,46 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureSelfSigned(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls", "server.crt")
+	keyPath := filepath.Join(dir, "tls", "server.key")
+
+	require.NoError(EnsureSelfSigned(certPath, keyPath, []string{"kiosk.local", "10.0.0.5"}))
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	require.NoError(err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(err)
+	require.NoError(leaf.VerifyHostname("kiosk.local"))
+	require.NoError(leaf.VerifyHostname("10.0.0.5"))
+
+	info, err := os.Stat(keyPath)
+	require.NoError(err)
+	require.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// valid certificate is reused
+	before, err := os.ReadFile(certPath)
+	require.NoError(err)
+	require.NoError(EnsureSelfSigned(certPath, keyPath, []string{"kiosk.local"}))
+	after, err := os.ReadFile(certPath)
+	require.NoError(err)
+	require.Equal(before, after)
+
+	pool, err := LoadCertPool(certPath)
+	require.NoError(err)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: "kiosk.local"})
+	require.NoError(err)
+}
@@ -0,0 +1,70 @@
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// DefaultJPEGQuality is used when quality is not set
+var DefaultJPEGQuality = 85
+
+// Encode writes img into w in given format: png or jpeg. Quality is used only for jpeg
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "", "png":
+		return png.Encode(w, img)
+	case "jpeg", "jpg":
+		if quality <= 0 {
+			quality = DefaultJPEGQuality
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	default:
+		return fmt.Errorf("unsupported image format %q", format)
+	}
+}
+
+// Thumbnail scales img down to width keeping aspect ratio. Each target pixel is average of
+// source pixels it covers. Images narrower than width are returned as is
+func Thumbnail(img image.Image, width int) image.Image {
+	src := img.Bounds()
+	if width <= 0 || width >= src.Dx() {
+		return img
+	}
+	height := src.Dy() * width / src.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := src.Min.Y + y*src.Dy()/height
+		y1 := src.Min.Y + (y+1)*src.Dy()/height
+		for x := 0; x < width; x++ {
+			x0 := src.Min.X + x*src.Dx()/width
+			x1 := src.Min.X + (x+1)*src.Dx()/width
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,41 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnail(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	img := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
+	for x := 0; x < 960; x++ {
+		for y := 0; y < 1080; y++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	thumb := Thumbnail(img, 320)
+	require.Equal(image.Rect(0, 0, 320, 180), thumb.Bounds())
+	r, _, _, _ := thumb.At(10, 10).RGBA()
+	require.Equal(uint32(0xffff), r)
+	r, _, _, _ = thumb.At(300, 10).RGBA()
+	require.Equal(uint32(0), r)
+
+	// images are never scaled up
+	require.Equal(img, Thumbnail(img, 4000))
+
+	buf := &bytes.Buffer{}
+	require.NoError(Encode(buf, thumb, "jpeg", 50))
+	decoded, err := jpeg.Decode(buf)
+	require.NoError(err)
+	require.Equal(thumb.Bounds(), decoded.Bounds())
+
+	require.Error(Encode(buf, thumb, "gif", 0))
+}
@@ -0,0 +1,83 @@
+package x11
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jezek/xgb/dpms"
+)
+
+// ErrDPMSNotCapable is returned when display can't control monitor power
+var ErrDPMSNotCapable = errors.New("display is not DPMS capable")
+
+// PowerLevel is DPMS power level of the monitor
+type PowerLevel uint16
+
+var (
+	PowerLevelOn      PowerLevel = dpms.DPMSModeOn
+	PowerLevelStandby PowerLevel = dpms.DPMSModeStandby
+	PowerLevelSuspend PowerLevel = dpms.DPMSModeSuspend
+	PowerLevelOff     PowerLevel = dpms.DPMSModeOff
+)
+
+// Power returns monitor power level. enabled is false when DPMS is disabled, monitor stays on then
+func (c *Conn) Power() (level PowerLevel, enabled bool, err error) {
+	err = c.initDPMS()
+	if err != nil {
+		return 0, false, err
+	}
+	info, err := dpms.Info(c.conn).Reply()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query power level: %w", err)
+	}
+	return PowerLevel(info.PowerLevel), info.State, nil
+}
+
+// SetPower turns monitor on or off. DPMS is disabled once monitor is on, so it is not blanked by DPMS timeouts
+func (c *Conn) SetPower(on bool) error {
+	err := c.initDPMS()
+	if err != nil {
+		return err
+	}
+
+	// level can be forced only while DPMS is enabled
+	err = dpms.EnableChecked(c.conn).Check()
+	if err != nil {
+		return fmt.Errorf("failed to enable DPMS: %w", err)
+	}
+	level := PowerLevelOff
+	if on {
+		level = PowerLevelOn
+	}
+	err = dpms.ForceLevelChecked(c.conn, uint16(level)).Check()
+	if err != nil {
+		return fmt.Errorf("failed to force power level: %w", err)
+	}
+	if !on {
+		return nil
+	}
+	err = dpms.DisableChecked(c.conn).Check()
+	if err != nil {
+		return fmt.Errorf("failed to disable DPMS: %w", err)
+	}
+	return nil
+}
+
+func (c *Conn) initDPMS() error {
+	if c.dpms {
+		return nil
+	}
+	err := dpms.Init(c.conn)
+	if err != nil {
+		return fmt.Errorf("failed to initialize DPMS extension: %w", err)
+	}
+	capable, err := dpms.Capable(c.conn).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to query DPMS capability: %w", err)
+	}
+	if !capable.Capable {
+		return ErrDPMSNotCapable
+	}
+	c.dpms = true
+	return nil
+}
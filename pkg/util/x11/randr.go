@@ -0,0 +1,445 @@
+package x11
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Rotation is rotation of output, left and right swap its width and height
+type Rotation string
+
+var (
+	RotationNormal   Rotation = "normal"
+	RotationLeft     Rotation = "left"
+	RotationInverted Rotation = "inverted"
+	RotationRight    Rotation = "right"
+
+	rotations = map[Rotation]uint16{
+		RotationNormal:   randr.RotationRotate0,
+		RotationLeft:     randr.RotationRotate90,
+		RotationInverted: randr.RotationRotate180,
+		RotationRight:    randr.RotationRotate270,
+	}
+
+	// ErrOutputDisabled is returned when mode is set on output which is turned off
+	ErrOutputDisabled = errors.New("output is disabled")
+)
+
+// Output is video output connected to the screen. Geometry of disabled output is size of its preferred mode.
+// Rotation and Rate are set for enabled outputs only
+type Output struct {
+	Name     string
+	Enabled  bool
+	X        int
+	Y        int
+	Width    int
+	Height   int
+	Rotation Rotation
+	Rate     float64
+	// Modes are modes output supports, preferred ones first
+	Modes []Mode
+}
+
+// Mode is resolution and refresh rate of output
+type Mode struct {
+	Width     int
+	Height    int
+	Rate      float64
+	Preferred bool
+}
+
+// OutputMode is mode and rotation set on output. Zero fields keep current value
+type OutputMode struct {
+	Width    int
+	Height   int
+	Rate     float64
+	Rotation Rotation
+}
+
+// Outputs returns connected outputs in order reported by X server
+func (c *Conn) Outputs() ([]Output, error) {
+	res, err := c.resources()
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []Output
+	for _, id := range res.Outputs {
+		info, err := randr.GetOutputInfo(c.conn, id, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get output info: %w", err)
+		}
+		if info.Connection != randr.ConnectionConnected {
+			continue
+		}
+
+		o := Output{Name: string(info.Name)}
+		for i, id := range info.Modes {
+			if mode := findMode(res, id); mode != nil {
+				o.Modes = append(o.Modes, Mode{
+					Width:     int(mode.Width),
+					Height:    int(mode.Height),
+					Rate:      modeRate(mode),
+					Preferred: i < int(info.NumPreferred),
+				})
+			}
+		}
+		crtc, err := c.crtc(res, info)
+		if err != nil {
+			return nil, err
+		}
+		if crtc != nil && crtc.Mode != 0 {
+			o.Enabled = true
+			o.X, o.Y, o.Width, o.Height = int(crtc.X), int(crtc.Y), int(crtc.Width), int(crtc.Height)
+			o.Rotation = rotationName(crtc.Rotation)
+			if mode := findMode(res, crtc.Mode); mode != nil {
+				o.Rate = modeRate(mode)
+			}
+		} else if mode := preferredMode(res, info); mode != nil {
+			o.Width, o.Height = int(mode.Width), int(mode.Height)
+		}
+		outputs = append(outputs, o)
+	}
+	return outputs, nil
+}
+
+// SetOutputEnabled turns output on with its preferred mode at x, y, or off by releasing its CRTC.
+// Monitors without signal go to standby, so this powers single monitor of multi monitor screen
+func (c *Conn) SetOutputEnabled(name string, enabled bool, x, y int) error {
+	res, err := c.resources()
+	if err != nil {
+		return err
+	}
+	id, info, err := c.output(res, name)
+	if err != nil {
+		return err
+	}
+	crtc, err := c.crtc(res, info)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		if crtc == nil || crtc.Mode == 0 {
+			return nil
+		}
+		return c.setCrtc(res, info.Crtc, 0, 0, 0, nil)
+	}
+	if crtc != nil && crtc.Mode != 0 {
+		return nil
+	}
+
+	mode := preferredMode(res, info)
+	if mode == nil {
+		return fmt.Errorf("output %s has no modes", name)
+	}
+	free, err := c.freeCrtc(res, info)
+	if err != nil {
+		return err
+	}
+	err = c.fitScreen(x+int(mode.Width), y+int(mode.Height))
+	if err != nil {
+		return err
+	}
+	return c.setCrtc(res, free, x, y, randr.Mode(mode.Id), []randr.Output{id})
+}
+
+// SetOutputMode changes resolution, refresh rate and rotation of enabled output. Zero width and height keep
+// current resolution, zero rate selects preferred or fastest mode of the resolution. Screen is resized to
+// contain all outputs
+func (c *Conn) SetOutputMode(name string, mode OutputMode) error {
+	res, err := c.resources()
+	if err != nil {
+		return err
+	}
+	id, info, err := c.output(res, name)
+	if err != nil {
+		return err
+	}
+	crtc, err := c.crtc(res, info)
+	if err != nil {
+		return err
+	}
+	if crtc == nil || crtc.Mode == 0 {
+		return fmt.Errorf("output %s: %w", name, ErrOutputDisabled)
+	}
+
+	current := findMode(res, crtc.Mode)
+	if current == nil {
+		return fmt.Errorf("output %s has unknown mode %d", name, crtc.Mode)
+	}
+	target := current
+	if mode.Width != 0 || mode.Height != 0 || mode.Rate != 0 {
+		width, height := mode.Width, mode.Height
+		if width == 0 || height == 0 {
+			width, height = int(current.Width), int(current.Height)
+		}
+		target, err = selectMode(res, info, width, height, mode.Rate)
+		if err != nil {
+			return fmt.Errorf("output %s: %w", name, err)
+		}
+	}
+
+	rotation := crtc.Rotation & 0x0f
+	if mode.Rotation != "" {
+		var ok bool
+		rotation, ok = rotations[mode.Rotation]
+		if !ok {
+			return fmt.Errorf("unknown rotation %q: %w", mode.Rotation, os.ErrInvalid)
+		}
+		if crtc.Rotations&rotation == 0 {
+			return fmt.Errorf("output %s does not support %s rotation: %w", name, mode.Rotation, os.ErrInvalid)
+		}
+	}
+	if target.Id == current.Id && rotation == crtc.Rotation&0x0f {
+		return nil
+	}
+
+	// screen must contain all CRTCs while they change, so it grows first and shrinks to fit them afterwards
+	width, height := int(target.Width), int(target.Height)
+	if rotation == randr.RotationRotate90 || rotation == randr.RotationRotate270 {
+		width, height = height, width
+	}
+	bounds, err := c.crtcBounds(res, info.Crtc)
+	if err != nil {
+		return err
+	}
+	right, bottom := max(bounds.right, int(crtc.X)+width), max(bounds.bottom, int(crtc.Y)+height)
+	err = c.fitScreen(right, bottom)
+	if err != nil {
+		return err
+	}
+	err = c.setCrtcRotation(res, info.Crtc, int(crtc.X), int(crtc.Y), randr.Mode(target.Id), rotation, []randr.Output{id})
+	if err != nil {
+		return err
+	}
+	return c.setScreenSize(right, bottom)
+}
+
+// crtcBounds returns bottom right corner of area covered by active CRTCs except skipped one
+func (c *Conn) crtcBounds(res *randr.GetScreenResourcesCurrentReply, skip randr.Crtc) (struct{ right, bottom int }, error) {
+	bounds := struct{ right, bottom int }{}
+	for _, id := range res.Crtcs {
+		if id == skip {
+			continue
+		}
+		crtc, err := randr.GetCrtcInfo(c.conn, id, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return bounds, fmt.Errorf("failed to get CRTC info: %w", err)
+		}
+		if crtc.Mode == 0 {
+			continue
+		}
+		bounds.right = max(bounds.right, int(crtc.X)+int(crtc.Width))
+		bounds.bottom = max(bounds.bottom, int(crtc.Y)+int(crtc.Height))
+	}
+	return bounds, nil
+}
+
+func (c *Conn) initRandR() error {
+	if c.randr {
+		return nil
+	}
+	err := randr.Init(c.conn)
+	if err != nil {
+		return fmt.Errorf("failed to initialize RandR extension: %w", err)
+	}
+	c.randr = true
+	return nil
+}
+
+func (c *Conn) resources() (*randr.GetScreenResourcesCurrentReply, error) {
+	err := c.initRandR()
+	if err != nil {
+		return nil, err
+	}
+	res, err := randr.GetScreenResourcesCurrent(c.conn, c.root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screen resources: %w", err)
+	}
+	return res, nil
+}
+
+// output finds connected output by name
+func (c *Conn) output(res *randr.GetScreenResourcesCurrentReply, name string) (randr.Output, *randr.GetOutputInfoReply, error) {
+	for _, id := range res.Outputs {
+		info, err := randr.GetOutputInfo(c.conn, id, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get output info: %w", err)
+		}
+		if string(info.Name) == name && info.Connection == randr.ConnectionConnected {
+			return id, info, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("output %s: %w", name, os.ErrNotExist)
+}
+
+// crtc returns CRTC output is shown on, nil when output has none
+func (c *Conn) crtc(res *randr.GetScreenResourcesCurrentReply, info *randr.GetOutputInfoReply) (*randr.GetCrtcInfoReply, error) {
+	if info.Crtc == 0 {
+		return nil, nil
+	}
+	crtc, err := randr.GetCrtcInfo(c.conn, info.Crtc, res.ConfigTimestamp).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CRTC info: %w", err)
+	}
+	return crtc, nil
+}
+
+// freeCrtc returns CRTC output can be shown on which does not drive other outputs
+func (c *Conn) freeCrtc(res *randr.GetScreenResourcesCurrentReply, info *randr.GetOutputInfoReply) (randr.Crtc, error) {
+	if info.Crtc != 0 {
+		return info.Crtc, nil
+	}
+	for _, id := range info.Crtcs {
+		crtc, err := randr.GetCrtcInfo(c.conn, id, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get CRTC info: %w", err)
+		}
+		if len(crtc.Outputs) == 0 {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("no free CRTC for output %s", info.Name)
+}
+
+func (c *Conn) setCrtc(res *randr.GetScreenResourcesCurrentReply, crtc randr.Crtc, x, y int, mode randr.Mode, outputs []randr.Output) error {
+	return c.setCrtcRotation(res, crtc, x, y, mode, randr.RotationRotate0, outputs)
+}
+
+func (c *Conn) setCrtcRotation(res *randr.GetScreenResourcesCurrentReply, crtc randr.Crtc, x, y int, mode randr.Mode, rotation uint16, outputs []randr.Output) error {
+	reply, err := randr.SetCrtcConfig(c.conn, crtc, xproto.TimeCurrentTime, res.ConfigTimestamp,
+		int16(x), int16(y), mode, rotation, outputs).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to configure CRTC: %w", err)
+	}
+	if reply.Status != randr.SetConfigSuccess {
+		return fmt.Errorf("failed to configure CRTC, status %d", reply.Status)
+	}
+	return nil
+}
+
+// fitScreen grows screen so it contains width x height area. Physical size is scaled keeping current DPI
+func (c *Conn) fitScreen(width, height int) error {
+	w, h, err := c.screenSize()
+	if err != nil {
+		return err
+	}
+	if width <= w && height <= h {
+		return nil
+	}
+	return c.setScreenSize(max(width, w), max(height, h))
+}
+
+func (c *Conn) screenSize() (int, int, error) {
+	current, err := xproto.GetGeometry(c.conn, xproto.Drawable(c.root)).Reply()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get screen size: %w", err)
+	}
+	return int(current.Width), int(current.Height), nil
+}
+
+// setScreenSize resizes screen to width x height. Physical size is scaled keeping DPI of the screen
+func (c *Conn) setScreenSize(width, height int) error {
+	w, h, err := c.screenSize()
+	if err != nil {
+		return err
+	}
+	if width == w && height == h {
+		return nil
+	}
+	screen := xproto.Setup(c.conn).DefaultScreen(c.conn)
+	mmWidth := uint32(width) * uint32(screen.WidthInMillimeters) / uint32(screen.WidthInPixels)
+	mmHeight := uint32(height) * uint32(screen.HeightInMillimeters) / uint32(screen.HeightInPixels)
+	err = randr.SetScreenSizeChecked(c.conn, c.root, uint16(width), uint16(height), mmWidth, mmHeight).Check()
+	if err != nil {
+		return fmt.Errorf("failed to resize screen to %dx%d: %w", width, height, err)
+	}
+	return nil
+}
+
+// preferredMode returns mode output prefers, nil when it has no modes. Preferred modes are listed first
+func preferredMode(res *randr.GetScreenResourcesCurrentReply, info *randr.GetOutputInfoReply) *randr.ModeInfo {
+	if len(info.Modes) == 0 {
+		return nil
+	}
+	return findMode(res, info.Modes[0])
+}
+
+// selectMode returns mode of output with given resolution. Mode closest to rate is selected when rate is set,
+// preferred or the fastest one otherwise
+func selectMode(res *randr.GetScreenResourcesCurrentReply, info *randr.GetOutputInfoReply, width, height int, rate float64) (*randr.ModeInfo, error) {
+	var selected *randr.ModeInfo
+	for i, id := range info.Modes {
+		mode := findMode(res, id)
+		if mode == nil || int(mode.Width) != width || int(mode.Height) != height {
+			continue
+		}
+		// preferred mode wins unless rate is requested
+		if rate == 0 && i < int(info.NumPreferred) {
+			return mode, nil
+		}
+		switch {
+		case selected == nil:
+			selected = mode
+		case rate != 0 && math.Abs(modeRate(mode)-rate) < math.Abs(modeRate(selected)-rate):
+			selected = mode
+		case rate == 0 && modeRate(mode) > modeRate(selected):
+			selected = mode
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("mode %dx%d is not supported: %w", width, height, os.ErrInvalid)
+	}
+	// rates are rounded differently by tools, so close enough rate is accepted
+	if rate != 0 && math.Abs(modeRate(selected)-rate) > 0.5 {
+		return nil, fmt.Errorf("mode %dx%d at %.2fHz is not supported: %w", width, height, rate, os.ErrInvalid)
+	}
+	return selected, nil
+}
+
+func findMode(res *randr.GetScreenResourcesCurrentReply, id randr.Mode) *randr.ModeInfo {
+	for i := range res.Modes {
+		if randr.Mode(res.Modes[i].Id) == id {
+			return &res.Modes[i]
+		}
+	}
+	return nil
+}
+
+// modeRate returns refresh rate of mode in Hz rounded to two decimals
+func modeRate(mode *randr.ModeInfo) float64 {
+	vTotal := float64(mode.Vtotal)
+	if mode.ModeFlags&randr.ModeFlagDoubleScan != 0 {
+		vTotal *= 2
+	}
+	if mode.ModeFlags&randr.ModeFlagInterlace != 0 {
+		vTotal /= 2
+	}
+	if mode.Htotal == 0 || vTotal == 0 {
+		return 0
+	}
+	rate := float64(mode.DotClock) / (float64(mode.Htotal) * vTotal)
+	return math.Round(rate*100) / 100
+}
+
+func rotationName(rotation uint16) Rotation {
+	for name, r := range rotations {
+		if rotation&0x0f == r {
+			return name
+		}
+	}
+	return RotationNormal
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
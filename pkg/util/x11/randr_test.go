@@ -0,0 +1,78 @@
+package x11
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jezek/xgb/randr"
+	"github.com/stretchr/testify/require"
+)
+
+// mode returns mode with given refresh rate, clock is scaled to it
+func mode(id uint32, width, height uint16, rate float64) randr.ModeInfo {
+	return randr.ModeInfo{
+		Id:       id,
+		Width:    width,
+		Height:   height,
+		Htotal:   2200,
+		Vtotal:   1125,
+		DotClock: uint32(rate * 2200 * 1125),
+	}
+}
+
+func TestSelectMode(t *testing.T) {
+	t.Parallel()
+
+	res := &randr.GetScreenResourcesCurrentReply{Modes: []randr.ModeInfo{
+		mode(1, 1920, 1080, 60),
+		mode(2, 1920, 1080, 75),
+		mode(3, 1920, 1080, 59.94),
+		mode(4, 1280, 720, 50),
+		mode(5, 1280, 720, 60),
+	}}
+	info := &randr.GetOutputInfoReply{Modes: []randr.Mode{1, 2, 3, 4, 5}, NumPreferred: 1}
+
+	for _, tc := range []struct {
+		name   string
+		width  int
+		height int
+		rate   float64
+		id     uint32
+		err    bool
+	}{
+		{name: "preferred", width: 1920, height: 1080, id: 1},
+		{name: "closest rate", width: 1920, height: 1080, rate: 59.9, id: 3},
+		{name: "fastest", width: 1280, height: 720, id: 5},
+		{name: "unsupported rate", width: 1280, height: 720, rate: 75, err: true},
+		{name: "unsupported size", width: 800, height: 600, err: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			selected, err := selectMode(res, info, tc.width, tc.height, tc.rate)
+			if tc.err {
+				require.True(errors.Is(err, os.ErrInvalid))
+				return
+			}
+			require.NoError(err)
+			require.Equal(tc.id, selected.Id)
+		})
+	}
+}
+
+func TestModeRate(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	m := mode(1, 1920, 1080, 59.94)
+	require.Equal(59.94, modeRate(&m))
+
+	// interlaced modes draw half of lines per field
+	m.ModeFlags = randr.ModeFlagInterlace
+	require.Equal(119.88, modeRate(&m))
+
+	require.Equal(RotationLeft, rotationName(randr.RotationRotate90|randr.RotationReflectX))
+}
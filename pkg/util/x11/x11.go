@@ -0,0 +1,239 @@
+package x11
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+)
+
+// maxTreeDepth limits how deep window tree is searched for application windows
+var maxTreeDepth = 4
+
+// Window is X11 window id
+type Window uint32
+
+// Geometry is window position relative to the screen and its size
+type Geometry struct {
+	X          int
+	Y          int
+	Width      int
+	Height     int
+	Fullscreen bool
+}
+
+// Conn is connection to X server of DISPLAY
+type Conn struct {
+	conn *xgb.Conn
+	root xproto.Window
+	// screensaver is set once MIT-SCREEN-SAVER extension is initialized
+	screensaver bool
+	// dpms is set once DPMS extension is initialized and display is capable of it
+	dpms bool
+	// randr is set once RandR extension is initialized
+	randr bool
+}
+
+func Connect() (*Conn, error) {
+	return ConnectDisplay("")
+}
+
+// ConnectDisplay connects to X server of display, e.g. :0. DISPLAY is used when display is empty
+func ConnectDisplay(display string) (*Conn, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to X server: %w", err)
+	}
+	return &Conn{
+		conn: conn,
+		root: xproto.Setup(conn).DefaultScreen(conn).Root,
+	}, nil
+}
+
+func (c *Conn) Close() {
+	c.conn.Close()
+}
+
+// FindWindow returns largest viewable window owned by process pid. Window owner
+// is taken from _NET_WM_PID property which toolkits set on application windows
+func (c *Conn) FindWindow(pid int) (Window, error) {
+	pidAtom, err := c.atom("_NET_WM_PID")
+	if err != nil {
+		return 0, err
+	}
+
+	var found xproto.Window
+	var area int
+	var walk func(w xproto.Window, depth int) error
+	walk = func(w xproto.Window, depth int) error {
+		if depth > maxTreeDepth {
+			return nil
+		}
+		tree, err := xproto.QueryTree(c.conn, w).Reply()
+		if err != nil {
+			return err
+		}
+		for _, child := range tree.Children {
+			if c.ownedBy(child, pidAtom, pid) {
+				if a := c.viewableArea(child); a > area {
+					found, area = child, a
+				}
+			}
+			err := walk(child, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(c.root, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list windows: %w", err)
+	}
+	if found == 0 {
+		return 0, fmt.Errorf("window of process %d: %w", pid, os.ErrNotExist)
+	}
+	return Window(found), nil
+}
+
+// Geometry returns window position relative to root window, size and fullscreen state
+func (c *Conn) Geometry(w Window) (Geometry, error) {
+	geometry, err := xproto.GetGeometry(c.conn, xproto.Drawable(w)).Reply()
+	if err != nil {
+		return Geometry{}, fmt.Errorf("failed to get window geometry: %w", err)
+	}
+	position, err := xproto.TranslateCoordinates(c.conn, xproto.Window(w), c.root, 0, 0).Reply()
+	if err != nil {
+		return Geometry{}, fmt.Errorf("failed to get window position: %w", err)
+	}
+	fullscreen, err := c.fullscreen(w)
+	if err != nil {
+		return Geometry{}, err
+	}
+
+	return Geometry{
+		X:          int(position.DstX),
+		Y:          int(position.DstY),
+		Width:      int(geometry.Width),
+		Height:     int(geometry.Height),
+		Fullscreen: fullscreen,
+	}, nil
+}
+
+// Configure moves and resizes window. Zero width or height keeps window size
+func (c *Conn) Configure(w Window, x, y, width, height int) error {
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY)
+	values := []uint32{uint32(int32(x)), uint32(int32(y))}
+	if width > 0 && height > 0 {
+		mask |= xproto.ConfigWindowWidth | xproto.ConfigWindowHeight
+		values = append(values, uint32(width), uint32(height))
+	}
+
+	err := xproto.ConfigureWindowChecked(c.conn, xproto.Window(w), mask, values).Check()
+	if err != nil {
+		return fmt.Errorf("failed to configure window: %w", err)
+	}
+	return nil
+}
+
+// SetFullscreen asks window manager to add or remove fullscreen state of window.
+// It has no effect without EWMH compliant window manager
+func (c *Conn) SetFullscreen(w Window, fullscreen bool) error {
+	state, err := c.atom("_NET_WM_STATE")
+	if err != nil {
+		return err
+	}
+	fullscreenAtom, err := c.atom("_NET_WM_STATE_FULLSCREEN")
+	if err != nil {
+		return err
+	}
+
+	// _NET_WM_STATE_REMOVE is 0, _NET_WM_STATE_ADD is 1. Last but one value marks request from application
+	action := uint32(0)
+	if fullscreen {
+		action = 1
+	}
+	event := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: xproto.Window(w),
+		Type:   state,
+		Data:   xproto.ClientMessageDataUnionData32New([]uint32{action, uint32(fullscreenAtom), 0, 1, 0}),
+	}
+	mask := uint32(xproto.EventMaskSubstructureNotify | xproto.EventMaskSubstructureRedirect)
+	err = xproto.SendEventChecked(c.conn, false, c.root, mask, string(event.Bytes())).Check()
+	if err != nil {
+		return fmt.Errorf("failed to change fullscreen state: %w", err)
+	}
+	return nil
+}
+
+func (c *Conn) fullscreen(w Window) (bool, error) {
+	state, err := c.atom("_NET_WM_STATE")
+	if err != nil {
+		return false, err
+	}
+	fullscreenAtom, err := c.atom("_NET_WM_STATE_FULLSCREEN")
+	if err != nil {
+		return false, err
+	}
+
+	reply, err := xproto.GetProperty(c.conn, false, xproto.Window(w), state, xproto.AtomAtom, 0, 32).Reply()
+	if err != nil {
+		return false, fmt.Errorf("failed to get window state: %w", err)
+	}
+	for i := 0; i+4 <= len(reply.Value); i += 4 {
+		if xproto.Atom(xgb.Get32(reply.Value[i:])) == fullscreenAtom {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IdleTime returns time since last user input on the display. It requires MIT-SCREEN-SAVER extension
+func (c *Conn) IdleTime() (time.Duration, error) {
+	if !c.screensaver {
+		err := screensaver.Init(c.conn)
+		if err != nil {
+			return 0, fmt.Errorf("failed to initialize screensaver extension: %w", err)
+		}
+		c.screensaver = true
+	}
+
+	info, err := screensaver.QueryInfo(c.conn, xproto.Drawable(c.root)).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query idle time: %w", err)
+	}
+	return time.Duration(info.MsSinceUserInput) * time.Millisecond, nil
+}
+
+func (c *Conn) ownedBy(w xproto.Window, pidAtom xproto.Atom, pid int) bool {
+	reply, err := xproto.GetProperty(c.conn, false, w, pidAtom, xproto.AtomCardinal, 0, 1).Reply()
+	if err != nil || reply.Format != 32 || len(reply.Value) < 4 {
+		return false
+	}
+	return int(xgb.Get32(reply.Value)) == pid
+}
+
+func (c *Conn) viewableArea(w xproto.Window) int {
+	attrs, err := xproto.GetWindowAttributes(c.conn, w).Reply()
+	if err != nil || attrs.MapState != xproto.MapStateViewable {
+		return 0
+	}
+	geometry, err := xproto.GetGeometry(c.conn, xproto.Drawable(w)).Reply()
+	if err != nil {
+		return 0
+	}
+	return int(geometry.Width) * int(geometry.Height)
+}
+
+func (c *Conn) atom(name string) (xproto.Atom, error) {
+	reply, err := xproto.InternAtom(c.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get atom %s: %w", name, err)
+	}
+	return reply.Atom, nil
+}
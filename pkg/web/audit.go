@@ -0,0 +1,115 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+var (
+	// defaultAuditLimit and maxAuditLimit bound number of audit entries returned at once
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// getAudit returns audit entries, newest first. Supports from and to (RFC3339) and limit query parameters
+func (s *Service) getAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var from, to time.Time
+	var err error
+	if v := q.Get("from"); v != "" {
+		from, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid from %q, expected RFC3339 time", v))
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		to, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid to %q, expected RFC3339 time", v))
+			return
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		s.writeError(w, r, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	limit := defaultAuditLimit
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit))
+			return
+		}
+	}
+
+	entries, err := s.audit.Query(from, to, limit)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to query audit log: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, entries)
+}
+
+// record writes action handled by web service itself to audit log, with caller of the request. Result
+// is derived from err unless it is set in entry
+func (s *Service) record(r *http.Request, entry api.AuditEntry, err error) {
+	caller := callerFromRequest(r)
+	entry.Identity = caller.Identity
+	entry.Address = caller.Address
+	entry.RequestID = caller.RequestID
+	if entry.Result == "" {
+		entry.Result = api.AuditResultSuccess
+		if err != nil {
+			entry.Result = api.AuditResultError
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	err = s.audit.Record(entry)
+	if err != nil {
+		s.log.Error("failed to record audit entry", zap.String("action", entry.Action), zap.Error(err))
+	}
+}
+
+// recordDenied writes request rejected by authentication or authorization to audit log. Reads are not
+// recorded, as they do not change kiosk, and denied requests of single caller are recorded once per interval
+func (s *Service) recordDenied(r *http.Request, err error) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return
+	}
+	caller := callerFromRequest(r)
+	if !s.denied.Allow(caller.Address+"/"+caller.Identity, time.Now()) {
+		return
+	}
+	// display of rejected request is not validated, unknown display is recorded as the first one
+	display, _ := strconv.Atoi(r.URL.Query().Get("display"))
+	s.record(r, api.AuditEntry{
+		Display: display,
+		Action:  r.Method + " " + r.URL.Path,
+		Result:  api.AuditResultDenied,
+	}, err)
+}
+
+// recordFailed writes kiosk request which failed before kiosk handled it to audit log. Requests
+// handled by kiosk are recorded by kiosk itself
+func (s *Service) recordFailed(r *http.Request, payload api.KioskRequest, err error) {
+	// reads do not change kiosk
+	if !payload.Action.Mutating() {
+		return
+	}
+	s.record(r, api.AuditEntry{
+		Display: payload.TargetDisplay(),
+		Action:  payload.Action.String(),
+	}, err)
+}
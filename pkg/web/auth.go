@@ -0,0 +1,144 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/auth"
+	"github.com/unikiosk/unikiosk/pkg/web/response"
+)
+
+// authMiddleware authenticates api callers and checks if their role allows the request.
+// Landing page content is never protected, as it is loaded by the kiosk browser itself.
+// Token management is protected even when api is not, so tokens can be created only by
+// admin token configured via API_TOKENS
+func (s *Service) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := requiredRole(r)
+		if required == auth.RoleNone || (!s.auth.Enabled() && !isTokensPath(r.URL.Path)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := s.auth.Authenticate(tokenFromRequest(r))
+		if err != nil {
+			s.recordDenied(r, err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="unikiosk"`)
+			s.writeError(w, r, http.StatusUnauthorized, "valid api token is required")
+			return
+		}
+		if !id.Role.Allows(required) {
+			msg := fmt.Sprintf("role %s is not allowed to perform this request", id.Role)
+			s.log.Warn("request forbidden", zap.String("requestId", response.RequestID(r.Context())), zap.String("identity", id.Name), zap.String("path", r.URL.Path))
+			s.recordDenied(r.WithContext(auth.WithIdentity(r.Context(), id)), errors.New(msg))
+			s.writeError(w, r, http.StatusForbidden, msg)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), id)))
+	})
+}
+
+// requiredRole returns role required to execute the request
+func requiredRole(r *http.Request) auth.Role {
+	path := r.URL.Path
+	switch {
+	case path != "/api" && !strings.HasPrefix(path, "/api/"):
+		return auth.RoleNone
+	case path == "/api/openapi.json":
+		return auth.RoleNone
+	case isTokensPath(path), path == "/api/v1/audit":
+		return auth.RoleAdmin
+	// cookies carry sessions of the page
+	case path == "/api/v1/browser/cookies":
+		return auth.RoleOperator
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return auth.RoleViewer
+	default:
+		return auth.RoleOperator
+	}
+}
+
+func isTokensPath(path string) bool {
+	return path == "/api/v1/tokens" || strings.HasPrefix(path, "/api/v1/tokens/")
+}
+
+// tokenFromRequest returns bearer token. Query parameter is supported for clients
+// which can't set headers, like browser EventSource and WebSocket
+func tokenFromRequest(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+func (s *Service) listTokens(w http.ResponseWriter, r *http.Request) {
+	tokens := s.auth.ListTokens()
+	result := make([]api.Token, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, api.Token{
+			ID:      t.ID,
+			Name:    t.Name,
+			Role:    string(t.Role),
+			Created: t.Created,
+		})
+	}
+	s.writeJSON(w, r, http.StatusOK, result)
+}
+
+func (s *Service) createToken(w http.ResponseWriter, r *http.Request) {
+	in := api.Token{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if in.Name == "" {
+		s.writeError(w, r, http.StatusBadRequest, "name is required")
+		return
+	}
+	role, err := auth.StringToRole(in.Role)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid role %q, expected one of: viewer, operator, admin", in.Role))
+		return
+	}
+
+	t, secret, err := s.auth.CreateToken(in.Name, role)
+	entry := api.AuditEntry{Action: api.AuditActionCreateToken}
+	if t != nil {
+		entry.Token = t.ID
+	}
+	s.record(r, entry, err)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to create token: %s", err))
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusCreated, api.Token{
+		ID:      t.ID,
+		Name:    t.Name,
+		Role:    string(t.Role),
+		Created: t.Created,
+		Token:   secret,
+	})
+}
+
+func (s *Service) deleteToken(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	err := s.auth.DeleteToken(id)
+	s.record(r, api.AuditEntry{Action: api.AuditActionDeleteToken, Token: id}, err)
+	if errors.Is(err, auth.ErrTokenNotFound) {
+		s.writeError(w, r, http.StatusNotFound, "token not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to delete token: %s", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
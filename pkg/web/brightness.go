@@ -0,0 +1,43 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// getBrightness returns backlight brightness of display with its schedule
+func (s *Service) getBrightness(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	s.updateBrightness(w, r, api.KioskRequest{Action: api.ScreenActionGetBrightness, Display: display}, "failed to get brightness")
+}
+
+// putBrightness sets brightness level and schedule. Level set by hand lasts until the next point of schedule
+func (s *Service) putBrightness(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	in := api.BrightnessConfig{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if in.Level == nil && in.Schedule == nil {
+		s.writeError(w, r, http.StatusBadRequest, "one of level or schedule is required")
+		return
+	}
+	s.updateBrightness(w, r, api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &in, Display: display}, "failed to set brightness")
+}
+
+func (s *Service) updateBrightness(w http.ResponseWriter, r *http.Request, payload api.KioskRequest, message string) {
+	result, err := s.update(r, payload)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("%s: %s", message, err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, result.Brightness)
+}
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unikiosk/unikiosk/pkg/console"
+)
+
+// getConsole returns last browser console messages and page exceptions of display, newest first.
+// Supports level (minimal level: debug, info, warning, error) and limit query parameters
+func (s *Service) getConsole(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	buffer, ok := s.consoles[display]
+	if !ok {
+		s.writeError(w, r, http.StatusNotFound, fmt.Sprintf("display %d has no console", display))
+		return
+	}
+	q := r.URL.Query()
+
+	level := q.Get("level")
+	if level != "" && console.Severity(level) == 0 {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid level %q, expected one of: debug, info, warning, error", level))
+		return
+	}
+
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			s.writeError(w, r, http.StatusBadRequest, "limit must be positive")
+			return
+		}
+	}
+
+	s.writeJSON(w, r, http.StatusOK, buffer.List(level, limit))
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// getCookies returns browser cookies. Supports domain (cookies of domain and its subdomains) and name query parameters
+func (s *Service) getCookies(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{
+		Action:        api.ScreenActionGetCookies,
+		CookieOptions: cookieOptions(r),
+		Display:       display,
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get cookies: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, cookiesOrEmpty(result.Cookies))
+}
+
+// setCookies creates or replaces cookies given in request body
+func (s *Service) setCookies(w http.ResponseWriter, r *http.Request) {
+	in := []api.Cookie{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := s.update(r, api.KioskRequest{
+		Action:  api.ScreenActionSetCookies,
+		Cookies: in,
+		Display: display,
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to set cookies: %s", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteCookies removes cookies selected by domain and name query parameters, all cookies when none is set.
+// Removed cookies are returned
+func (s *Service) deleteCookies(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{
+		Action:        api.ScreenActionDeleteCookies,
+		CookieOptions: cookieOptions(r),
+		Display:       display,
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to delete cookies: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, cookiesOrEmpty(result.Cookies))
+}
+
+// clearData removes cookies, storage or cache of the browser
+func (s *Service) clearData(w http.ResponseWriter, r *http.Request) {
+	in := api.ClearData{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := s.update(r, api.KioskRequest{
+		Action:  api.ScreenActionClearData,
+		Display: display,
+		ClearOptions: &api.ClearOptions{
+			Cookies: in.Cookies,
+			Storage: in.Storage,
+			Cache:   in.Cache,
+			Origins: in.Origins,
+		},
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to clear browser data: %s", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func cookieOptions(r *http.Request) *api.CookieOptions {
+	q := r.URL.Query()
+	return &api.CookieOptions{
+		Domain: q.Get("domain"),
+		Name:   q.Get("name"),
+	}
+}
+
+// cookiesOrEmpty makes no cookies encode as empty list
+func cookiesOrEmpty(cookies []api.Cookie) []api.Cookie {
+	if cookies == nil {
+		return []api.Cookie{}
+	}
+	return cookies
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// defaultErrorTemplate is error page used when PageErrorTemplate is not configured
+var defaultErrorTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>UniKiosk</title>
+<style>
+body { margin: 0; height: 100vh; display: flex; align-items: center; justify-content: center;
+  background: #1f2937; color: #f9fafb; font-family: sans-serif; text-align: center; }
+.url, .error { color: #9ca3af; font-size: 0.9em; word-break: break-all; }
+</style>
+</head>
+<body>
+<div>
+<h1>Content is temporarily unavailable</h1>
+<p>It will be shown again as soon as it can be loaded.</p>
+{{if .URL}}<p class="url">{{.URL}}</p>{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+</div>
+</body>
+</html>
+`
+
+// errorPageData is passed to error page template
+type errorPageData struct {
+	// URL is content which failed to load, Error describes why
+	URL   string
+	Error string
+}
+
+// loadErrorTemplate parses error page template from file, built-in template is used when file is empty
+func loadErrorTemplate(file string) (*template.Template, error) {
+	if file == "" {
+		return template.Must(template.New("error").Parse(defaultErrorTemplate)), nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read error page template: %w", err)
+	}
+	t, err := template.New("error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse error page template: %w", err)
+	}
+	return t, nil
+}
+
+// errorPage is shown by the browser while content fails to load. Supports url and error query parameters
+func (s *Service) errorPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := errorPageData{
+		URL:   q.Get("url"),
+		Error: q.Get("error"),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	err := s.errorTemplate.Execute(w, data)
+	if err != nil {
+		s.log.Error("failed to render error page", zap.Error(err))
+	}
+}
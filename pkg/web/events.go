@@ -0,0 +1,156 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+var (
+	// streamBufferSize is number of events buffered per remote client before events are dropped
+	streamBufferSize = 32
+	// streamHeartbeatInterval is interval at which keep-alive messages are sent to SSE clients
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+// streamEvents streams kiosk notifications to remote clients. WebSocket is used when
+// client requests connection upgrade, Server-Sent Events otherwise
+func (s *Service) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		server := websocket.Server{
+			// CORS is handled by the router, accept any origin
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler:   s.streamWebSocket,
+		}
+		server.ServeHTTP(w, r)
+		return
+	}
+	s.streamSSE(w, r)
+}
+
+func (s *Service) streamSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	// subscribe before responding so client does not miss events published right after connecting
+	events := s.subscribe(r.Context())
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				s.log.Error("failed to marshal event", zap.Error(err))
+				continue
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Service) streamWebSocket(ws *websocket.Conn) {
+	defer ws.Close()
+
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+
+	// client is not expected to send anything, read only to detect closed connection
+	go func() {
+		defer cancel()
+		var discard []byte
+		for {
+			if err := websocket.Message.Receive(ws, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	events := s.subscribe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			err := websocket.JSON.Send(ws, event)
+			if err != nil {
+				s.log.Debug("websocket client disconnected", zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// subscribe subscribes to kiosk notifications. Events are buffered so slow clients
+// would not block eventer, and dropped once buffer is full
+func (s *Service) subscribe(ctx context.Context) <-chan api.StreamEvent {
+	out := make(chan api.StreamEvent, streamBufferSize)
+	listener := s.events.Subscribe(ctx)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-listener:
+				if !ok {
+					return
+				}
+				if event.Payload.Type == api.EventTypeRequest {
+					continue
+				}
+
+				select {
+				case out <- api.StreamEvent{
+					Type:    event.Payload.Type,
+					Time:    time.Now(),
+					State:   api.ResponseToState(event.Payload.Response),
+					Message: event.Payload.Console,
+				}:
+				default:
+					s.log.Warn("event stream buffer is full, dropping event", zap.String("type", string(event.Payload.Type)))
+				}
+			}
+		}
+	}()
+
+	return out
+}
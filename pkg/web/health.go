@@ -0,0 +1,26 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/unikiosk/unikiosk/pkg/health"
+)
+
+// healthz reports if kiosk is alive. Only liveness components make it fail
+func (s *Service) healthz(w http.ResponseWriter, r *http.Request) {
+	s.writeHealth(w, r, s.health.Live(r.Context()))
+}
+
+// readyz reports if kiosk is ready to display content. Any failing component makes it fail
+func (s *Service) readyz(w http.ResponseWriter, r *http.Request) {
+	s.writeHealth(w, r, s.health.Ready(r.Context()))
+}
+
+func (s *Service) writeHealth(w http.ResponseWriter, r *http.Request, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeJSON(w, r, status, report)
+}
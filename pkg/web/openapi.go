@@ -0,0 +1,23 @@
+package web
+
+import (
+	_ "embed"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// openAPISpec is OpenAPI document describing management api. Keep it in sync with router
+//go:embed openapi.json
+var openAPISpec []byte
+
+func (s *Service) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", api.ContentTypeApplicationJSON)
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(openAPISpec)
+	if err != nil {
+		s.log.Error("failed to write openapi spec", zap.Error(err))
+	}
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// getOutputs lists video outputs of the screen with modes they support
+func (s *Service) getOutputs(w http.ResponseWriter, r *http.Request) {
+	result, err := s.update(r, api.KioskRequest{Action: api.ScreenActionGetOutputs})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to list outputs: %s", err))
+		return
+	}
+	outputs := result.Outputs
+	if outputs == nil {
+		outputs = []api.Output{}
+	}
+	s.writeJSON(w, r, http.StatusOK, outputs)
+}
+
+func (s *Service) getOutput(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	result, err := s.update(r, api.KioskRequest{Action: api.ScreenActionGetOutputs})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get output: %s", err))
+		return
+	}
+	s.writeOutput(w, r, name, result.Outputs)
+}
+
+// putOutput changes resolution, refresh rate and rotation of output. Fields which are not set keep current value
+func (s *Service) putOutput(w http.ResponseWriter, r *http.Request) {
+	in := api.OutputConfig{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	in.Name = mux.Vars(r)["name"]
+	if !api.ValidRotation(in.Rotation) {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid rotation %q, expected one of: normal, left, inverted, right", in.Rotation))
+		return
+	}
+	if in.Width < 0 || in.Height < 0 || in.Rate < 0 || (in.Width == 0) != (in.Height == 0) {
+		s.writeError(w, r, http.StatusBadRequest, "width and height must be both set and positive")
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{
+		Action:  api.ScreenActionSetOutput,
+		Output:  &in,
+		Display: s.outputDisplay(in.Name),
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to configure output: %s", err))
+		return
+	}
+	s.writeOutput(w, r, in.Name, result.Outputs)
+}
+
+func (s *Service) writeOutput(w http.ResponseWriter, r *http.Request, name string, outputs []api.Output) {
+	for _, o := range outputs {
+		if o.Name == name {
+			s.writeJSON(w, r, http.StatusOK, o)
+			return
+		}
+	}
+	s.writeError(w, r, http.StatusNotFound, fmt.Sprintf("output %s not found", name))
+}
+
+// outputDisplay returns display which persists configuration of output. Each display has its own output
+// with multiple displays, the first display keeps configuration of all outputs otherwise
+func (s *Service) outputDisplay(name string) int {
+	for _, d := range s.displays {
+		if d.Name == name {
+			return d.ID
+		}
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// JSON writes payload as json with provided status code
+func JSON(w http.ResponseWriter, status int, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", api.ContentTypeApplicationJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// Error writes standard json error envelope with provided status code
+func Error(w http.ResponseWriter, r *http.Request, status int, message string) error {
+	return JSON(w, status, api.ErrorResponse{
+		Code:      status,
+		Message:   message,
+		RequestID: RequestID(r.Context()),
+	})
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// getPowerSchedule returns power schedule of display with power it requests now
+func (s *Service) getPowerSchedule(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	result, err := s.update(r, api.KioskRequest{Action: api.ScreenActionGetPowerSchedule, Display: display})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get power schedule: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, result.PowerSchedule)
+}
+
+// putPowerSchedule replaces power schedule. Power requested by hand afterwards overrides it until the next transition
+func (s *Service) putPowerSchedule(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	in := api.PowerSchedule{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	result, err := s.update(r, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, PowerSchedule: &in, Display: display})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to set power schedule: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, result.PowerSchedule)
+}
+
+// deletePowerSchedule removes power schedule, screen keeps its power
+func (s *Service) deletePowerSchedule(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	_, err := s.update(r, api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, Display: display})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to remove power schedule: %s", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
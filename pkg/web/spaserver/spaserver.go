@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 
 	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/web/response"
 )
 
 type SPAFileServer struct {
@@ -28,14 +30,14 @@ func (s *SPAFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path, err := filepath.Abs(r.URL.Path)
 	if err != nil {
 		s.log.Error("serve http error", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, "failed to resolve path")
 		return
 	}
 
 	if f, err := s.fileSystem.Open(path); err == nil {
 		if err = f.Close(); err != nil {
 			s.log.Error("serve http error", zap.Error(err))
-			w.WriteHeader(http.StatusInternalServerError)
+			response.Error(w, r, http.StatusInternalServerError, "failed to read file")
 			return
 		}
 		s.fileServer.ServeHTTP(w, r)
@@ -45,7 +47,7 @@ func (s *SPAFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		s.log.Error("file system open", zap.Error(err))
-		w.WriteHeader(http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, "failed to open file")
 	}
 }
 
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"crypto/tls"
+	"fmt"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/util/certs"
+)
+
+var (
+	// selfSignedCert and selfSignedKey are files in state directory used when certificate is not configured
+	selfSignedCert = filepath.Join("tls", "server.crt")
+	selfSignedKey  = filepath.Join("tls", "server.key")
+)
+
+// tlsConfig builds TLS configuration of management api. Client certificates are required
+// only when client CA is configured
+func (s *Service) tlsConfig() (*tls.Config, error) {
+	certPath, keyPath := s.config.WebServerTLSCert, s.config.WebServerTLSKey
+	switch {
+	case certPath == "" && keyPath == "":
+		certPath = filepath.Join(s.config.StateDir, selfSignedCert)
+		keyPath = filepath.Join(s.config.StateDir, selfSignedKey)
+
+		err := certs.EnsureSelfSigned(certPath, keyPath, certs.DefaultHosts())
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+		s.log.Info("using self-signed certificate", zap.String("cert", certPath))
+	case certPath == "" || keyPath == "":
+		return nil, fmt.Errorf("both WEB_SERVER_TLS_CERT and WEB_SERVER_TLS_KEY must be set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if s.config.WebServerTLSClientCA != "" {
+		pool, err := certs.LoadCertPool(s.config.WebServerTLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client CA: %w", err)
+		}
+		c.ClientCAs = pool
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return c, nil
+}
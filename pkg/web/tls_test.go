@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikiosk/unikiosk/pkg/client"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/util/certs"
+)
+
+func TestService_tls(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	clientDir := t.TempDir()
+	clientCert := filepath.Join(clientDir, "client.crt")
+	clientKey := filepath.Join(clientDir, "client.key")
+	require.NoError(certs.EnsureSelfSigned(clientCert, clientKey, []string{"operator"}))
+
+	svc, _, _ := newTestServiceWithConfig(t, &config.Config{
+		WebServerTLS:         true,
+		WebServerTLSClientCA: clientCert,
+	})
+
+	tlsConfig, err := svc.tlsConfig()
+	require.NoError(err)
+	serverCert := filepath.Join(svc.config.StateDir, selfSignedCert)
+	require.FileExists(serverCert)
+
+	server := httptest.NewUnstartedServer(svc.server.Handler)
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	// client without certificate is rejected during handshake
+	c, err := client.TLSConfig(serverCert, "", "")
+	require.NoError(err)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: c}}
+	_, err = httpClient.Get(server.URL + "/api/v1/state")
+	require.Error(err)
+
+	c, err = client.TLSConfig(serverCert, clientCert, clientKey)
+	require.NoError(err)
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: c}}
+	resp, err := httpClient.Get(server.URL + "/api/v1/state")
+	require.NoError(err)
+	defer resp.Body.Close()
+	// state is not initialized in test store
+	require.Equal(http.StatusNotFound, resp.StatusCode)
+}
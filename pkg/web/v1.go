@@ -0,0 +1,415 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/allowlist"
+	"github.com/unikiosk/unikiosk/pkg/api"
+)
+
+// setupV1Router registers resource oriented api. All resources are backed by the same
+// eventer path as legacy /api endpoint
+func (s *Service) setupV1Router(r *mux.Router) {
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+
+	v1.HandleFunc("/displays", s.getDisplays).Methods(http.MethodGet)
+	v1.HandleFunc("/state", s.getState).Methods(http.MethodGet)
+
+	v1.HandleFunc("/content", s.getContent).Methods(http.MethodGet)
+	v1.HandleFunc("/content", s.putContent).Methods(http.MethodPut)
+	v1.HandleFunc("/content", s.patchContent).Methods(http.MethodPatch)
+
+	v1.HandleFunc("/power", s.getPower).Methods(http.MethodGet)
+	v1.HandleFunc("/power", s.putPower).Methods(http.MethodPut, http.MethodPatch)
+	v1.HandleFunc("/power/schedule", s.getPowerSchedule).Methods(http.MethodGet)
+	v1.HandleFunc("/power/schedule", s.putPowerSchedule).Methods(http.MethodPut)
+	v1.HandleFunc("/power/schedule", s.deletePowerSchedule).Methods(http.MethodDelete)
+
+	v1.HandleFunc("/window", s.getWindow).Methods(http.MethodGet)
+	v1.HandleFunc("/window", s.putWindow).Methods(http.MethodPut)
+	v1.HandleFunc("/window", s.patchWindow).Methods(http.MethodPatch)
+
+	v1.HandleFunc("/brightness", s.getBrightness).Methods(http.MethodGet)
+	v1.HandleFunc("/brightness", s.putBrightness).Methods(http.MethodPut, http.MethodPatch)
+
+	v1.HandleFunc("/screenshot", s.getScreenshot).Methods(http.MethodGet)
+
+	v1.HandleFunc("/outputs", s.getOutputs).Methods(http.MethodGet)
+	v1.HandleFunc("/outputs/{name}", s.getOutput).Methods(http.MethodGet)
+	v1.HandleFunc("/outputs/{name}", s.putOutput).Methods(http.MethodPut, http.MethodPatch)
+
+	v1.HandleFunc("/eval", s.eval).Methods(http.MethodPost)
+	v1.HandleFunc("/browser/console", s.getConsole).Methods(http.MethodGet)
+	v1.HandleFunc("/browser/cookies", s.getCookies).Methods(http.MethodGet)
+	v1.HandleFunc("/browser/cookies", s.setCookies).Methods(http.MethodPost)
+	v1.HandleFunc("/browser/cookies", s.deleteCookies).Methods(http.MethodDelete)
+	v1.HandleFunc("/browser/clear", s.clearData).Methods(http.MethodPost)
+
+	v1.HandleFunc("/events", s.streamEvents).Methods(http.MethodGet)
+
+	v1.HandleFunc("/audit", s.getAudit).Methods(http.MethodGet)
+
+	v1.HandleFunc("/tokens", s.listTokens).Methods(http.MethodGet)
+	v1.HandleFunc("/tokens", s.createToken).Methods(http.MethodPost)
+	v1.HandleFunc("/tokens/{id}", s.deleteToken).Methods(http.MethodDelete)
+}
+
+// getDisplays lists displays kiosk shows content on. Other resources address display by display query parameter
+func (s *Service) getDisplays(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, r, http.StatusOK, s.displays)
+}
+
+func (s *Service) getState(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	state, err := s.get(display)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get screen state: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.ResponseToState(state))
+}
+
+func (s *Service) getContent(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	state, err := s.get(display)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get content: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.Content{Content: state.Content, Title: state.Title, Allowlist: state.Allowlist})
+}
+
+func (s *Service) putContent(w http.ResponseWriter, r *http.Request) {
+	in := api.Content{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if in.Content == "" {
+		s.writeError(w, r, http.StatusBadRequest, "content is required")
+		return
+	}
+	// content is replaced with its allowlist
+	if in.Allowlist == nil {
+		in.Allowlist = []string{}
+	}
+	s.updateContent(w, r, in)
+}
+
+func (s *Service) patchContent(w http.ResponseWriter, r *http.Request) {
+	in := api.Content{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if in.Content == "" && in.Title == "" && in.Allowlist == nil {
+		s.writeError(w, r, http.StatusBadRequest, "one of content, title or allowlist is required")
+		return
+	}
+	s.updateContent(w, r, in)
+}
+
+func (s *Service) updateContent(w http.ResponseWriter, r *http.Request, in api.Content) {
+	_, err := allowlist.New(in.Allowlist)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{
+		Action:    api.ScreenActionUpdate,
+		Content:   in.Content,
+		Title:     in.Title,
+		Allowlist: in.Allowlist,
+		Display:   display,
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to update content: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.Content{Content: result.Content, Title: result.Title, Allowlist: result.Allowlist})
+}
+
+func (s *Service) getPower(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	state, err := s.get(display)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get power state: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.ResponseToPower(state))
+}
+
+func (s *Service) putPower(w http.ResponseWriter, r *http.Request) {
+	in := api.Power{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	power, err := api.StringToPowerState(in.State)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid power state %q, expected one of: on, off", in.State))
+		return
+	}
+
+	action := api.ScreenActionPowerOn
+	if power == api.PowerStateOff {
+		action = api.ScreenActionPowerOff
+	}
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{Action: action, Display: display})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to change power state: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.ResponseToPower(result))
+}
+
+func (s *Service) getWindow(w http.ResponseWriter, r *http.Request) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	state, err := s.get(display)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get window: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.ResponseToWindow(state))
+}
+
+// putWindow replaces window size, position and fullscreen mode. Size is optional in fullscreen
+func (s *Service) putWindow(w http.ResponseWriter, r *http.Request) {
+	in := api.Window{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if in.Width < 0 || in.Height < 0 || (!in.Fullscreen && (in.Width == 0 || in.Height == 0)) {
+		s.writeError(w, r, http.StatusBadRequest, "width and height must be positive")
+		return
+	}
+	s.updateWindow(w, r, api.KioskRequest{
+		Action:     api.ScreenActionUpdate,
+		SizeW:      in.Width,
+		SizeH:      in.Height,
+		Position:   &api.Position{X: in.X, Y: in.Y},
+		Fullscreen: &in.Fullscreen,
+	})
+}
+
+// windowPatch is partial window update, nil fields are not changed
+type windowPatch struct {
+	Width      *int  `json:"width"`
+	Height     *int  `json:"height"`
+	X          *int  `json:"x"`
+	Y          *int  `json:"y"`
+	Fullscreen *bool `json:"fullscreen"`
+}
+
+func (s *Service) patchWindow(w http.ResponseWriter, r *http.Request) {
+	in := windowPatch{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if (in.Width != nil && *in.Width <= 0) || (in.Height != nil && *in.Height <= 0) {
+		s.writeError(w, r, http.StatusBadRequest, "width and height must be positive")
+		return
+	}
+
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	state, err := s.get(display)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get window: %s", err))
+		return
+	}
+	if in.Width == nil && in.Height == nil && in.X == nil && in.Y == nil && in.Fullscreen == nil {
+		s.writeJSON(w, r, http.StatusOK, api.ResponseToWindow(state))
+		return
+	}
+
+	req := api.KioskRequest{
+		Action:     api.ScreenActionUpdate,
+		Fullscreen: in.Fullscreen,
+	}
+	if in.Width != nil || in.Height != nil {
+		req.SizeW, req.SizeH = state.SizeW, state.SizeH
+		if in.Width != nil {
+			req.SizeW = *in.Width
+		}
+		if in.Height != nil {
+			req.SizeH = *in.Height
+		}
+	}
+	if in.X != nil || in.Y != nil {
+		req.Position = &api.Position{X: state.PosX, Y: state.PosY}
+		if in.X != nil {
+			req.Position.X = *in.X
+		}
+		if in.Y != nil {
+			req.Position.Y = *in.Y
+		}
+	}
+	s.updateWindow(w, r, req)
+}
+
+func (s *Service) updateWindow(w http.ResponseWriter, r *http.Request, req api.KioskRequest) {
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+	req.Display = display
+	result, err := s.update(r, req)
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to update window: %s", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, api.ResponseToWindow(result))
+}
+
+// getScreenshot captures screen on demand. Supports format (png, jpeg), quality (jpeg only),
+// width (thumbnail width) and display (display id or all, stitching all displays) query parameters
+func (s *Service) getScreenshot(w http.ResponseWriter, r *http.Request) {
+	opts, err := screenshotOptions(r.URL.Query())
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{
+		Action:            api.ScreenActionScreenShot,
+		ScreenshotOptions: opts,
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to take screenshot: %s", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(len(result.Screenshot)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(result.Screenshot)
+	if err != nil {
+		s.log.Error("failed to write screenshot", zap.Error(err))
+	}
+}
+
+// eval evaluates javascript expression in the current page. Exception thrown by
+// expression is part of successful response
+func (s *Service) eval(w http.ResponseWriter, r *http.Request) {
+	in := api.Eval{}
+	if !s.decode(w, r, &in) {
+		return
+	}
+	if strings.TrimSpace(in.Expression) == "" {
+		s.writeError(w, r, http.StatusBadRequest, "expression is required")
+		return
+	}
+	opts := &api.EvalOptions{Expression: in.Expression}
+	if in.Timeout != "" {
+		timeout, err := time.ParseDuration(in.Timeout)
+		if err != nil || timeout <= 0 || timeout > api.MaxEvalTimeout {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("timeout must be duration up to %s", api.MaxEvalTimeout))
+			return
+		}
+		opts.Timeout = timeout
+	}
+
+	display, ok := s.requestDisplay(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.update(r, api.KioskRequest{
+		Action:      api.ScreenActionEval,
+		EvalOptions: opts,
+		Display:     display,
+	})
+	if err != nil {
+		s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to evaluate expression: %s", err))
+		return
+	}
+	if result.Eval == nil {
+		s.writeError(w, r, http.StatusInternalServerError, "evaluation result is missing")
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, result.Eval)
+}
+
+func screenshotOptions(q url.Values) (*api.ScreenshotOptions, error) {
+	opts := &api.ScreenshotOptions{
+		Format: api.ScreenshotFormatPNG,
+	}
+
+	switch f := strings.ToLower(q.Get("format")); f {
+	case "", api.ScreenshotFormatPNG:
+	case api.ScreenshotFormatJPEG, "jpg":
+		opts.Format = api.ScreenshotFormatJPEG
+	default:
+		return nil, fmt.Errorf("invalid format %q, expected one of: png, jpeg", f)
+	}
+
+	var err error
+	if v := q.Get("quality"); v != "" {
+		opts.Quality, err = strconv.Atoi(v)
+		if err != nil || opts.Quality < 1 || opts.Quality > 100 {
+			return nil, fmt.Errorf("quality must be between 1 and 100")
+		}
+		if opts.Format != api.ScreenshotFormatJPEG {
+			return nil, fmt.Errorf("quality is supported only for jpeg format")
+		}
+	}
+	if v := q.Get("width"); v != "" {
+		opts.Width, err = strconv.Atoi(v)
+		if err != nil || opts.Width < 1 {
+			return nil, fmt.Errorf("width must be positive")
+		}
+	}
+	switch v := q.Get("display"); v {
+	case "":
+	case "all":
+		opts.Stitch = true
+	default:
+		opts.Display, err = strconv.Atoi(v)
+		if err != nil || opts.Display < 0 {
+			return nil, fmt.Errorf("display must be display id or all")
+		}
+	}
+	return opts, nil
+}
+
+// decode decodes request body into v and writes error response if it fails
+func (s *Service) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("failed to parse request: %s", err))
+		return false
+	}
+	return true
+}
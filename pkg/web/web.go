@@ -3,18 +3,30 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/auth"
 	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/console"
 	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/health"
 	"github.com/unikiosk/unikiosk/pkg/store"
+	"github.com/unikiosk/unikiosk/pkg/web/response"
 	"github.com/unikiosk/unikiosk/pkg/web/spaserver"
 )
 
@@ -22,6 +34,7 @@ var _ Interface = &Service{}
 
 type Interface interface {
 	Run(ctx context.Context) error
+	Check(ctx context.Context) error
 }
 
 type Service struct {
@@ -30,7 +43,23 @@ type Service struct {
 	router *mux.Router
 	events eventer.Eventer
 	store  store.Store
+	auth   *auth.Authenticator
+	audit  audit.Log
+	// denied limits audit entries of denied requests per caller
+	denied *audit.Limiter
 	config *config.Config
+	// consoles keep last browser console messages of each display
+	consoles map[int]*console.Buffer
+	// displays are displays kiosk shows content on
+	displays []api.Display
+
+	// content serves default kiosk content. It is exposed on local plain http listener when api uses TLS
+	content http.Handler
+	health  *health.Health
+	// errorTemplate renders page shown while content fails to load
+	errorTemplate *template.Template
+	// listening is set to 1 while management api listener is bound
+	listening int32
 }
 
 func New(
@@ -38,13 +67,30 @@ func New(
 	config *config.Config,
 	events eventer.Eventer,
 	store store.Store,
+	authenticator *auth.Authenticator,
+	auditLog audit.Log,
+	health *health.Health,
+	consoles map[int]*console.Buffer,
+	displays []api.Display,
 ) (*Service, error) {
 
 	s := &Service{
-		log:    log,
-		events: events,
-		store:  store,
-		config: config,
+		log:      log,
+		events:   events,
+		store:    store,
+		auth:     authenticator,
+		audit:    auditLog,
+		denied:   audit.NewLimiter(audit.DeniedInterval),
+		health:   health,
+		consoles: consoles,
+		displays: displays,
+		config:   config,
+	}
+
+	var err error
+	s.errorTemplate, err = loadErrorTemplate(config.PageErrorTemplate)
+	if err != nil {
+		return nil, err
 	}
 
 	s.router = s.setupRouter()
@@ -52,20 +98,21 @@ func New(
 	// when running in dev mode set
 	// WEB_SERVER_URI=http://localhost:3000
 	if strings.HasPrefix(s.config.WebServerDir, "http://") {
-		s.router.PathPrefix("/").Handler(spaserver.NewSPAReverseProxyServer(s.log, s.config.WebServerDir))
+		s.content = spaserver.NewSPAReverseProxyServer(s.log, s.config.WebServerDir)
 	} else {
 		s.log.Info("serving static UI files", zap.String("dir", s.config.WebServerDir))
 
 		fileSystem := http.Dir(s.config.WebServerDir)
 
-		s.router.PathPrefix("/").Handler(spaserver.NewSPAFileServer(s.log, fileSystem))
+		s.content = spaserver.NewSPAFileServer(s.log, fileSystem)
 	}
+	s.router.PathPrefix("/").Methods(http.MethodGet, http.MethodHead).Handler(s.content)
 
 	s.server = &http.Server{
 		Addr: config.WebServerAddr,
 		Handler: handlers.CORS(
 			handlers.AllowCredentials(),
-			handlers.AllowedHeaders([]string{"Content-Type"}),
+			handlers.AllowedHeaders([]string{"Content-Type", "Authorization", response.HeaderRequestID}),
 			handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE"}),
 		)(s.router),
 	}
@@ -76,84 +123,266 @@ func New(
 func (s *Service) Run(ctx context.Context) error {
 	s.log.Info("Starting API Service")
 
-	defer s.server.Shutdown(ctx)
+	if !s.config.WebServerTLS {
+		defer s.server.Shutdown(ctx)
+
+		listener, err := s.listen(s.config.WebServerAddr)
+		if err != nil {
+			return err
+		}
+		defer atomic.StoreInt32(&s.listening, 0)
+
+		s.log.Info("Server will now listen", zap.String("url", s.config.WebServerAddr))
+		return s.server.Serve(listener)
+	}
+
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	s.server.TLSConfig = tlsConfig
+
+	// browser can't be made to trust our certificate, so default content stays on plain http
+	localRouter := http.NewServeMux()
+	localRouter.HandleFunc("/healthz", s.healthz)
+	localRouter.HandleFunc("/readyz", s.readyz)
+	localRouter.HandleFunc(api.ErrorPagePath, s.errorPage)
+	localRouter.Handle("/", s.content)
+	local := &http.Server{
+		Addr:    s.config.WebServerLocalAddr,
+		Handler: localRouter,
+	}
+
+	localListener, err := net.Listen("tcp", s.config.WebServerLocalAddr)
+	if err != nil {
+		return err
+	}
+	listener, err := s.listen(s.config.WebServerAddr)
+	if err != nil {
+		localListener.Close()
+		return err
+	}
+	defer atomic.StoreInt32(&s.listening, 0)
+
+	// both servers are shut down once either of them fails
+	g, gctx := errgroup.WithContext(ctx)
+	go func() {
+		<-gctx.Done()
+		local.Shutdown(context.Background())
+		s.server.Shutdown(context.Background())
+	}()
+
+	g.Go(func() error {
+		s.log.Info("Local content server will now listen", zap.String("url", s.config.WebServerLocalAddr))
+		return local.Serve(localListener)
+	})
+	g.Go(func() error {
+		s.log.Info("Server will now listen with TLS", zap.String("url", s.config.WebServerAddr),
+			zap.Bool("clientAuth", s.config.WebServerTLSClientCA != ""))
+		return s.server.ServeTLS(listener, "", "")
+	})
+
+	err = g.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// listen binds management api listener and marks service as listening
+func (s *Service) listen(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	atomic.StoreInt32(&s.listening, 1)
+	return listener, nil
+}
 
-	s.log.Info("Server will now listen", zap.String("url", s.config.WebServerAddr))
-	return s.server.ListenAndServe()
+// Check returns error when management api is not listening
+func (s *Service) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&s.listening) == 0 {
+		return fmt.Errorf("web server is not listening on %s", s.config.WebServerAddr)
+	}
+	return nil
 }
 
 func (s *Service) setupRouter() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(response.RequestIDMiddleware)
+	r.Use(s.authMiddleware)
 
+	// legacy action based api, kept for compatibility with older clients
 	code := http.HandlerFunc(s.handel)
 	r.Handle("/api", code).Methods(http.MethodPost)
 	r.Handle("/api", code).Methods(http.MethodGet)
 
-	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-	})
+	r.HandleFunc("/api/openapi.json", s.getOpenAPISpec).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", s.healthz).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/readyz", s.readyz).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc(api.ErrorPagePath, s.errorPage).Methods(http.MethodGet, http.MethodHead)
+	s.setupV1Router(r)
+
+	r.NotFoundHandler = response.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, r, http.StatusNotFound, "not found")
+	}))
+	r.MethodNotAllowedHandler = response.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+	}))
 
 	return r
 }
 
 func (s *Service) handel(w http.ResponseWriter, r *http.Request) {
-
 	switch r.Method {
 	case http.MethodPost:
 		// update
 		payload := api.KioskRequest{}
 		err := json.NewDecoder(r.Body).Decode(&payload)
 		if err != nil {
-			_, err := fmt.Fprintf(w, "failed to parse request: %s", err.Error())
-			if err != nil {
-				fmt.Println(err.Error())
-			}
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("failed to parse request: %s", err))
+			return
 		}
 
-		err = s.update(payload)
+		result, err := s.update(r, payload)
 		if err != nil {
-			_, err := fmt.Fprintf(w, "failed to update screen: %s", err.Error())
-			if err != nil {
-				fmt.Println(err.Error())
-			}
+			s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to update screen: %s", err))
+			return
 		}
+		s.writeJSON(w, r, http.StatusOK, result)
 
 	case http.MethodGet:
 		// get current state
-		state, err := s.get()
-		if err != nil {
-			_, err := fmt.Fprintf(w, "failed to get screen state: %s", err.Error())
-			if err != nil {
-				fmt.Println(err.Error())
-			}
+		display, ok := s.requestDisplay(w, r)
+		if !ok {
+			return
 		}
-
-		data, err := json.Marshal(state)
+		state, err := s.get(display)
 		if err != nil {
-			fmt.Printf("failed to marshal: %s", err.Error())
+			s.writeError(w, r, statusFromError(err), fmt.Sprintf("failed to get screen state: %s", err))
+			return
 		}
-
-		// TODO: all return should be standart error json
-		fmt.Fprint(w, data)
+		s.writeJSON(w, r, http.StatusOK, state)
 
 	default:
-		fmt.Fprintf(w, "not supported")
+		s.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
 	}
-
 }
 
-func (s *Service) update(payload api.KioskRequest) error {
+func (s *Service) update(r *http.Request, payload api.KioskRequest) (api.KioskResponse, error) {
+	// requests of unknown display would not be handled by any kiosk
+	if display := payload.TargetDisplay(); !s.hasDisplay(display) {
+		err := fmt.Errorf("display %d: %w", display, os.ErrNotExist)
+		s.recordFailed(r, payload, err)
+		return api.KioskResponse{}, err
+	}
+
 	event := api.Event{
 		Request: payload,
+		Caller:  callerFromRequest(r),
 	}
 
-	_, err := s.events.Emit(&eventer.EventWrapper{
+	result, err := s.events.Emit(&eventer.EventWrapper{
 		Payload: event,
 	})
-	return err
+	if err != nil {
+		// without result kiosk did not respond in time, so request is not recorded by kiosk
+		if result == nil {
+			s.recordFailed(r, payload, err)
+		}
+		return api.KioskResponse{}, err
+	}
+	return result.Payload.Response, nil
+}
+
+// callerFromRequest describes request origin for audit log
+func callerFromRequest(r *http.Request) api.Caller {
+	caller := api.Caller{
+		Address:   r.RemoteAddr,
+		RequestID: response.RequestID(r.Context()),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		caller.Address = host
+	}
+	if id := auth.IdentityFromContext(r.Context()); id != nil {
+		caller.Identity = id.Name
+	}
+	return caller
 }
 
-func (s *Service) get() (api.KioskResponse, error) {
-	return api.KioskResponse{}, nil
+func (s *Service) get(display int) (api.KioskResponse, error) {
+	if !s.hasDisplay(display) {
+		return api.KioskResponse{}, fmt.Errorf("display %d: %w", display, os.ErrNotExist)
+	}
+	state, err := s.store.Get(store.StateKey(display))
+	if err != nil {
+		return api.KioskResponse{}, err
+	}
+	return api.StateToResponse(state), nil
+}
+
+// requestDisplay returns display addressed by display query parameter, the first display when it is not set.
+// Error response is written when display is not valid
+func (s *Service) requestDisplay(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("display")
+	if v == "" {
+		return 0, true
+	}
+	display, err := strconv.Atoi(v)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid display %q, expected display id", v))
+		return 0, false
+	}
+	if !s.hasDisplay(display) {
+		msg := fmt.Sprintf("display %d not found", display)
+		// reads are not audited, requests changing kiosk are
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			s.record(r, api.AuditEntry{Display: display, Action: r.Method + " " + r.URL.Path}, errors.New(msg))
+		}
+		s.writeError(w, r, http.StatusNotFound, msg)
+		return 0, false
+	}
+	return display, true
+}
+
+func (s *Service) hasDisplay(display int) bool {
+	for _, d := range s.displays {
+		if d.ID == display {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) writeJSON(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
+	err := response.JSON(w, status, payload)
+	if err != nil {
+		s.log.Error("failed to write response", zap.String("requestId", response.RequestID(r.Context())), zap.Error(err))
+	}
+}
+
+func (s *Service) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if status >= http.StatusInternalServerError {
+		s.log.Error("request failed", zap.String("requestId", response.RequestID(r.Context())), zap.Int("code", status), zap.String("message", message))
+	}
+	err := response.Error(w, r, status, message)
+	if err != nil {
+		s.log.Error("failed to write error response", zap.String("requestId", response.RequestID(r.Context())), zap.Error(err))
+	}
+}
+
+// statusFromError maps internal errors to http status codes
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, os.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, eventer.ErrCallbackTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
 }
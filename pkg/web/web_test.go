@@ -0,0 +1,920 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/unikiosk/unikiosk/pkg/api"
+	"github.com/unikiosk/unikiosk/pkg/audit"
+	"github.com/unikiosk/unikiosk/pkg/auth"
+	"github.com/unikiosk/unikiosk/pkg/config"
+	"github.com/unikiosk/unikiosk/pkg/console"
+	"github.com/unikiosk/unikiosk/pkg/eventer"
+	"github.com/unikiosk/unikiosk/pkg/health"
+	"github.com/unikiosk/unikiosk/pkg/store"
+	"github.com/unikiosk/unikiosk/pkg/store/memory"
+	"github.com/unikiosk/unikiosk/pkg/util/logger"
+	"github.com/unikiosk/unikiosk/pkg/web/response"
+)
+
+func newTestService(t *testing.T) (*Service, *memory.MemoryStore) {
+	svc, s, _ := newTestServiceWithEvents(t)
+	return svc, s
+}
+
+func newTestServiceWithEvents(t *testing.T) (*Service, *memory.MemoryStore, *eventer.ChannelEventer) {
+	return newTestServiceWithConfig(t, &config.Config{})
+}
+
+func newTestServiceWithConfig(t *testing.T, c *config.Config) (*Service, *memory.MemoryStore, *eventer.ChannelEventer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c.WebServerDir = t.TempDir()
+	c.StateDir = t.TempDir()
+
+	log := logger.GetLoggerInstance("", zap.DebugLevel)
+	s := memory.New()
+	events := eventer.New(ctx, log)
+
+	authenticator, err := auth.New(log, c)
+	require.NoError(t, err)
+
+	auditLog, err := audit.New(log, c)
+	require.NoError(t, err)
+
+	displays := []api.Display{
+		{ID: 0, Name: "HDMI-1", Width: 1920, Height: 1080},
+		{ID: 1, Name: "HDMI-2", X: 1920, Width: 1280, Height: 1024},
+	}
+	consoles := map[int]*console.Buffer{0: console.New(10), 1: console.New(10)}
+	svc, err := New(log, c, events, s, authenticator, auditLog, health.New(), consoles, displays)
+	require.NoError(t, err)
+	return svc, s, events
+}
+
+// respond acts as kiosk dispatcher: it records received requests and answers with request echo
+func respond(ctx context.Context, events eventer.Eventer) <-chan api.KioskRequest {
+	received := make(chan api.KioskRequest, 10)
+	listener := events.Subscribe(ctx)
+	go func() {
+		for event := range listener {
+			req := event.Payload.Request
+			received <- req
+
+			power := api.PowerStateOn
+			if req.Action == api.ScreenActionPowerOff {
+				power = api.PowerStateOff
+			}
+			resp := api.KioskResponse{
+				Display:    req.TargetDisplay(),
+				Content:    req.Content,
+				Title:      req.Title,
+				SizeW:      req.SizeW,
+				SizeH:      req.SizeH,
+				Fullscreen: req.Fullscreen != nil && *req.Fullscreen,
+				PowerState: power,
+				Allowlist:  req.Allowlist,
+
+				DesiredPowerState: power,
+			}
+			if req.Position != nil {
+				resp.PosX, resp.PosY = req.Position.X, req.Position.Y
+			}
+			resp.Cookies = req.Cookies
+			switch {
+			case req.Output != nil:
+				resp.Outputs = []api.Output{{Name: req.Output.Name, Enabled: true, Width: req.Output.Width, Height: req.Output.Height, Rotation: req.Output.Rotation}}
+			case req.Action == api.ScreenActionGetOutputs:
+				resp.Outputs = []api.Output{{Name: "HDMI-1", Enabled: true, Width: 1920, Height: 1080, Rotation: api.RotationNormal, Rate: 60, Modes: []api.OutputMode{{Width: 1920, Height: 1080, Rate: 60, Preferred: true}}}}
+			}
+			switch {
+			case req.Brightness != nil:
+				resp.Brightness = &api.Brightness{Device: "panel", Schedule: req.Brightness.Schedule}
+				if req.Brightness.Level != nil {
+					resp.Brightness.Level = *req.Brightness.Level
+				}
+			case req.Action == api.ScreenActionGetBrightness:
+				resp.Brightness = &api.Brightness{Level: 50, Device: "panel"}
+			}
+			if req.Action == api.ScreenActionGetPowerSchedule || req.Action == api.ScreenActionSetPowerSchedule {
+				resp.PowerSchedule = &api.PowerScheduleStatus{Schedule: req.PowerSchedule}
+			}
+			if req.EvalOptions != nil {
+				expression, _ := json.Marshal(req.EvalOptions.Expression)
+				resp.Eval = &api.EvalResult{Result: expression}
+			}
+			event.Callback <- &eventer.EventWrapper{
+				Payload: api.Event{
+					Response: resp,
+				},
+			}
+		}
+	}()
+	return received
+}
+
+func TestService_getState(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, s := newTestService(t)
+	require.NoError(s.Persist(store.KioskStateKey, api.KioskState{
+		Content:    "https://synpse.net",
+		Title:      "UniKiosk",
+		SizeW:      1920,
+		SizeH:      1080,
+		PowerState: api.PowerStateOff,
+	}))
+
+	w := httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal(api.ContentTypeApplicationJSON, w.Header().Get("Content-Type"))
+
+	result := api.KioskResponse{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&result))
+	require.Equal("https://synpse.net", result.Content)
+	require.Equal(1920, result.SizeW)
+	require.Equal(api.PowerStateOff, result.PowerState)
+}
+
+func TestService_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		body   string
+		code   int
+	}{
+		{name: "missing state", method: http.MethodGet, code: http.StatusNotFound},
+		{name: "invalid payload", method: http.MethodPost, body: "{", code: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodDelete, code: http.StatusMethodNotAllowed},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			svc, _ := newTestService(t)
+
+			r := httptest.NewRequest(tc.method, "/api", strings.NewReader(tc.body))
+			r.Header.Set(response.HeaderRequestID, "test-id")
+			w := httptest.NewRecorder()
+			svc.server.Handler.ServeHTTP(w, r)
+
+			require.Equal(tc.code, w.Code)
+
+			result := api.ErrorResponse{}
+			require.NoError(json.NewDecoder(w.Body).Decode(&result))
+			require.Equal(tc.code, result.Code)
+			require.Equal("test-id", result.RequestID)
+			require.NotEmpty(result.Message)
+		})
+	}
+}
+
+func TestService_v1(t *testing.T) {
+	t.Parallel()
+
+	fullscreen := true
+	brightness := 80
+	schedule := api.PowerSchedule{
+		Timezone:   "UTC",
+		Windows:    []api.PowerWindow{{Days: []string{"mon", "fri"}, On: "08:00", Off: "20:00"}},
+		Exceptions: []api.PowerException{{Date: "2022-12-25"}},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		code     int
+		expected api.KioskRequest
+		result   string
+	}{
+		{
+			name:     "put content",
+			method:   http.MethodPut,
+			path:     "/api/v1/content",
+			body:     `{"content":"https://synpse.net"}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net", Allowlist: []string{}},
+			result:   `{"content":"https://synpse.net"}`,
+		},
+		{
+			name:     "put content with allowlist",
+			method:   http.MethodPut,
+			path:     "/api/v1/content",
+			body:     `{"content":"https://synpse.net","allowlist":["https://synpse.net/*"]}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, Content: "https://synpse.net", Allowlist: []string{"https://synpse.net/*"}},
+			result:   `{"content":"https://synpse.net","allowlist":["https://synpse.net/*"]}`,
+		},
+		{
+			name:     "patch allowlist",
+			method:   http.MethodPatch,
+			path:     "/api/v1/content",
+			body:     `{"allowlist":["https://*.synpse.net/*"]}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, Allowlist: []string{"https://*.synpse.net/*"}},
+			result:   `{"content":"","allowlist":["https://*.synpse.net/*"]}`,
+		},
+		{
+			name:   "invalid allowlist pattern",
+			method: http.MethodPut,
+			path:   "/api/v1/content",
+			body:   `{"content":"https://synpse.net","allowlist":["synpse.net"]}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "put content of display",
+			method:   http.MethodPut,
+			path:     "/api/v1/content?display=1",
+			body:     `{"content":"https://synpse.net"}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, Display: 1, Content: "https://synpse.net", Allowlist: []string{}},
+			result:   `{"content":"https://synpse.net"}`,
+		},
+		{
+			name:   "put content of unknown display",
+			method: http.MethodPut,
+			path:   "/api/v1/content?display=2",
+			body:   `{"content":"https://synpse.net"}`,
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "invalid display",
+			method: http.MethodPut,
+			path:   "/api/v1/power?display=left",
+			body:   `{"state":"off"}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "screenshot of unknown display",
+			method: http.MethodGet,
+			path:   "/api/v1/screenshot?display=2",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "get outputs",
+			method: http.MethodGet,
+			path:   "/api/v1/outputs",
+			code:   http.StatusOK,
+			result: `[{"name":"HDMI-1","enabled":true,"x":0,"y":0,"width":1920,"height":1080,"rotation":"normal","rate":60,"modes":[{"width":1920,"height":1080,"rate":60,"preferred":true}]}]`,
+		},
+		{
+			name:   "get unknown output",
+			method: http.MethodGet,
+			path:   "/api/v1/outputs/DP-1",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:     "rotate output of display",
+			method:   http.MethodPut,
+			path:     "/api/v1/outputs/HDMI-2",
+			body:     `{"rotation":"left","width":1280,"height":1024}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionSetOutput, Display: 1, Output: &api.OutputConfig{Name: "HDMI-2", Width: 1280, Height: 1024, Rotation: api.RotationLeft}},
+			result:   `{"name":"HDMI-2","enabled":true,"x":0,"y":0,"width":1280,"height":1024,"rotation":"left","modes":null}`,
+		},
+		{
+			name:   "invalid rotation",
+			method: http.MethodPatch,
+			path:   "/api/v1/outputs/HDMI-1",
+			body:   `{"rotation":"upside-down"}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "output width without height",
+			method: http.MethodPatch,
+			path:   "/api/v1/outputs/HDMI-1",
+			body:   `{"width":1280}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "get brightness",
+			method:   http.MethodGet,
+			path:     "/api/v1/brightness",
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionGetBrightness},
+			result:   `{"level":50,"device":"panel"}`,
+		},
+		{
+			name:     "set brightness",
+			method:   http.MethodPut,
+			path:     "/api/v1/brightness",
+			body:     `{"level":80}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Level: &brightness}},
+			result:   `{"level":80,"device":"panel"}`,
+		},
+		{
+			name:     "patch brightness schedule",
+			method:   http.MethodPatch,
+			path:     "/api/v1/brightness",
+			body:     `{"schedule":[{"time":"07:00","level":100},{"time":"22:00","level":20}]}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionSetBrightness, Brightness: &api.BrightnessConfig{Schedule: []api.BrightnessPoint{{Time: "07:00", Level: 100}, {Time: "22:00", Level: 20}}}},
+			result:   `{"level":0,"device":"panel","schedule":[{"time":"07:00","level":100},{"time":"22:00","level":20}]}`,
+		},
+		{
+			name:   "set brightness without level",
+			method: http.MethodPut,
+			path:   "/api/v1/brightness",
+			body:   `{}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "put power schedule",
+			method:   http.MethodPut,
+			path:     "/api/v1/power/schedule?display=1",
+			body:     `{"timezone":"UTC","windows":[{"days":["mon","fri"],"on":"08:00","off":"20:00"}],"exceptions":[{"date":"2022-12-25"}]}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionSetPowerSchedule, Display: 1, PowerSchedule: &schedule},
+			result:   `{"schedule":{"timezone":"UTC","windows":[{"days":["mon","fri"],"on":"08:00","off":"20:00"}],"exceptions":[{"date":"2022-12-25"}]},"override":false}`,
+		},
+		{
+			name:     "delete power schedule",
+			method:   http.MethodDelete,
+			path:     "/api/v1/power/schedule",
+			code:     http.StatusNoContent,
+			expected: api.KioskRequest{Action: api.ScreenActionSetPowerSchedule},
+		},
+		{
+			name:   "put empty content",
+			method: http.MethodPut,
+			path:   "/api/v1/content",
+			body:   `{"title":"foo"}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "power off",
+			method:   http.MethodPut,
+			path:     "/api/v1/power",
+			body:     `{"state":"off"}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionPowerOff},
+			result:   `{"state":"off","desired":"off"}`,
+		},
+		{
+			name:   "invalid power state",
+			method: http.MethodPut,
+			path:   "/api/v1/power",
+			body:   `{"state":"dim"}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "patch window",
+			method:   http.MethodPatch,
+			path:     "/api/v1/window",
+			body:     `{"width":800}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, SizeW: 800, SizeH: 1080},
+			result:   `{"width":800,"height":1080,"x":0,"y":0,"fullscreen":false}`,
+		},
+		{
+			name:     "patch window position",
+			method:   http.MethodPatch,
+			path:     "/api/v1/window",
+			body:     `{"x":100}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, Position: &api.Position{X: 100}},
+			result:   `{"width":0,"height":0,"x":100,"y":0,"fullscreen":false}`,
+		},
+		{
+			name:     "put fullscreen window",
+			method:   http.MethodPut,
+			path:     "/api/v1/window",
+			body:     `{"fullscreen":true}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionUpdate, Position: &api.Position{}, Fullscreen: &fullscreen},
+			result:   `{"width":0,"height":0,"x":0,"y":0,"fullscreen":true}`,
+		},
+		{
+			name:   "put window without size",
+			method: http.MethodPut,
+			path:   "/api/v1/window",
+			body:   `{"x":10,"y":10}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "jpeg thumbnail",
+			method: http.MethodGet,
+			path:   "/api/v1/screenshot?format=jpeg&quality=50&width=320&display=1",
+			code:   http.StatusOK,
+			expected: api.KioskRequest{
+				Action:            api.ScreenActionScreenShot,
+				ScreenshotOptions: &api.ScreenshotOptions{Format: "jpeg", Quality: 50, Width: 320, Display: 1},
+			},
+		},
+		{
+			name:   "invalid screenshot format",
+			method: http.MethodGet,
+			path:   "/api/v1/screenshot?format=gif",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "png quality",
+			method: http.MethodGet,
+			path:   "/api/v1/screenshot?quality=50",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "eval",
+			method:   http.MethodPost,
+			path:     "/api/v1/eval",
+			body:     `{"expression":"document.title","timeout":"1s"}`,
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionEval, EvalOptions: &api.EvalOptions{Expression: "document.title", Timeout: time.Second}},
+			result:   `{"result":"document.title"}`,
+		},
+		{
+			name:   "eval without expression",
+			method: http.MethodPost,
+			path:   "/api/v1/eval",
+			body:   `{"expression":" "}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "eval timeout too long",
+			method: http.MethodPost,
+			path:   "/api/v1/eval",
+			body:   `{"expression":"1","timeout":"1m"}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:     "get cookies",
+			method:   http.MethodGet,
+			path:     "/api/v1/browser/cookies?domain=synpse.net",
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionGetCookies, CookieOptions: &api.CookieOptions{Domain: "synpse.net"}},
+			result:   `[]`,
+		},
+		{
+			name:   "set cookies",
+			method: http.MethodPost,
+			path:   "/api/v1/browser/cookies",
+			body:   `[{"name":"session","value":"secret","domain":"synpse.net","httpOnly":true}]`,
+			code:   http.StatusNoContent,
+			expected: api.KioskRequest{
+				Action:  api.ScreenActionSetCookies,
+				Cookies: []api.Cookie{{Name: "session", Value: "secret", Domain: "synpse.net", HTTPOnly: true}},
+			},
+		},
+		{
+			name:     "delete cookies",
+			method:   http.MethodDelete,
+			path:     "/api/v1/browser/cookies?name=session",
+			code:     http.StatusOK,
+			expected: api.KioskRequest{Action: api.ScreenActionDeleteCookies, CookieOptions: &api.CookieOptions{Name: "session"}},
+			result:   `[]`,
+		},
+		{
+			name:     "clear storage",
+			method:   http.MethodPost,
+			path:     "/api/v1/browser/clear",
+			body:     `{"storage":true,"origins":["https://synpse.net"]}`,
+			code:     http.StatusNoContent,
+			expected: api.KioskRequest{Action: api.ScreenActionClearData, ClearOptions: &api.ClearOptions{Storage: true, Origins: []string{"https://synpse.net"}}},
+		},
+		{
+			name:   "get state",
+			method: http.MethodGet,
+			path:   "/api/v1/state",
+			code:   http.StatusOK,
+			result: `{"display":0,"content":"https://synpse.net","window":{"width":1920,"height":1080,"x":0,"y":0,"fullscreen":true},"power":"on","browser":{"restarts":0},"navigation":{"blocked":0}}`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			svc, s, events := newTestServiceWithEvents(t)
+			require.NoError(s.Persist(store.KioskStateKey, api.KioskState{
+				Content: "https://synpse.net",
+				SizeW:   1920,
+				SizeH:   1080,
+			}))
+			received := respond(ctx, events)
+
+			w := httptest.NewRecorder()
+			svc.server.Handler.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+			require.Equal(tc.code, w.Code, w.Body.String())
+			if tc.result != "" {
+				require.JSONEq(tc.result, w.Body.String())
+			}
+			if !reflect.DeepEqual(tc.expected, api.KioskRequest{}) {
+				require.Equal(tc.expected, <-received)
+			}
+		})
+	}
+}
+
+func TestService_displays(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, s := newTestService(t)
+	require.NoError(s.Persist(store.StateKey(1), api.KioskState{
+		Display: 1,
+		Content: "https://synpse.net",
+		SizeW:   1280,
+		SizeH:   1024,
+	}))
+
+	w := httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/displays", nil))
+	require.Equal(http.StatusOK, w.Code)
+	displays := []api.Display{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&displays))
+	require.Len(displays, 2)
+	require.Equal("HDMI-2", displays[1].Name)
+	require.Equal(1920, displays[1].X)
+
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/state?display=1", nil))
+	require.Equal(http.StatusOK, w.Code, w.Body.String())
+	state := api.State{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&state))
+	require.Equal(1, state.Display)
+	require.Equal(1280, state.Window.Width)
+
+	// display without stored state is not known yet
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/state", nil))
+	require.Equal(http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/window?display=3", nil))
+	require.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestService_console(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, _ := newTestService(t)
+	svc.consoles[0].Add(api.ConsoleMessage{Level: api.ConsoleLevelInfo, Source: api.ConsoleSourceConsole, Text: "loaded"})
+	svc.consoles[0].Add(api.ConsoleMessage{Level: api.ConsoleLevelError, Source: api.ConsoleSourceException, Text: "TypeError"})
+	svc.consoles[1].Add(api.ConsoleMessage{Display: 1, Level: api.ConsoleLevelWarning, Source: api.ConsoleSourceConsole, Text: "deprecated"})
+
+	w := httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/browser/console", nil))
+	require.Equal(http.StatusOK, w.Code)
+	messages := []api.ConsoleMessage{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&messages))
+	require.Len(messages, 2)
+	require.Equal("TypeError", messages[0].Text)
+
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/browser/console?level=warning", nil))
+	require.Equal(http.StatusOK, w.Code)
+	messages = []api.ConsoleMessage{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&messages))
+	require.Len(messages, 1)
+	require.Equal(api.ConsoleSourceException, messages[0].Source)
+
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/browser/console?level=fatal", nil))
+	require.Equal(http.StatusBadRequest, w.Code)
+
+	// each display has its own console
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/browser/console?display=1", nil))
+	require.Equal(http.StatusOK, w.Code)
+	messages = []api.ConsoleMessage{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&messages))
+	require.Len(messages, 1)
+	require.Equal("deprecated", messages[0].Text)
+
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/browser/console?display=2", nil))
+	require.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestService_errorPage(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, _ := newTestService(t)
+	w := httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, api.ErrorPagePath+"?url=https%3A%2F%2Fsynpse.net&error=%3Cb%3Edown%3C%2Fb%3E", nil))
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal("text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(w.Body.String(), "https://synpse.net")
+	// error is escaped
+	require.Contains(w.Body.String(), "&lt;b&gt;down&lt;/b&gt;")
+
+	// branded template replaces built-in page
+	template := filepath.Join(t.TempDir(), "error.html")
+	require.NoError(os.WriteFile(template, []byte("<h1>ACME</h1>{{.URL}}"), 0644))
+	svc, _, _ = newTestServiceWithConfig(t, &config.Config{PageErrorTemplate: template})
+	w = httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, api.ErrorPagePath+"?url=https%3A%2F%2Fsynpse.net", nil))
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal("<h1>ACME</h1>https://synpse.net", w.Body.String())
+}
+
+func TestService_streamEvents(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, _, events := newTestServiceWithEvents(t)
+	server := httptest.NewServer(svc.server.Handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/events")
+	require.NoError(err)
+	defer resp.Body.Close()
+	require.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	// requests must not be streamed, only notifications
+	go events.Emit(&eventer.EventWrapper{Payload: api.Event{Request: api.KioskRequest{Content: "foo"}}})
+	require.NoError(events.Publish(&eventer.EventWrapper{
+		Payload: api.Event{
+			Type:     api.EventTypeContentChanged,
+			Response: api.KioskResponse{Content: "https://synpse.net"},
+		},
+	}))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(err)
+	require.Equal("event: content_changed\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(err)
+	event := api.StreamEvent{}
+	require.NoError(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+	require.Equal(api.EventTypeContentChanged, event.Type)
+	require.Equal("https://synpse.net", event.State.Content)
+}
+
+func TestService_openAPISpec(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, _ := newTestService(t)
+
+	w := httptest.NewRecorder()
+	svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(http.StatusOK, w.Code)
+
+	spec := struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&spec))
+
+	// every api route must be documented
+	err := svc.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, "/api/") || path == "/api/openapi.json" {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			require.Contains(spec.Paths[path], strings.ToLower(method), "route %s %s is not documented", method, path)
+		}
+		return nil
+	})
+	require.NoError(err)
+}
+
+func TestService_auth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, s, _ := newTestServiceWithConfig(t, &config.Config{
+		APITokens: map[string]string{
+			"admin-secret":  "admin",
+			"viewer-secret": "viewer",
+		},
+	})
+	require.NoError(s.Persist(store.KioskStateKey, api.KioskState{Content: "https://synpse.net"}))
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		svc.server.Handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// landing page and api spec are public
+	require.Equal(http.StatusOK, do(http.MethodGet, "/api/openapi.json", "", "").Code)
+	require.NotEqual(http.StatusUnauthorized, do(http.MethodGet, "/", "", "").Code)
+
+	require.Equal(http.StatusUnauthorized, do(http.MethodGet, "/api/v1/state", "", "").Code)
+	require.Equal(http.StatusUnauthorized, do(http.MethodGet, "/api/v1/state", "wrong", "").Code)
+	require.Equal(http.StatusOK, do(http.MethodGet, "/api/v1/state", "viewer-secret", "").Code)
+	require.Equal(http.StatusForbidden, do(http.MethodPut, "/api/v1/power", "viewer-secret", `{"state":"off"}`).Code)
+	require.Equal(http.StatusForbidden, do(http.MethodGet, "/api/v1/tokens", "viewer-secret", "").Code)
+	require.Equal(http.StatusForbidden, do(http.MethodGet, "/api/v1/browser/cookies", "viewer-secret", "").Code)
+
+	// token management
+	w := do(http.MethodPost, "/api/v1/tokens", "admin-secret", `{"name":"dashboard","role":"operator"}`)
+	require.Equal(http.StatusCreated, w.Code, w.Body.String())
+	token := api.Token{}
+	require.NoError(json.NewDecoder(w.Body).Decode(&token))
+	require.NotEmpty(token.Token)
+
+	require.Equal(http.StatusOK, do(http.MethodGet, "/api/v1/content", token.Token, "").Code)
+	require.Equal(http.StatusOK, do(http.MethodGet, "/api/v1/state?access_token="+token.Token, "", "").Code)
+
+	w = do(http.MethodGet, "/api/v1/tokens", "admin-secret", "")
+	require.Equal(http.StatusOK, w.Code)
+	require.NotContains(w.Body.String(), token.Token)
+
+	require.Equal(http.StatusNoContent, do(http.MethodDelete, "/api/v1/tokens/"+token.ID, "admin-secret", "").Code)
+	require.Equal(http.StatusUnauthorized, do(http.MethodGet, "/api/v1/content", token.Token, "").Code)
+	require.Equal(http.StatusNotFound, do(http.MethodDelete, "/api/v1/tokens/"+token.ID, "admin-secret", "").Code)
+
+	// denied changes are recorded once per caller, denied reads are not recorded
+	require.Equal(http.StatusForbidden, do(http.MethodPut, "/api/v1/power", "viewer-secret", `{"state":"off"}`).Code)
+	require.Equal(http.StatusUnauthorized, do(http.MethodPut, "/api/v1/power", "", `{"state":"off"}`).Code)
+
+	// token changes and rejected requests are audited
+	entries, err := svc.audit.Query(time.Time{}, time.Time{}, 100)
+	require.NoError(err)
+	require.Len(entries, 5)
+	require.Equal("PUT /api/v1/power", entries[0].Action)
+	require.Equal(api.AuditResultDenied, entries[0].Result)
+	require.Empty(entries[0].Identity)
+	require.Equal(api.AuditActionDeleteToken, entries[1].Action)
+	require.Equal(api.AuditResultError, entries[1].Result)
+	require.Equal(api.AuditActionDeleteToken, entries[2].Action)
+	require.Equal(api.AuditResultSuccess, entries[2].Result)
+	require.Equal(api.AuditActionCreateToken, entries[3].Action)
+	require.Equal(token.ID, entries[3].Token)
+	require.Contains(entries[3].Identity, "static-")
+	require.Equal("PUT /api/v1/power", entries[4].Action)
+	require.Equal(api.AuditResultDenied, entries[4].Result)
+	require.Contains(entries[4].Identity, "static-")
+}
+
+func TestService_authDisabled(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, s := newTestService(t)
+	require.NoError(s.Persist(store.KioskStateKey, api.KioskState{Content: "https://synpse.net"}))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		svc.server.Handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// api is open without tokens, but tokens can't be created without admin token
+	require.Equal(http.StatusOK, do(http.MethodGet, "/api/v1/state", "").Code)
+	require.Equal(http.StatusUnauthorized, do(http.MethodPost, "/api/v1/tokens", `{"name":"dashboard","role":"admin"}`).Code)
+	require.Equal(http.StatusUnauthorized, do(http.MethodGet, "/api/v1/tokens", "").Code)
+	require.Empty(svc.auth.ListTokens())
+}
+
+func TestService_audit(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	svc, _, events := newTestServiceWithConfig(t, &config.Config{
+		APITokens: map[string]string{
+			"admin-secret":    "admin",
+			"operator-secret": "operator",
+		},
+	})
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		svc.server.Handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// caller is passed to kiosk together with request
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	callers := make(chan api.Caller, 1)
+	listener := events.Subscribe(ctx)
+	go func() {
+		for event := range listener {
+			callers <- event.Payload.Caller
+			event.Callback <- &eventer.EventWrapper{}
+		}
+	}()
+
+	w := do(http.MethodPut, "/api/v1/content", "operator-secret", `{"content":"https://synpse.net"}`)
+	require.Equal(http.StatusOK, w.Code, w.Body.String())
+	caller := <-callers
+	require.Contains(caller.Identity, "static-")
+	require.Equal("192.0.2.1", caller.Address)
+	require.Equal(w.Header().Get(response.HeaderRequestID), caller.RequestID)
+
+	now := time.Now().UTC()
+	for i, content := range []string{"https://a.example", "https://b.example", "https://c.example"} {
+		require.NoError(svc.audit.Record(api.AuditEntry{
+			Time:     now.Add(time.Duration(i-3) * time.Hour),
+			Identity: "operator",
+			Action:   api.ScreenActionUpdate.String(),
+			Content:  content,
+			Result:   api.AuditResultSuccess,
+		}))
+	}
+
+	require.Equal(http.StatusForbidden, do(http.MethodGet, "/api/v1/audit", "operator-secret", "").Code)
+	require.Equal(http.StatusBadRequest, do(http.MethodGet, "/api/v1/audit?from=yesterday", "admin-secret", "").Code)
+	require.Equal(http.StatusBadRequest, do(http.MethodGet, "/api/v1/audit?limit=0", "admin-secret", "").Code)
+
+	query := func(q string) []api.AuditEntry {
+		w := do(http.MethodGet, "/api/v1/audit"+q, "admin-secret", "")
+		require.Equal(http.StatusOK, w.Code, w.Body.String())
+		var entries []api.AuditEntry
+		require.NoError(json.NewDecoder(w.Body).Decode(&entries))
+		return entries
+	}
+
+	entries := query("")
+	require.Len(entries, 3)
+	require.Equal("https://c.example", entries[0].Content)
+
+	entries = query("?from=" + now.Add(-150*time.Minute).Format(time.RFC3339) + "&to=" + now.Add(-30*time.Minute).Format(time.RFC3339))
+	require.Len(entries, 2)
+	require.Equal("https://c.example", entries[0].Content)
+	require.Equal("https://b.example", entries[1].Content)
+
+	require.Len(query("?limit=1"), 1)
+
+	// request of unknown display is not handled by any kiosk, so it is recorded by web service
+	w = do(http.MethodPut, "/api/v1/content?display=5", "operator-secret", `{"content":"https://synpse.net"}`)
+	require.Equal(http.StatusNotFound, w.Code, w.Body.String())
+	entries = query("?limit=1")
+	require.Equal("PUT /api/v1/content", entries[0].Action)
+	require.Equal(5, entries[0].Display)
+	require.Equal(api.AuditResultError, entries[0].Result)
+	require.Contains(entries[0].Identity, "static-")
+}
+
+func TestService_health(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// health endpoints are public even when api is protected
+	svc, _, _ := newTestServiceWithConfig(t, &config.Config{
+		APITokens: map[string]string{"admin-secret": "admin"},
+	})
+	svc.health.Register("store", true, health.ErrorCheck(func(ctx context.Context) error { return nil }))
+	svc.health.Register("page_load", false, func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("content was not loaded yet")
+	})
+
+	get := func(path string) (int, health.Report) {
+		w := httptest.NewRecorder()
+		svc.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		report := health.Report{}
+		require.NoError(json.NewDecoder(w.Body).Decode(&report))
+		return w.Code, report
+	}
+
+	code, report := get("/healthz")
+	require.Equal(http.StatusOK, code)
+	require.Equal(health.StatusOK, report.Status)
+	require.Len(report.Components, 2)
+
+	code, report = get("/readyz")
+	require.Equal(http.StatusServiceUnavailable, code)
+	require.Equal(health.StatusFailing, report.Status)
+	require.Equal(health.ComponentStatus{
+		Name:    "page_load",
+		Status:  health.StatusFailing,
+		Message: "content was not loaded yet",
+	}, report.Components[1])
+}